@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"backend-go/models"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Actions, named "<target>.<verb>"
+const (
+	ActionPDFCreate         = "pdf.create"
+	ActionPDFUpload         = "pdf.upload"
+	ActionPDFUpdate         = "pdf.update"
+	ActionPDFReplaceFile    = "pdf.replace_file"
+	ActionPDFDelete         = "pdf.delete"
+	ActionPDFRestore        = "pdf.restore"
+	ActionPDFSummarize      = "pdf.summarize"
+	ActionPDFTag            = "pdf.tag"
+	ActionPDFUntag          = "pdf.untag"
+	ActionPDFBulkTag        = "pdf.bulk_tag"
+	ActionPDFMove           = "pdf.move"
+	ActionPDFShare          = "pdf.share"
+	ActionSummaryUpdate     = "summary.update"
+	ActionSummaryRevert     = "summary.revert"
+	ActionSummaryDelete     = "summary.delete"
+	ActionSummaryBulkDelete = "summary.bulk_delete"
+	ActionSummaryRestore    = "summary.restore"
+	ActionSummaryShare      = "summary.share"
+	ActionShareRevoke       = "share.revoke"
+	ActionTagCreate         = "tag.create"
+	ActionTagRename         = "tag.rename"
+	ActionTagDelete         = "tag.delete"
+	ActionCollectionCreate  = "collection.create"
+	ActionCollectionRename  = "collection.rename"
+	ActionCollectionMove    = "collection.move"
+	ActionCollectionDelete  = "collection.delete"
+	ActionUploadCreate      = "upload.create"
+	ActionUploadCancel      = "upload.cancel"
+	ActionWorkspaceCreate   = "workspace.create"
+	ActionMemberAdd         = "member.add"
+	ActionMemberUpdate      = "member.update"
+	ActionMemberRemove      = "member.remove"
+	ActionAPIKeyCreate      = "api_key.create"
+	ActionAPIKeyRevoke      = "api_key.revoke"
+	ActionUserRegister      = "user.register"
+)
+
+// Target types
+const (
+	TargetPDF        = "pdf"
+	TargetSummary    = "summary"
+	TargetShare      = "share"
+	TargetTag        = "tag"
+	TargetCollection = "collection"
+	TargetUpload     = "upload"
+	TargetWorkspace  = "workspace"
+	TargetMember     = "member"
+	TargetAPIKey     = "api_key"
+	TargetUser       = "user"
+)
+
+// Filter narrows the events returned by List and Export. Zero values match everything.
+type Filter struct {
+	ActorID    uint
+	Actions    []string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+}
+
+// Service writes and reads the audit log
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates an audit log service
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Record appends event to the log
+func (s *Service) Record(event *models.AuditEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if err := s.db.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// List returns one page of a workspace's events, newest first, with the total
+// number matching filter. A workspaceID of 0 lists the account events of
+// filter.ActorID, which belong to no workspace.
+func (s *Service) List(workspaceID uint, filter Filter, page, itemsPerPage int) ([]models.AuditEvent, int64, error) {
+	query := s.query(workspaceID, filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []models.AuditEvent
+	err := query.Order("created_at desc, id desc").
+		Offset((page - 1) * itemsPerPage).
+		Limit(itemsPerPage).
+		Find(&events).Error
+	return events, total, err
+}
+
+// Export calls fn with every matching event, oldest first, reading them in batches
+func (s *Service) Export(workspaceID uint, filter Filter, fn func(event models.AuditEvent) error) error {
+	var events []models.AuditEvent
+	return s.query(workspaceID, filter).
+		FindInBatches(&events, 500, func(tx *gorm.DB, batch int) error {
+			for _, event := range events {
+				if err := fn(event); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
+
+func (s *Service) query(workspaceID uint, filter Filter) *gorm.DB {
+	query := s.db.Model(&models.AuditEvent{})
+	if workspaceID != 0 {
+		query = query.Where("workspace_id = ?", workspaceID)
+		if filter.ActorID != 0 {
+			query = query.Where("actor_id = ?", filter.ActorID)
+		}
+	} else {
+		// Account events are only ever listed for their own actor
+		query = query.Where("workspace_id IS NULL AND actor_id = ?", filter.ActorID)
+	}
+	if len(filter.Actions) > 0 {
+		query = query.Where("action IN ?", filter.Actions)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	return query
+}
+
+// Snapshot turns a model or response into the JSON object stored as an
+// event's before or after state. nil stays nil.
+func Snapshot(v interface{}) models.JSONMap {
+	if v == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return models.JSONMap{"error": err.Error()}
+	}
+	var snapshot models.JSONMap
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		// Not an object, e.g. a list of IDs
+		var value interface{}
+		json.Unmarshal(data, &value)
+		return models.JSONMap{"value": value}
+	}
+	return snapshot
+}
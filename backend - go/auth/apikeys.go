@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"backend-go/models"
+	"backend-go/utils"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// API key scopes
+const (
+	ScopePDFRead         = "pdf:read"
+	ScopePDFWrite        = "pdf:write"
+	ScopeSummaryRead     = "summary:read"
+	ScopeSummaryWrite    = "summary:write"
+	ScopeSummaryGenerate = "summary:generate"
+	ScopeAdmin           = "admin"
+)
+
+// Scopes lists every scope an API key can be granted
+var Scopes = []string{
+	ScopePDFRead,
+	ScopePDFWrite,
+	ScopeSummaryRead,
+	ScopeSummaryWrite,
+	ScopeSummaryGenerate,
+	ScopeAdmin,
+}
+
+// apiKeyPrefix marks our keys so they are easy to spot in logs and secret scanners
+const apiKeyPrefix = "apm_"
+
+// lastUsedResolution limits how often last_used_at is written for a busy key
+const lastUsedResolution = time.Minute
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// ValidScope reports whether scope is a known API key scope
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeys creates, lists, revokes and verifies API keys. Only a SHA-256
+// hash of each key is stored, the key itself is returned once on creation.
+type APIKeys struct {
+	db *gorm.DB
+}
+
+// NewAPIKeys creates an API key service
+func NewAPIKeys(db *gorm.DB) *APIKeys {
+	return &APIKeys{db: db}
+}
+
+// Create generates a new key for a user and returns it alongside its record
+func (k *APIKeys) Create(userID uint, name string, scopes []string, rateLimit int, expiresAt *time.Time) (string, *models.APIKey, error) {
+	id, err := randomHex(4)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", nil, err
+	}
+
+	prefix := apiKeyPrefix + id
+	key := prefix + "_" + secret
+
+	record := models.APIKey{
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		Prefix:    prefix,
+		KeyHash:   hashAPIKey(key),
+		Scopes:    strings.Join(scopes, " "),
+		RateLimit: rateLimit,
+		ExpiresAt: expiresAt,
+	}
+	if err := k.db.Create(&record).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return key, &record, nil
+}
+
+// List returns a user's keys, newest first
+func (k *APIKeys) List(userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := k.db.Where("user_id = ?", userID).Order("created_at desc").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke stops a user's key from authenticating. Revoking twice is not an error.
+func (k *APIKeys) Revoke(userID, id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := k.db.Where("id = ? AND user_id = ?", id, userID).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now()
+		if err := k.db.Model(&key).Update("revoked_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &key, nil
+}
+
+// VerifyAPIKey looks up an active key and records that it was used
+func (k *APIKeys) VerifyAPIKey(key string) (*utils.Principal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	var record models.APIKey
+	err := k.db.Where("key_hash = ?", hashAPIKey(key)).First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if record.RevokedAt != nil || (record.ExpiresAt != nil && now.After(*record.ExpiresAt)) {
+		return nil, ErrInvalidToken
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= lastUsedResolution {
+		// Conditional so concurrent requests don't all write the same row
+		k.db.Model(&models.APIKey{}).
+			Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", record.ID, now.Add(-lastUsedResolution)).
+			UpdateColumn("last_used_at", now)
+	}
+
+	return &utils.Principal{
+		UserID:    record.UserID,
+		APIKeyID:  record.ID,
+		Scopes:    strings.Fields(record.Scopes),
+		RateLimit: record.RateLimit,
+	}, nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
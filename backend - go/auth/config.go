@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"backend-go/utils"
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// TokensFromEnv creates the token signer configured by JWT_SECRET, JWT_ISSUER,
+// JWT_ACCESS_TTL and JWT_REFRESH_TTL. Without JWT_SECRET a random secret is
+// generated, which signs everyone out whenever the server restarts.
+func TokensFromEnv() (*Tokens, error) {
+	secret := []byte(utils.GetEnv("JWT_SECRET", ""))
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+		}
+		fmt.Println("Warning: JWT_SECRET is not set, using a random secret for this run")
+	} else if len(secret) < 32 {
+		return nil, fmt.Errorf("JWT_SECRET must be at least 32 bytes")
+	}
+
+	return NewTokens(
+		secret,
+		utils.GetEnv("JWT_ISSUER", "ai-pdf-management"),
+		utils.GetEnvDuration("JWT_ACCESS_TTL", 15*time.Minute),
+		utils.GetEnvDuration("JWT_REFRESH_TTL", 30*24*time.Hour),
+	), nil
+}
@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"backend-go/models"
+	"backend-go/workspaces"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrEmailTaken         = errors.New("email is already registered")
+)
+
+// dummyHash is compared against when a login email is unknown, so the
+// response time doesn't reveal which emails are registered
+var dummyHash, _ = HashPassword("not-a-real-password")
+
+// Session is the token pair handed out by login and refresh
+type Session struct {
+	User             models.User
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// Service registers users and manages their sessions
+type Service struct {
+	db           *gorm.DB
+	tokens       *Tokens
+	adoptOrphans bool
+}
+
+// NewService creates an auth service. With adoptOrphans, the first user to
+// register takes over documents created before accounts existed.
+func NewService(db *gorm.DB, tokens *Tokens, adoptOrphans bool) *Service {
+	return &Service{db: db, tokens: tokens, adoptOrphans: adoptOrphans}
+}
+
+// Tokens returns the token signer, which also verifies access tokens for the auth middleware
+func (s *Service) Tokens() *Tokens {
+	return s.tokens
+}
+
+// NormalizeEmail lowercases and trims an email so lookups are case-insensitive
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Register creates a user and their personal workspace. When enabled, the
+// first user adopts documents created before accounts existed.
+func (s *Service) Register(email, name, password string) (*models.User, error) {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := models.User{
+		Email:        NormalizeEmail(email),
+		Name:         strings.TrimSpace(name),
+		PasswordHash: hash,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Unscoped().Model(&models.User{}).Where("email = ?", user.Email).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrEmailTaken
+		}
+
+		// A concurrent registration with the same email can still win the race to the unique index
+		if err := tx.Create(&user).Error; err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return ErrEmailTaken
+			}
+			return err
+		}
+
+		// Everyone starts with a personal workspace and can be invited to others
+		workspaceName := user.Name
+		if workspaceName == "" {
+			workspaceName = user.Email
+		}
+		workspace, err := workspaces.CreateWithOwner(tx, user.ID, workspaceName+"'s workspace")
+		if err != nil {
+			return err
+		}
+
+		if !s.adoptOrphans {
+			return nil
+		}
+
+		var users int64
+		if err := tx.Unscoped().Model(&models.User{}).Count(&users).Error; err != nil {
+			return err
+		}
+		if users > 1 {
+			return nil
+		}
+
+		adopt := map[string]interface{}{"owner_id": user.ID, "workspace_id": workspace.ID}
+		if err := tx.Model(&models.PDF{}).Unscoped().Where("owner_id IS NULL").Updates(adopt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Summaries{}).Unscoped().Where("owner_id IS NULL").Updates(adopt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// Login checks a user's password and starts a new session
+func (s *Service) Login(email, password string) (*Session, error) {
+	var user models.User
+	err := s.db.Where("email = ?", NormalizeEmail(email)).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if user.ID == 0 {
+		CheckPassword(dummyHash, password)
+		return nil, ErrInvalidCredentials
+	}
+	if !CheckPassword(user.PasswordHash, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	var session *Session
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		session, err = s.issue(tx, user)
+		return err
+	})
+	return session, err
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token works once;
+// presenting a revoked one signs the user out everywhere since it was probably stolen.
+func (s *Service) Refresh(refreshToken string) (*Session, error) {
+	claims, err := s.tokens.Parse(refreshToken, TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	var session *Session
+	reused := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var stored models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_id = ?", claims.ID).
+			First(&stored).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+
+		if stored.RevokedAt != nil {
+			reused = true
+			return nil
+		}
+		if time.Now().After(stored.ExpiresAt) {
+			return ErrInvalidToken
+		}
+
+		if err := tx.Model(&stored).Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		var user models.User
+		if err := tx.First(&user, stored.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidToken
+			}
+			return err
+		}
+
+		session, err = s.issue(tx, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reused {
+		userID, _ := claims.UserID()
+		if err := s.revokeAll(userID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidToken
+	}
+
+	return session, nil
+}
+
+// Logout revokes a refresh token. Access tokens stay valid until they expire.
+func (s *Service) Logout(refreshToken string) error {
+	claims, err := s.tokens.Parse(refreshToken, TokenTypeRefresh)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Model(&models.RefreshToken{}).
+		Where("token_id = ? AND revoked_at IS NULL", claims.ID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (s *Service) revokeAll(userID uint) error {
+	return s.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// issue signs a token pair and records the refresh token
+func (s *Service) issue(tx *gorm.DB, user models.User) (*Session, error) {
+	access, accessClaims, err := s.tokens.Issue(user.ID, TokenTypeAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, refreshClaims, err := s.tokens.Issue(user.ID, TokenTypeRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Create(&models.RefreshToken{
+		TokenID:   refreshClaims.ID,
+		UserID:    user.ID,
+		ExpiresAt: refreshClaims.ExpiresAt.Time,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &Session{
+		User:             user,
+		AccessToken:      access,
+		AccessExpiresAt:  accessClaims.ExpiresAt.Time,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshClaims.ExpiresAt.Time,
+	}, nil
+}
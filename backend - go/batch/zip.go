@@ -0,0 +1,169 @@
+package batch
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+var (
+	ErrNotZip       = errors.New("file is not a valid ZIP archive")
+	ErrTooManyFiles = errors.New("archive contains too many files")
+	ErrTooLarge     = errors.New("archive expands beyond the size limit")
+	ErrUnsafePath   = errors.New("entry path points outside the archive")
+	ErrRatio        = errors.New("entry is compressed too well to be a PDF")
+	ErrSizeMismatch = errors.New("entry is larger than its header claims")
+)
+
+// Limits bound what an archive may expand to, so a small upload can't turn
+// into gigabytes on disk
+type Limits struct {
+	MaxFiles     int   // entries, not counting skipped ones
+	MaxTotalSize int64 // sum of uncompressed sizes
+	MaxRatio     int64 // uncompressed size over compressed size of one entry
+}
+
+// Entry is a file inside an archive. Entries with Err set must not be read.
+type Entry struct {
+	Name string // path inside the archive with "/" separators
+	Size int64  // uncompressed size from the entry header
+	Err  error
+	file *zip.File
+}
+
+// Open returns the entry's content. Reading more than the header's size fails
+// with ErrSizeMismatch.
+func (e Entry) Open() (io.ReadCloser, error) {
+	if e.Err != nil {
+		return nil, e.Err
+	}
+
+	rc, err := e.file.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &limitedReader{rc: rc, remaining: e.Size}, nil
+}
+
+// ReadZip lists the files in a ZIP archive. Directories, macOS resource forks
+// and hidden files are skipped. Problems with a single entry are reported on
+// the entry, problems with the archive as a whole fail the call.
+func ReadZip(r io.ReaderAt, size int64, limits Limits) ([]Entry, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrNotZip
+	}
+
+	var entries []Entry
+	var total int64
+	for _, file := range archive.File {
+		if skip(file) {
+			continue
+		}
+
+		entry := Entry{Name: file.Name, Size: int64(file.UncompressedSize64), file: file}
+		if len(entries) == limits.MaxFiles {
+			return nil, fmt.Errorf("%w, the limit is %d", ErrTooManyFiles, limits.MaxFiles)
+		}
+
+		if name, ok := cleanName(file.Name); ok {
+			entry.Name = name
+		} else {
+			entry.Err = ErrUnsafePath
+		}
+
+		// Header sizes are checked before anything is decompressed
+		if entry.Err == nil && file.UncompressedSize64 > uint64(limits.MaxTotalSize) {
+			return nil, ErrTooLarge
+		}
+		if entry.Err == nil && limits.MaxRatio > 0 && file.CompressedSize64 > 0 &&
+			file.UncompressedSize64/file.CompressedSize64 > uint64(limits.MaxRatio) {
+			entry.Err = ErrRatio
+		}
+
+		if entry.Err == nil {
+			total += entry.Size
+			if total > limits.MaxTotalSize {
+				return nil, ErrTooLarge
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Corrupt reports whether err came from a damaged or lying archive entry
+// rather than from the server
+func Corrupt(err error) bool {
+	return errors.Is(err, ErrSizeMismatch) ||
+		errors.Is(err, zip.ErrFormat) ||
+		errors.Is(err, zip.ErrChecksum) ||
+		errors.Is(err, zip.ErrAlgorithm)
+}
+
+// skip reports whether an entry is archive clutter rather than a document
+func skip(file *zip.File) bool {
+	if file.FileInfo().IsDir() || strings.HasSuffix(file.Name, "/") {
+		return true
+	}
+
+	name := strings.ReplaceAll(file.Name, "\\", "/")
+	if strings.HasPrefix(name, "__MACOSX/") {
+		return true
+	}
+	return strings.HasPrefix(path.Base(name), ".")
+}
+
+// cleanName normalizes an entry's path and rejects absolute paths and paths
+// that climb out of the archive with ".."
+func cleanName(name string) (string, bool) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" || strings.ContainsRune(name, 0) || strings.HasPrefix(name, "/") {
+		return "", false
+	}
+	// Windows drive letters, e.g. "C:/..."
+	if len(name) >= 2 && name[1] == ':' {
+		return "", false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", false
+		}
+	}
+	return path.Clean(name), true
+}
+
+// limitedReader fails once more than remaining bytes come out of an entry
+type limitedReader struct {
+	rc        io.ReadCloser
+	remaining int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		// Allow the clean EOF but not a single byte more. archive/zip reports
+		// the extra byte itself as ErrFormat when the entry has a checksum.
+		var probe [1]byte
+		n, err := r.rc.Read(probe[:])
+		if n > 0 || errors.Is(err, zip.ErrFormat) {
+			return 0, ErrSizeMismatch
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.rc.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+func (r *limitedReader) Close() error {
+	return r.rc.Close()
+}
@@ -0,0 +1,232 @@
+package batch
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+)
+
+// zipFile is an entry to write into a test archive
+type zipFile struct {
+	name    string
+	content []byte
+	// headerSize, when set, is written as the uncompressed size instead of the real one
+	headerSize uint64
+}
+
+func buildZip(t *testing.T, files ...zipFile) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		if f.headerSize == 0 {
+			fw, err := w.Create(f.name)
+			if err != nil {
+				t.Fatalf("create %s: %v", f.name, err)
+			}
+			fw.Write(f.content)
+			continue
+		}
+
+		// Stored raw so the header can lie about the size
+		fw, err := w.CreateRaw(&zip.FileHeader{
+			Name:               f.name,
+			Method:             zip.Store,
+			CRC32:              crc32.ChecksumIEEE(f.content),
+			CompressedSize64:   uint64(len(f.content)),
+			UncompressedSize64: f.headerSize,
+		})
+		if err != nil {
+			t.Fatalf("create %s: %v", f.name, err)
+		}
+		fw.Write(f.content)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close archive: %v", err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func readTestZip(t *testing.T, limits Limits, files ...zipFile) ([]Entry, error) {
+	archive := buildZip(t, files...)
+	return ReadZip(archive, archive.Size(), limits)
+}
+
+var testLimits = Limits{MaxFiles: 10, MaxTotalSize: 1 << 20, MaxRatio: 100}
+
+func TestReadZipPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr error
+	}{
+		{"report.pdf", "report.pdf", nil},
+		{"docs/./2024/report.pdf", "docs/2024/report.pdf", nil},
+		{`docs\report.pdf`, "docs/report.pdf", nil},
+		{"../x.pdf", "", ErrUnsafePath},
+		{"docs/../../x.pdf", "", ErrUnsafePath},
+		{`..\x.pdf`, "", ErrUnsafePath},
+		{"/etc/x.pdf", "", ErrUnsafePath},
+		{`\x.pdf`, "", ErrUnsafePath},
+		{"C:/x.pdf", "", ErrUnsafePath},
+		{`C:\x.pdf`, "", ErrUnsafePath},
+	}
+
+	for _, tt := range tests {
+		entries, err := readTestZip(t, testLimits, zipFile{name: tt.name, content: []byte("%PDF-1.4")})
+		if err != nil {
+			t.Errorf("%q: ReadZip: %v", tt.name, err)
+			continue
+		}
+		if len(entries) != 1 {
+			t.Errorf("%q: got %d entries, want 1", tt.name, len(entries))
+			continue
+		}
+
+		entry := entries[0]
+		if entry.Err != tt.wantErr {
+			t.Errorf("%q: entry error = %v, want %v", tt.name, entry.Err, tt.wantErr)
+		}
+		if tt.wantErr == nil && entry.Name != tt.want {
+			t.Errorf("%q: name = %q, want %q", tt.name, entry.Name, tt.want)
+		}
+		if tt.wantErr != nil {
+			if _, err := entry.Open(); err != tt.wantErr {
+				t.Errorf("%q: Open = %v, want %v", tt.name, err, tt.wantErr)
+			}
+		}
+	}
+}
+
+func TestReadZipSkipsClutter(t *testing.T) {
+	entries, err := readTestZip(t, testLimits,
+		zipFile{name: "docs/"},
+		zipFile{name: "__MACOSX/docs/._a.pdf", content: []byte("fork")},
+		zipFile{name: "docs/.DS_Store", content: []byte("finder")},
+		zipFile{name: "docs/a.pdf", content: []byte("%PDF-1.4")},
+	)
+	if err != nil {
+		t.Fatalf("ReadZip: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name != "docs/a.pdf" {
+		t.Errorf("entries = %+v, want only docs/a.pdf", entries)
+	}
+}
+
+func TestReadZipLimits(t *testing.T) {
+	pdf := []byte("%PDF-1.4 not very compressible 0123456789")
+	zeros := make([]byte, 64<<10)
+
+	tests := []struct {
+		name      string
+		limits    Limits
+		files     []zipFile
+		wantErr   error
+		wantEntry error // error on the first entry when the archive is accepted
+	}{
+		{
+			name:   "at MaxFiles",
+			limits: Limits{MaxFiles: 2, MaxTotalSize: 1 << 20},
+			files:  []zipFile{{name: "a.pdf", content: pdf}, {name: "b.pdf", content: pdf}},
+		},
+		{
+			name:    "over MaxFiles",
+			limits:  Limits{MaxFiles: 2, MaxTotalSize: 1 << 20},
+			files:   []zipFile{{name: "a.pdf", content: pdf}, {name: "b.pdf", content: pdf}, {name: "c.pdf", content: pdf}},
+			wantErr: ErrTooManyFiles,
+		},
+		{
+			name:   "skipped entries don't count towards MaxFiles",
+			limits: Limits{MaxFiles: 1, MaxTotalSize: 1 << 20},
+			files:  []zipFile{{name: "a.pdf", content: pdf}, {name: ".hidden", content: pdf}},
+		},
+		{
+			name:    "over MaxTotalSize in total",
+			limits:  Limits{MaxFiles: 10, MaxTotalSize: int64(len(pdf)) * 2},
+			files:   []zipFile{{name: "a.pdf", content: pdf}, {name: "b.pdf", content: pdf}, {name: "c.pdf", content: pdf}},
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "one entry over MaxTotalSize",
+			limits:  Limits{MaxFiles: 10, MaxTotalSize: 1 << 10},
+			files:   []zipFile{{name: "big.pdf", content: zeros}},
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:      "ratio above MaxRatio",
+			limits:    Limits{MaxFiles: 10, MaxTotalSize: 1 << 20, MaxRatio: 100},
+			files:     []zipFile{{name: "bomb.pdf", content: zeros}},
+			wantEntry: ErrRatio,
+		},
+		{
+			name:   "ratio ignored without MaxRatio",
+			limits: Limits{MaxFiles: 10, MaxTotalSize: 1 << 20},
+			files:  []zipFile{{name: "bomb.pdf", content: zeros}},
+		},
+	}
+
+	for _, tt := range tests {
+		entries, err := readTestZip(t, tt.limits, tt.files...)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: ReadZip error = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if entries[0].Err != tt.wantEntry {
+			t.Errorf("%s: entry error = %v, want %v", tt.name, entries[0].Err, tt.wantEntry)
+		}
+	}
+}
+
+func TestReadZipRejectsInvalidArchives(t *testing.T) {
+	data := strings.NewReader("%PDF-1.4 this is not an archive")
+	if _, err := ReadZip(data, data.Size(), testLimits); err != ErrNotZip {
+		t.Errorf("ReadZip = %v, want ErrNotZip", err)
+	}
+}
+
+func TestEntryOpenStopsAtHeaderSize(t *testing.T) {
+	content := bytes.Repeat([]byte("%PDF-1.4 "), 100)
+
+	tests := []struct {
+		name       string
+		headerSize uint64
+		wantErr    error
+	}{
+		{"header matches", uint64(len(content)), nil},
+		{"header understates the size", 10, ErrSizeMismatch},
+	}
+
+	for _, tt := range tests {
+		entries, err := readTestZip(t, testLimits, zipFile{name: "a.pdf", content: content, headerSize: tt.headerSize})
+		if err != nil {
+			t.Fatalf("%s: ReadZip: %v", tt.name, err)
+		}
+		if entries[0].Size != int64(tt.headerSize) {
+			t.Errorf("%s: Size = %d, want the header's %d", tt.name, entries[0].Size, tt.headerSize)
+		}
+
+		rc, err := entries[0].Open()
+		if err != nil {
+			t.Fatalf("%s: Open: %v", tt.name, err)
+		}
+		got, err := io.ReadAll(rc)
+		rc.Close()
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: read error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if int64(len(got)) > int64(tt.headerSize) {
+			t.Errorf("%s: read %d bytes, more than the header's %d", tt.name, len(got), tt.headerSize)
+		}
+		if tt.wantErr != nil && !Corrupt(err) {
+			t.Errorf("%s: Corrupt(%v) = false", tt.name, err)
+		}
+	}
+}
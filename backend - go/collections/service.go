@@ -0,0 +1,391 @@
+package collections
+
+import (
+	"backend-go/models"
+	"backend-go/trash"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotFound       = errors.New("collection not found")
+	ErrParentNotFound = errors.New("parent collection not found")
+	ErrExists         = errors.New("a collection with that name already exists here")
+	ErrCycle          = errors.New("a collection cannot be moved into itself or one of its descendants")
+	ErrPDFNotFound    = errors.New("PDF not found")
+)
+
+// Count is a collection with the number of subcollections and live PDFs directly inside it
+type Count struct {
+	models.Collection
+	ChildCount int64
+	PDFCount   int64
+}
+
+// Service manages a workspace's tree of collections and which collection
+// each PDF is filed in. Changes to the tree lock the workspace row, so
+// concurrent moves can never create a cycle or leave stale paths behind.
+type Service struct {
+	db    *gorm.DB
+	trash *trash.Service
+}
+
+// NewService creates a collection service. Deleting a collection with its
+// contents moves the PDFs inside it to trash.
+func NewService(db *gorm.DB, trash *trash.Service) *Service {
+	return &Service{db: db, trash: trash}
+}
+
+// Get returns a collection with its counts
+func (s *Service) Get(workspaceID, id uint) (*Count, error) {
+	var count Count
+	err := s.counts(workspaceID).Where("collections.id = ?", id).Take(&count).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return &count, nil
+}
+
+// Children returns the collections directly inside parentID by name, or the top level ones when parentID is nil
+func (s *Service) Children(workspaceID uint, parentID *uint) ([]Count, error) {
+	query := s.counts(workspaceID)
+	if parentID == nil {
+		query = query.Where("collections.parent_id IS NULL")
+	} else {
+		query = query.Where("collections.parent_id = ?", *parentID)
+	}
+
+	children := []Count{}
+	if err := query.Order("lower(collections.name) asc").Find(&children).Error; err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	return children, nil
+}
+
+// Breadcrumbs returns the collections from the top level down to and including id
+func (s *Service) Breadcrumbs(workspaceID, id uint) ([]models.Collection, error) {
+	var collection models.Collection
+	if err := find(s.db, workspaceID, id, &collection, ErrNotFound); err != nil {
+		return nil, err
+	}
+
+	crumbs := []models.Collection{}
+	err := s.db.Where("workspace_id = ? AND id IN ?", workspaceID, ancestors(collection.Path)).
+		Order("depth asc").
+		Find(&crumbs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load breadcrumbs: %w", err)
+	}
+	return crumbs, nil
+}
+
+// Create adds a collection inside parentID, or at the top level when parentID is nil
+func (s *Service) Create(workspaceID uint, name string, parentID *uint) (*models.Collection, error) {
+	collection := models.Collection{WorkspaceID: workspaceID, ParentID: parentID, Name: strings.TrimSpace(name)}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTree(tx, workspaceID); err != nil {
+			return err
+		}
+
+		parentPath := "/"
+		if parentID != nil {
+			var parent models.Collection
+			if err := find(tx, workspaceID, *parentID, &parent, ErrParentNotFound); err != nil {
+				return err
+			}
+			parentPath = parent.Path
+			collection.Depth = parent.Depth + 1
+		}
+
+		if err := checkName(tx, workspaceID, parentID, collection.Name, 0); err != nil {
+			return err
+		}
+
+		// The path includes the collection's own ID, which is only known after the insert
+		if err := tx.Create(&collection).Error; err != nil {
+			return fmt.Errorf("failed to create collection: %w", err)
+		}
+		collection.Path = pathOf(parentPath, collection.ID)
+		return tx.Model(&collection).Update("path", collection.Path).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// Rename changes a collection's name. Changing only the case of the name is allowed.
+func (s *Service) Rename(workspaceID, id uint, name string) (*models.Collection, error) {
+	name = strings.TrimSpace(name)
+
+	var collection models.Collection
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTree(tx, workspaceID); err != nil {
+			return err
+		}
+		if err := find(tx, workspaceID, id, &collection, ErrNotFound); err != nil {
+			return err
+		}
+		if err := checkName(tx, workspaceID, collection.ParentID, name, collection.ID); err != nil {
+			return err
+		}
+
+		collection.Name = name
+		return tx.Model(&collection).Update("name", name).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// Move puts a collection and everything in it inside parentID, or at the top level when parentID is nil
+func (s *Service) Move(workspaceID, id uint, parentID *uint) (*models.Collection, error) {
+	var collection models.Collection
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTree(tx, workspaceID); err != nil {
+			return err
+		}
+		if err := find(tx, workspaceID, id, &collection, ErrNotFound); err != nil {
+			return err
+		}
+
+		parentPath, depth := "/", 0
+		if parentID != nil {
+			var parent models.Collection
+			if err := find(tx, workspaceID, *parentID, &parent, ErrParentNotFound); err != nil {
+				return err
+			}
+			if strings.HasPrefix(parent.Path, collection.Path) {
+				return ErrCycle
+			}
+			parentPath, depth = parent.Path, parent.Depth+1
+		}
+
+		if err := checkName(tx, workspaceID, parentID, collection.Name, collection.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&collection).Update("parent_id", parentID).Error; err != nil {
+			return fmt.Errorf("failed to move collection: %w", err)
+		}
+		if err := rebase(tx, workspaceID, collection.Path, pathOf(parentPath, collection.ID), depth-collection.Depth); err != nil {
+			return err
+		}
+
+		collection.ParentID = parentID
+		collection.Path = pathOf(parentPath, collection.ID)
+		collection.Depth = depth
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// Delete removes a collection. With recursive, its subcollections are
+// deleted too and every PDF in the subtree goes to the trash. Otherwise its
+// subcollections and PDFs move up into its parent. It returns how many PDFs
+// were trashed or moved.
+func (s *Service) Delete(workspaceID, id uint, recursive bool) (int64, error) {
+	var affected int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTree(tx, workspaceID); err != nil {
+			return err
+		}
+
+		var collection models.Collection
+		if err := find(tx, workspaceID, id, &collection, ErrNotFound); err != nil {
+			return err
+		}
+
+		if recursive {
+			var pdfIDs []uint
+			if err := tx.Model(&models.PDF{}).
+				Where("collection_id IN (?)", subtree(tx, workspaceID, collection.Path).Select("id")).
+				Pluck("id", &pdfIDs).Error; err != nil {
+				return fmt.Errorf("failed to find PDFs in collection: %w", err)
+			}
+			if err := s.trash.TrashPDFs(tx, pdfIDs); err != nil {
+				return fmt.Errorf("failed to trash PDFs: %w", err)
+			}
+			affected = int64(len(pdfIDs))
+
+			// Subcollections go with it through the parent_id cascade
+			return tx.Delete(&collection).Error
+		}
+
+		// Lift the contents up one level before the collection disappears
+		result := tx.Model(&models.PDF{}).Where("collection_id = ?", collection.ID).Update("collection_id", collection.ParentID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to move PDFs: %w", result.Error)
+		}
+		affected = result.RowsAffected
+
+		// Trashed PDFs follow too, so restoring them puts them back in the parent
+		if err := tx.Unscoped().Model(&models.PDF{}).
+			Where("collection_id = ? AND deleted_at IS NOT NULL", collection.ID).
+			Update("collection_id", collection.ParentID).Error; err != nil {
+			return fmt.Errorf("failed to move trashed PDFs: %w", err)
+		}
+
+		var children []models.Collection
+		if err := tx.Where("parent_id = ?", collection.ID).Find(&children).Error; err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := checkName(tx, workspaceID, collection.ParentID, child.Name, collection.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.Collection{}).
+			Where("parent_id = ?", collection.ID).
+			Update("parent_id", collection.ParentID).Error; err != nil {
+			return fmt.Errorf("failed to move subcollections: %w", err)
+		}
+		if err := tx.Delete(&collection).Error; err != nil {
+			return err
+		}
+
+		// Only the descendants are left under the old path
+		return rebase(tx, workspaceID, collection.Path, parentPathOf(collection.Path), -1)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// MovePDFs files PDFs in collectionID, or takes them out of any collection when collectionID is nil
+func (s *Service) MovePDFs(workspaceID uint, pdfIDs []uint, collectionID *uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if collectionID != nil {
+			var collection models.Collection
+			if err := find(tx, workspaceID, *collectionID, &collection, ErrNotFound); err != nil {
+				return err
+			}
+		}
+
+		unique := make(map[uint]bool, len(pdfIDs))
+		for _, id := range pdfIDs {
+			unique[id] = true
+		}
+
+		result := tx.Model(&models.PDF{}).
+			Where("workspace_id = ? AND id IN ?", workspaceID, pdfIDs).
+			Update("collection_id", collectionID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to move PDFs: %w", result.Error)
+		}
+		if result.RowsAffected != int64(len(unique)) {
+			return ErrPDFNotFound
+		}
+		return nil
+	})
+}
+
+// Contains restricts a PDF query to the collection, or with recursive to the collection and its descendants
+func Contains(collection models.Collection, recursive bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !recursive {
+			return db.Where("pdfs.collection_id = ?", collection.ID)
+		}
+		descendants := subtree(db.Session(&gorm.Session{NewDB: true}), collection.WorkspaceID, collection.Path).Select("id")
+		return db.Where("pdfs.collection_id IN (?)", descendants)
+	}
+}
+
+// counts selects collections with their subcollection and PDF counts
+func (s *Service) counts(workspaceID uint) *gorm.DB {
+	return s.db.Model(&models.Collection{}).
+		Select("collections.*, "+
+			"(SELECT COUNT(*) FROM collections children WHERE children.parent_id = collections.id) AS child_count, "+
+			"(SELECT COUNT(*) FROM pdfs WHERE pdfs.collection_id = collections.id AND pdfs.deleted_at IS NULL) AS pdf_count").
+		Where("collections.workspace_id = ?", workspaceID)
+}
+
+// lockTree serializes changes to a workspace's collections
+func lockTree(tx *gorm.DB, workspaceID uint) error {
+	var workspace models.Workspace
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&workspace, workspaceID).Error
+}
+
+func find(tx *gorm.DB, workspaceID, id uint, collection *models.Collection, notFound error) error {
+	err := tx.Where("workspace_id = ?", workspaceID).First(collection, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound
+	}
+	return err
+}
+
+// checkName makes sure no other collection under parentID, apart from except, is called name
+func checkName(tx *gorm.DB, workspaceID uint, parentID *uint, name string, except uint) error {
+	query := tx.Model(&models.Collection{}).Where("workspace_id = ? AND lower(name) = lower(?) AND id <> ?", workspaceID, name, except)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+
+	var taken int64
+	if err := query.Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return ErrExists
+	}
+	return nil
+}
+
+// subtree selects the collection at path and all of its descendants
+func subtree(tx *gorm.DB, workspaceID uint, path string) *gorm.DB {
+	return tx.Model(&models.Collection{}).Where("workspace_id = ? AND path LIKE ?", workspaceID, path+"%")
+}
+
+// rebase rewrites the paths under oldPath, including oldPath itself, to start
+// with newPath instead and shifts their depth
+func rebase(tx *gorm.DB, workspaceID uint, oldPath, newPath string, depthChange int) error {
+	err := subtree(tx, workspaceID, oldPath).
+		Updates(map[string]interface{}{
+			"path":  gorm.Expr("? || substr(path, ?)", newPath, len(oldPath)+1),
+			"depth": gorm.Expr("depth + ?", depthChange),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update collection paths: %w", err)
+	}
+	return nil
+}
+
+// pathOf is the path of collection id inside the collection at parentPath
+func pathOf(parentPath string, id uint) string {
+	return parentPath + strconv.FormatUint(uint64(id), 10) + "/"
+}
+
+// parentPathOf drops the last ID from a path, e.g. "/1/4/" becomes "/1/"
+func parentPathOf(path string) string {
+	trimmed := strings.TrimSuffix(path, "/")
+	return trimmed[:strings.LastIndex(trimmed, "/")+1]
+}
+
+// ancestors lists the IDs in a path, top level first
+func ancestors(path string) []uint {
+	var ids []uint
+	for _, part := range strings.Split(strings.Trim(path, "/"), "/") {
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,109 @@
+package download
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag is the strong entity tag of a file with the given content hash, or ""
+// for files stored before hashes were recorded
+func ETag(hash string) string {
+	if hash == "" {
+		return ""
+	}
+	return `"` + hash + `"`
+}
+
+// NotModified reports whether a GET with these If-None-Match and
+// If-Modified-Since headers should get 304. If-Modified-Since is only used
+// when If-None-Match is absent.
+func NotModified(ifNoneMatch, ifModifiedSince, etag string, modified time.Time) bool {
+	if ifNoneMatch != "" {
+		return etag != "" && matchAny(ifNoneMatch, etag)
+	}
+
+	if ifModifiedSince == "" || modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	// HTTP dates have whole seconds
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// IfRange reports whether a Range header should be honoured given the
+// If-Range header, which must strongly match the current ETag or
+// Last-Modified date
+func IfRange(ifRange, etag string, modified time.Time) bool {
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+		return etag != "" && ifRange == etag
+	}
+
+	date, err := http.ParseTime(ifRange)
+	if err != nil || modified.IsZero() {
+		return false
+	}
+	return modified.Truncate(time.Second).Equal(date)
+}
+
+// ContentDisposition is the Content-Disposition header value for a file
+// shown "inline" or downloaded as an "attachment". Names outside ASCII are
+// sent RFC 5987 encoded in filename*, with a plain filename fallback for
+// clients that don't understand it.
+func ContentDisposition(disposition, filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r >= 0x7f || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+
+	value := disposition + `; filename="` + fallback + `"`
+	if fallback != filename {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return value
+}
+
+// matchAny reports whether etag is in an If-None-Match list, comparing weakly
+func matchAny(list, etag string) bool {
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeRFC5987 percent-encodes every byte of s except RFC 5987 attr-chars
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,99 @@
+package download
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNotModified(t *testing.T) {
+	etag := `"abc"`
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	at := func(t time.Time) string { return t.Format(http.TimeFormat) }
+
+	tests := []struct {
+		name                         string
+		ifNoneMatch, ifModifiedSince string
+		etag                         string
+		want                         bool
+	}{
+		{"no conditions", "", "", etag, false},
+		{"matching ETag", `"abc"`, "", etag, true},
+		{"weak match", `W/"abc"`, "", etag, true},
+		{"match in a list", `"x", "abc"`, "", etag, true},
+		{"wildcard", "*", "", etag, true},
+		{"other ETag", `"xyz"`, "", etag, false},
+		{"file without an ETag", `"abc"`, "", "", false},
+		{"ETag wins over the date", `"xyz"`, at(modified.Add(time.Hour)), etag, false},
+		{"unchanged since", "", at(modified), etag, true},
+		{"changed since", "", at(modified.Add(-time.Hour)), etag, false},
+		{"invalid date", "", "yesterday", etag, false},
+	}
+
+	for _, tt := range tests {
+		if got := NotModified(tt.ifNoneMatch, tt.ifModifiedSince, tt.etag, modified); got != tt.want {
+			t.Errorf("%s: NotModified = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIfRange(t *testing.T) {
+	etag := `"abc"`
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+
+	tests := []struct {
+		name    string
+		ifRange string
+		etag    string
+		want    bool
+	}{
+		{"absent", "", etag, true},
+		{"matching ETag", `"abc"`, etag, true},
+		{"other ETag", `"xyz"`, etag, false},
+		{"weak ETags never match", `W/"abc"`, etag, false},
+		{"file without an ETag", `"abc"`, "", false},
+		{"matching date", modified.Format(http.TimeFormat), etag, true},
+		{"older date", modified.Add(-time.Second).Format(http.TimeFormat), etag, false},
+		{"newer date", modified.Add(time.Second).Format(http.TimeFormat), etag, false},
+		{"invalid date", "yesterday", etag, false},
+	}
+
+	for _, tt := range tests {
+		if got := IfRange(tt.ifRange, tt.etag, modified); got != tt.want {
+			t.Errorf("%s: IfRange(%q) = %v, want %v", tt.name, tt.ifRange, got, tt.want)
+		}
+	}
+
+	if IfRange(modified.Format(http.TimeFormat), etag, time.Time{}) {
+		t.Error("a date matched a file without a modification time")
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		disposition, filename string
+		want                  string
+	}{
+		{"attachment", "report.pdf", `attachment; filename="report.pdf"`},
+		{"inline", "Q1 report.pdf", `inline; filename="Q1 report.pdf"`},
+		{"attachment", `say "hi"\.pdf`, `attachment; filename="say _hi__.pdf"; filename*=UTF-8''say%20%22hi%22%5C.pdf`},
+		{"attachment", "Übersicht €.pdf", `attachment; filename="_bersicht _.pdf"; filename*=UTF-8''%C3%9Cbersicht%20%E2%82%AC.pdf`},
+		{"attachment", "日本.pdf", `attachment; filename="__.pdf"; filename*=UTF-8''%E6%97%A5%E6%9C%AC.pdf`},
+		{"attachment", "a\r\nb.pdf", `attachment; filename="a__b.pdf"; filename*=UTF-8''a%0D%0Ab.pdf`},
+	}
+
+	for _, tt := range tests {
+		if got := ContentDisposition(tt.disposition, tt.filename); got != tt.want {
+			t.Errorf("ContentDisposition(%q, %q) = %s, want %s", tt.disposition, tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestETag(t *testing.T) {
+	if got := ETag("abc"); got != `"abc"` {
+		t.Errorf(`ETag("abc") = %s`, got)
+	}
+	if got := ETag(""); got != "" {
+		t.Errorf(`ETag("") = %s, want none`, got)
+	}
+}
@@ -0,0 +1,176 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRanges is the most ranges served from one request. Asking for more gets the whole file.
+const MaxRanges = 32
+
+var ErrUnsatisfiable = errors.New("none of the requested ranges overlap the file")
+
+// Range is a run of bytes within a file
+type Range struct {
+	Start  int64
+	Length int64
+}
+
+// ContentRange is the Content-Range header value for r within a file of size bytes
+func (r Range) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.Start+r.Length-1, size)
+}
+
+// ParseRange parses a Range header for a file of size bytes. Ranges past the
+// end of the file are clipped. When no range overlaps the file it returns
+// ErrUnsatisfiable. Headers that should be ignored, such as other units,
+// malformed ones or ones asking for more bytes than the file holds, return no
+// ranges so the whole file is sent.
+func ParseRange(header string, size int64) ([]Range, error) {
+	specs, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return nil, nil
+	}
+
+	var ranges []Range
+	var total int64
+	parsed := 0
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		parsed++
+
+		first, last, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, nil
+		}
+		first = strings.TrimSpace(first)
+		last = strings.TrimSpace(last)
+
+		var r Range
+		if first == "" {
+			// "-n" is the last n bytes
+			n, ok := parseOffset(last)
+			if !ok {
+				return nil, nil
+			}
+			if n == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r = Range{Start: size - n, Length: n}
+		} else {
+			start, ok := parseOffset(first)
+			if !ok {
+				return nil, nil
+			}
+			end := size - 1
+			if last != "" {
+				n, ok := parseOffset(last)
+				if !ok || n < start {
+					return nil, nil
+				}
+				if n < end {
+					end = n
+				}
+			}
+			if start >= size {
+				continue
+			}
+			r = Range{Start: start, Length: end - start + 1}
+		}
+
+		ranges = append(ranges, r)
+		total += r.Length
+	}
+
+	if parsed == 0 {
+		return nil, nil
+	}
+	if len(ranges) == 0 {
+		return nil, ErrUnsatisfiable
+	}
+	// Overlapping ranges could otherwise make a small file send far more than its size
+	if len(ranges) > MaxRanges || total > size {
+		return nil, nil
+	}
+	return ranges, nil
+}
+
+// Ranges resolves the Range and If-Range headers of a GET for a file of size
+// bytes with the given ETag and modification time. No ranges means the whole
+// file is sent, and ErrUnsatisfiable means 416.
+func Ranges(rangeHeader, ifRange, etag string, modified time.Time, size int64) ([]Range, error) {
+	if rangeHeader == "" || !IfRange(ifRange, etag, modified) {
+		return nil, nil
+	}
+	return ParseRange(rangeHeader, size)
+}
+
+// IncludesStart reports whether a response sending ranges, or the whole file
+// when there are none, sends the file's first byte. Every complete download
+// does, however it was asked for, so it is how downloads are counted once.
+func IncludesStart(ranges []Range) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if r.Start == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Multipart lays out ranges of r as a multipart/byteranges body, returning the
+// body, its length and the boundary for the Content-Type header. Nothing is
+// read from r until the body is.
+func Multipart(r io.ReaderAt, ranges []Range, size int64, contentType string) (io.Reader, int64, string) {
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+
+	var parts []io.Reader
+	var length int64
+	for i, rng := range ranges {
+		header := fmt.Sprintf("--%s\r\nContent-Type: %s\r\nContent-Range: %s\r\n\r\n", boundary, contentType, rng.ContentRange(size))
+		if i > 0 {
+			header = "\r\n" + header
+		}
+		parts = append(parts, strings.NewReader(header), io.NewSectionReader(r, rng.Start, rng.Length))
+		length += int64(len(header)) + rng.Length
+	}
+
+	trailer := fmt.Sprintf("\r\n--%s--\r\n", boundary)
+	parts = append(parts, strings.NewReader(trailer))
+	length += int64(len(trailer))
+
+	return io.MultiReader(parts...), length, boundary
+}
+
+// parseOffset parses a byte offset, which unlike strconv.ParseInt allows no sign
+func parseOffset(s string) (int64, bool) {
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil
+}
+
+// ReadCloser reads from one reader and closes another, so a body read from
+// part of a file still closes the file
+func ReadCloser(r io.Reader, closer io.Closer) io.ReadCloser {
+	return readCloser{r, closer}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,140 @@
+package download
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRange(t *testing.T) {
+	const size = 100
+
+	manyRanges := make([]string, MaxRanges+1)
+	var maxRanges []Range
+	for i := range manyRanges {
+		manyRanges[i] = fmt.Sprintf("%d-%d", i, i)
+		if i < MaxRanges {
+			maxRanges = append(maxRanges, Range{Start: int64(i), Length: 1})
+		}
+	}
+
+	tests := []struct {
+		header  string
+		want    []Range
+		wantErr error
+	}{
+		{"bytes=0-9", []Range{{Start: 0, Length: 10}}, nil},
+		{"bytes=90-", []Range{{Start: 90, Length: 10}}, nil},
+		{"bytes=90-200", []Range{{Start: 90, Length: 10}}, nil},
+		{"bytes= 10 - 19 ", []Range{{Start: 10, Length: 10}}, nil},
+		{"bytes=0-0,-1", []Range{{Start: 0, Length: 1}, {Start: 99, Length: 1}}, nil},
+
+		// Suffix ranges are the last n bytes, clipped to the file
+		{"bytes=-10", []Range{{Start: 90, Length: 10}}, nil},
+		{"bytes=-1000", []Range{{Start: 0, Length: 100}}, nil},
+		{"bytes=-0", nil, ErrUnsatisfiable},
+
+		// Nothing overlaps the file
+		{"bytes=100-", nil, ErrUnsatisfiable},
+		{"bytes=200-300", nil, ErrUnsatisfiable},
+		{"bytes=100-,150-160", nil, ErrUnsatisfiable},
+		// Ranges that don't overlap are dropped when others do
+		{"bytes=200-300,0-4", []Range{{Start: 0, Length: 5}}, nil},
+
+		// Overlapping ranges adding up to more than the file send the whole file
+		{"bytes=0-99,0-99", nil, nil},
+		{"bytes=0-60,40-99", nil, nil},
+		{"bytes=0-49,50-99", []Range{{Start: 0, Length: 50}, {Start: 50, Length: 50}}, nil},
+
+		// More than MaxRanges send the whole file
+		{"bytes=" + strings.Join(manyRanges[:MaxRanges], ","), maxRanges, nil},
+		{"bytes=" + strings.Join(manyRanges, ","), nil, nil},
+
+		// Ignored headers
+		{"items=0-9", nil, nil},
+		{"bytes=", nil, nil},
+		{"bytes=9-0", nil, nil},
+		{"bytes=a-b", nil, nil},
+		{"bytes=+1-5", nil, nil},
+		{"bytes=5", nil, nil},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRange(tt.header, size)
+		if err != tt.wantErr {
+			t.Errorf("ParseRange(%q) error = %v, want %v", tt.header, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRange(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	if got := (Range{Start: 10, Length: 5}).ContentRange(100); got != "bytes 10-14/100" {
+		t.Errorf("ContentRange = %q", got)
+	}
+}
+
+func TestMultipart(t *testing.T) {
+	content := strings.NewReader("0123456789abcdefghij")
+	ranges := []Range{{Start: 0, Length: 3}, {Start: 15, Length: 5}}
+
+	body, length, boundary := Multipart(content, ranges, 20, "application/pdf")
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if int64(len(data)) != length {
+		t.Errorf("body is %d bytes, Multipart said %d", len(data), length)
+	}
+
+	want := "--" + boundary + "\r\nContent-Type: application/pdf\r\nContent-Range: bytes 0-2/20\r\n\r\n012" +
+		"\r\n--" + boundary + "\r\nContent-Type: application/pdf\r\nContent-Range: bytes 15-19/20\r\n\r\nfghij" +
+		"\r\n--" + boundary + "--\r\n"
+	if string(data) != want {
+		t.Errorf("body = %q, want %q", data, want)
+	}
+}
+
+func TestDownloadsCountedOnce(t *testing.T) {
+	const size = 1000
+	etag := `"abc"`
+	modified := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name              string
+		rangeHeader       string
+		ifRange           string
+		wantIncludesStart bool
+	}{
+		{"whole file", "", "", true},
+		{"first range", "bytes=0-499", "", true},
+		{"later part of a ranged download", "bytes=500-", "", false},
+		{"suffix range clipped to the whole file", "bytes=-999999999", "", true},
+		{"suffix range of the tail", "bytes=-100", "", false},
+		{"multi-range reaching the start", "bytes=1-,0-0", "", true},
+		{"multi-range past the start", "bytes=10-19,100-199", "", false},
+		{"stale If-Range falls back to the whole file", "bytes=5-", `"old"`, true},
+		{"matching If-Range keeps the range", "bytes=5-", etag, false},
+		{"stale If-Range date", "bytes=5-", modified.Add(-time.Hour).UTC().Format(http.TimeFormat), true},
+		{"other units send the whole file", "items=5-", "", true},
+		{"too many ranges send the whole file", "bytes=1-1,2-2,3-3,4-4,5-5,6-6,7-7,8-8,9-9,10-10,11-11,12-12,13-13,14-14,15-15,16-16,17-17,18-18,19-19,20-20,21-21,22-22,23-23,24-24,25-25,26-26,27-27,28-28,29-29,30-30,31-31,32-32,33-33", "", true},
+	}
+
+	for _, tt := range tests {
+		ranges, err := Ranges(tt.rangeHeader, tt.ifRange, etag, modified, size)
+		if err != nil {
+			t.Errorf("%s: Ranges: %v", tt.name, err)
+			continue
+		}
+		if got := IncludesStart(ranges); got != tt.wantIncludesStart {
+			t.Errorf("%s: IncludesStart(%v) = %v, want %v", tt.name, ranges, got, tt.wantIncludesStart)
+		}
+	}
+}
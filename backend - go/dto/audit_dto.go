@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+type AuditEventResponse struct {
+	ID          uint                   `json:"id"`
+	CreatedAt   time.Time              `json:"created_at"`
+	WorkspaceID *uint                  `json:"workspace_id"`
+	ActorID     *uint                  `json:"actor_id"`
+	APIKeyID    *uint                  `json:"api_key_id,omitempty"`
+	IP          string                 `json:"ip"`
+	RequestID   string                 `json:"request_id"`
+	Action      string                 `json:"action"`
+	TargetType  string                 `json:"target_type"`
+	TargetID    string                 `json:"target_id,omitempty"`
+	Before      map[string]interface{} `json:"before,omitempty"`
+	After       map[string]interface{} `json:"after,omitempty"`
+}
+
+type AuditEventListResponse struct {
+	Data         []AuditEventResponse `json:"data"`
+	Page         int                  `json:"page"`
+	ItemsPerPage int                  `json:"itemsPerPage"`
+	TotalPages   int                  `json:"totalPages"`
+	TotalItems   int64                `json:"totalItems"`
+}
@@ -0,0 +1,64 @@
+package dto
+
+import "time"
+
+// CollectionRequest creates a collection inside ParentID, or at the top level when it is null
+type CollectionRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+type CollectionRenameRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CollectionMoveRequest moves a collection inside ParentID, or to the top level when it is null
+type CollectionMoveRequest struct {
+	ParentID *uint `json:"parent_id"`
+}
+
+type CollectionResponse struct {
+	ID         uint      `json:"id"`
+	Name       string    `json:"name"`
+	ParentID   *uint     `json:"parent_id"`
+	Path       string    `json:"path"`
+	Depth      int       `json:"depth"`
+	ChildCount int64     `json:"child_count"`
+	PDFCount   int64     `json:"pdf_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// CollectionDetailResponse is a collection with the path leading to it and its subcollections
+type CollectionDetailResponse struct {
+	CollectionResponse
+	Breadcrumbs []BreadcrumbResponse `json:"breadcrumbs"`
+	Children    []CollectionResponse `json:"children"`
+}
+
+type CollectionListResponse struct {
+	Data []CollectionResponse `json:"data"`
+}
+
+// BreadcrumbResponse is one collection on the path from the top level down to a collection
+type BreadcrumbResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type CollectionDeleteResponse struct {
+	Message  string `json:"message"`
+	Mode     string `json:"mode"`
+	PDFCount int64  `json:"pdf_count"`
+}
+
+// MovePDFsRequest files PDFs in CollectionID, or takes them out of their collection when it is null
+type MovePDFsRequest struct {
+	PDFIDs       []uint `json:"pdf_ids" binding:"required"`
+	CollectionID *uint  `json:"collection_id"`
+}
+
+type MovePDFsResponse struct {
+	Message    string `json:"message"`
+	MovedCount int    `json:"moved_count"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+type SummaryJobResponse struct {
+	ID          uint             `json:"id"`
+	PDFID       uint             `json:"pdf_id"`
+	Style       string           `json:"style"`
+	Language    string           `json:"language"`
+	Status      string           `json:"status"`
+	Attempts    int              `json:"attempts"`
+	MaxAttempts int              `json:"max_attempts"`
+	Error       string           `json:"error,omitempty"`
+	Cached      bool             `json:"cached"`
+	SummaryID   *uint            `json:"summary_id,omitempty"`
+	StartedAt   *time.Time       `json:"started_at,omitempty"`
+	FinishedAt  *time.Time       `json:"finished_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	Summary     *SummaryResponse `json:"summary,omitempty"`
+}
+
+type SummaryJobListResponse struct {
+	Data         []SummaryJobResponse `json:"data"`
+	Page         int                  `json:"page"`
+	ItemsPerPage int                  `json:"itemsPerPage"`
+	TotalPages   int                  `json:"totalPages"`
+	TotalItems   int64                `json:"totalItems"`
+}
@@ -9,15 +9,31 @@ type PDFCreateRequest struct {
 	PageCount int    `json:"page_count" binding:"required"`
 }
 
+// PDFUpdateRequest changes only the fields that are present. CustomFields are
+// merged into the existing fields and a null value removes a field.
+type PDFUpdateRequest struct {
+	Title        *string                `json:"title"`
+	Description  *string                `json:"description"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
+}
+
 type PDFResponse struct {
-	ID        uint              `json:"id"`
-	Filename  string            `json:"filename"`
-	FileSize  int64             `json:"file_size"`
-	Title     string            `json:"title"`
-	PageCount int               `json:"page_count"`
-	CreatedAt time.Time         `json:"created_at"`
-	UpdatedAt time.Time         `json:"updated_at"`
-	Summaries []SummaryResponse `json:"summaries"`
+	ID           uint                   `json:"id"`
+	Filename     string                 `json:"filename"`
+	FileSize     int64                  `json:"file_size"`
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
+	PageCount    int                    `json:"page_count"`
+	ContentHash  string                 `json:"content_hash,omitempty"`
+	TextStatus   string                 `json:"text_status"`
+	Version      int                    `json:"version"`
+	Tags         []TagResponse          `json:"tags"`
+	CollectionID *uint                  `json:"collection_id"`
+	Breadcrumbs  []BreadcrumbResponse   `json:"breadcrumbs,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+	Summaries    []SummaryResponse      `json:"summaries"`
 }
 
 type PDFListResponse struct {
@@ -28,6 +44,64 @@ type PDFListResponse struct {
 	TotalItems   int64         `json:"totalItems"`
 }
 
+// BatchUploadResult is the outcome for one file of a batch upload. Status is
+// created, duplicate (with dedupe, an identical PDF already exists) or failed.
+type BatchUploadResult struct {
+	Filename string              `json:"filename"`
+	Status   string              `json:"status"`
+	Error    string              `json:"error,omitempty"`
+	Message  string              `json:"message,omitempty"`
+	PDF      *PDFResponse        `json:"pdf,omitempty"`
+	Job      *SummaryJobResponse `json:"job,omitempty"`
+	JobError string              `json:"job_error,omitempty"`
+}
+
+type BatchUploadResponse struct {
+	Results   []BatchUploadResult `json:"results"`
+	Created   int                 `json:"created"`
+	Duplicate int                 `json:"duplicate"`
+	Failed    int                 `json:"failed"`
+}
+
+type PDFPageResponse struct {
+	PageNumber int    `json:"page_number"`
+	CharCount  int    `json:"char_count"`
+	Text       string `json:"text,omitempty"`
+}
+
+type PDFPagesResponse struct {
+	PDFID      uint              `json:"pdf_id"`
+	TextStatus string            `json:"text_status"`
+	PageCount  int               `json:"page_count"`
+	Pages      []PDFPageResponse `json:"pages"`
+}
+
+type PDFVersionResponse struct {
+	Version          int           `json:"version"`
+	Filename         string        `json:"filename"`
+	OriginalFilename string        `json:"original_filename,omitempty"`
+	FileSize         int64         `json:"file_size"`
+	PageCount        int           `json:"page_count"`
+	ContentHash      string        `json:"content_hash,omitempty"`
+	Current          bool          `json:"current"`
+	UploadedBy       *UserResponse `json:"uploaded_by,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+}
+
+type PDFVersionListResponse struct {
+	Data         []PDFVersionResponse `json:"data"`
+	Page         int                  `json:"page"`
+	ItemsPerPage int                  `json:"itemsPerPage"`
+	TotalPages   int                  `json:"totalPages"`
+	TotalItems   int64                `json:"totalItems"`
+}
+
+// PDFReplaceResponse is the PDF after its file was replaced with the version that was created
+type PDFReplaceResponse struct {
+	PDF     PDFResponse        `json:"pdf"`
+	Version PDFVersionResponse `json:"version"`
+}
+
 type PDFCountResponse struct {
 	Count int64 `json:"count"`
 }
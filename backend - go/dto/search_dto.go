@@ -0,0 +1,21 @@
+package dto
+
+type SearchHitResponse struct {
+	Type       string  `json:"type"`
+	PDFID      uint    `json:"pdf_id"`
+	PDFTitle   string  `json:"pdf_title"`
+	PageNumber *int    `json:"page_number,omitempty"`
+	SummaryID  *uint   `json:"summary_id,omitempty"`
+	Language   *string `json:"language,omitempty"`
+	Rank       float64 `json:"rank"`
+	Snippet    string  `json:"snippet"`
+}
+
+type SearchResponse struct {
+	Query        string              `json:"query"`
+	Data         []SearchHitResponse `json:"data"`
+	Page         int                 `json:"page"`
+	ItemsPerPage int                 `json:"itemsPerPage"`
+	TotalPages   int                 `json:"totalPages"`
+	TotalItems   int64               `json:"totalItems"`
+}
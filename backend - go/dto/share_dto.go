@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type CreateShareRequest struct {
+	ExpiresAt    *time.Time `json:"expires_at"` // defaults to SHARE_LINK_DEFAULT_TTL from now
+	Password     string     `json:"password"`
+	MaxDownloads *int       `json:"max_downloads"`
+}
+
+type ShareLinkResponse struct {
+	ID                uint       `json:"id"`
+	Type              string     `json:"type"` // "pdf" or "summary"
+	PDFID             *uint      `json:"pdf_id,omitempty"`
+	SummaryID         *uint      `json:"summary_id,omitempty"`
+	Token             string     `json:"token"`
+	URL               string     `json:"url"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	PasswordProtected bool       `json:"password_protected"`
+	MaxDownloads      *int       `json:"max_downloads"`
+	DownloadCount     int        `json:"download_count"`
+	LastDownloadedAt  *time.Time `json:"last_downloaded_at"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	Active            bool       `json:"active"`
+	CreatedByID       uint       `json:"created_by_id"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// SharedSummaryResponse is what a summary share link shows with format=json
+type SharedSummaryResponse struct {
+	Title     string    `json:"title"`
+	Style     string    `json:"style"`
+	Language  string    `json:"language"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
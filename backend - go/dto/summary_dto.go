@@ -7,6 +7,7 @@ import (
 type SummarizeRequest struct {
 	Style    string `json:"style" binding:"required"`
 	Language string `json:"language" binding:"required"`
+	Force    bool   `json:"force"`
 }
 
 type SummaryCreateRequest struct {
@@ -18,15 +19,52 @@ type SummaryCreateRequest struct {
 }
 
 type SummaryResponse struct {
-	ID          uint          `json:"id"`
-	Style       string        `json:"style"`
-	Content     string        `json:"content"`
-	PDFID       uint          `json:"pdf_id"`
-	Language    string        `json:"language"`
-	SummaryTime float64       `json:"summary_time"`
-	CreatedAt   time.Time     `json:"created_at"`
-	UpdatedAt   time.Time     `json:"updated_at"`
-	PDF         *PDFBasicInfo `json:"pdf,omitempty"`
+	ID              uint                   `json:"id"`
+	Style           string                 `json:"style"`
+	Content         string                 `json:"content"`
+	PDFID           uint                   `json:"pdf_id"`
+	PDFVersion      int                    `json:"pdf_version"`
+	Stale           bool                   `json:"stale"`
+	Language        string                 `json:"language"`
+	SummaryTime     float64                `json:"summary_time"`
+	WordCount       int                    `json:"word_count"`
+	ReadingTime     string                 `json:"reading_time"`
+	ChunksProcessed int                    `json:"chunks_processed"`
+	ChunkingUsed    bool                   `json:"chunking_used"`
+	TextStatistics  map[string]interface{} `json:"text_statistics,omitempty"`
+	FileInfo        *FileInfo              `json:"file_info,omitempty"`
+	EditedAt        *time.Time             `json:"edited_at,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+	PDF             *PDFBasicInfo          `json:"pdf,omitempty"`
+}
+
+type SummaryUpdateRequest struct {
+	Content string `json:"content" binding:"required"`
+	Note    string `json:"note"`
+}
+
+type SummaryRevisionResponse struct {
+	Revision  int           `json:"revision"`
+	Content   string        `json:"content"`
+	WordCount int           `json:"word_count"`
+	Note      string        `json:"note,omitempty"`
+	EditedBy  *UserResponse `json:"edited_by,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type SummaryRevisionListResponse struct {
+	Data         []SummaryRevisionResponse `json:"data"`
+	Page         int                       `json:"page"`
+	ItemsPerPage int                       `json:"itemsPerPage"`
+	TotalPages   int                       `json:"totalPages"`
+	TotalItems   int64                     `json:"totalItems"`
+}
+
+// SummaryEditResponse is the summary after an edit or revert with the revision it created
+type SummaryEditResponse struct {
+	Summary  SummaryResponse         `json:"summary"`
+	Revision SummaryRevisionResponse `json:"revision"`
 }
 
 type PDFBasicInfo struct {
@@ -0,0 +1,36 @@
+package dto
+
+type TagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type TagResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type TagCountResponse struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	PDFCount int64  `json:"pdf_count"`
+}
+
+type TagListResponse struct {
+	Data []TagCountResponse `json:"data"`
+}
+
+// PDFTagsRequest names the tags to attach to or detach from a PDF. Missing tags are created when attaching.
+type PDFTagsRequest struct {
+	Tags []string `json:"tags" binding:"required"`
+}
+
+type BulkTagRequest struct {
+	PDFIDs []uint   `json:"pdf_ids" binding:"required"`
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+type BulkTagResponse struct {
+	Message      string `json:"message"`
+	UpdatedCount int    `json:"updated_count"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type TrashItemResponse struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	PDFID     uint      `json:"pdf_id"`
+	Title     string    `json:"title"`
+	Style     *string   `json:"style,omitempty"`
+	Language  *string   `json:"language,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+type TrashResponse struct {
+	Data         []TrashItemResponse `json:"data"`
+	Page         int                 `json:"page"`
+	ItemsPerPage int                 `json:"itemsPerPage"`
+	TotalPages   int                 `json:"totalPages"`
+	TotalItems   int64               `json:"totalItems"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// UploadResponse describes a resumable upload. Offset is how many bytes have been received.
+type UploadResponse struct {
+	ID        string    `json:"id"`
+	Filename  string    `json:"filename"`
+	Title     string    `json:"title"`
+	Length    int64     `json:"length"`
+	Offset    int64     `json:"offset"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Location  string    `json:"location"`
+}
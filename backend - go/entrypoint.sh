@@ -10,7 +10,7 @@ echo "Database is up - executing command"
 
 # Run database migration
 echo "Running database migration..."
-./migrate
+./migrate up
 
 # Start the main application
 echo "Starting the application..."
@@ -0,0 +1,86 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Page is the plain text of one PDF page. Numbers start at 1.
+type Page struct {
+	Number int
+	Text   string
+}
+
+// Pages extracts the plain text of every page. A page whose text can't be
+// decoded comes back empty rather than failing the whole document.
+func Pages(r io.Reader) (pages []Page, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	// The PDF parser panics on some malformed documents
+	defer func() {
+		if rec := recover(); rec != nil {
+			pages = nil
+			err = fmt.Errorf("failed to parse PDF: %v", rec)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+
+	// Cache fonts across pages so charmaps are only parsed once
+	fonts := make(map[string]*pdf.Font)
+
+	total := reader.NumPage()
+	pages = make([]Page, 0, total)
+	for i := 1; i <= total; i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			pages = append(pages, Page{Number: i})
+			continue
+		}
+
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				font := page.Font(name)
+				fonts[name] = &font
+			}
+		}
+
+		text, err := page.GetPlainText(fonts)
+		if err != nil {
+			text = ""
+		}
+		pages = append(pages, Page{Number: i, Text: clean(text)})
+	}
+
+	return pages, nil
+}
+
+// Text extracts the plain text of a whole PDF document
+func Text(r io.Reader) (string, error) {
+	pages, err := Pages(r)
+	if err != nil {
+		return "", err
+	}
+
+	texts := make([]string, len(pages))
+	for i, page := range pages {
+		texts[i] = page.Text
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// clean makes extracted text safe to store: PostgreSQL text rejects NUL bytes and invalid UTF-8
+func clean(text string) string {
+	text = strings.ToValidUTF8(text, "")
+	return strings.ReplaceAll(text, "\x00", "")
+}
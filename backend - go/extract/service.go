@@ -0,0 +1,273 @@
+package extract
+
+import (
+	"backend-go/models"
+	"backend-go/storage"
+	"backend-go/utils"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Service extracts page text for uploaded PDFs in the background.
+// A PDF's text_status is its queue state, so pending documents survive restarts.
+// Running extractions are leased to this service's instance and kept alive
+// by heartbeats, so services on several replicas can share the table.
+type Service struct {
+	db           *gorm.DB
+	blobs        storage.BlobStore
+	workers      int
+	pollInterval time.Duration
+	instance     string
+	lease        time.Duration
+	queue        chan uint
+	wg           sync.WaitGroup
+}
+
+// NewService creates an extraction service. It does nothing until Start is called.
+func NewService(db *gorm.DB, blobs storage.BlobStore, workers int) *Service {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Service{
+		db:           db,
+		blobs:        blobs,
+		workers:      workers,
+		pollInterval: 30 * time.Second,
+		instance:     utils.InstanceID(),
+		lease:        time.Minute,
+		queue:        make(chan uint, 100),
+	}
+}
+
+// Start resets extractions abandoned by a stopped server and launches the workers.
+// The poller also backfills PDFs uploaded before extraction existed.
+func (s *Service) Start(ctx context.Context) error {
+	if err := s.recover(); err != nil {
+		return err
+	}
+
+	for i := 0; i < s.workers; i++ {
+		s.wg.Add(1)
+		go s.work(ctx)
+	}
+
+	s.wg.Add(1)
+	go s.poll(ctx)
+
+	return nil
+}
+
+// Wait blocks until all workers have exited after ctx is cancelled
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
+
+// Enqueue wakes up a worker for a pending PDF without blocking.
+// When the channel is full the poller will find the PDF later.
+func (s *Service) Enqueue(pdfID uint) {
+	select {
+	case s.queue <- pdfID:
+	default:
+	}
+}
+
+// Depth counts the PDFs waiting for extraction
+func (s *Service) Depth() (int64, error) {
+	var count int64
+	err := s.db.Model(&models.PDF{}).Where("text_status = ?", models.TextStatusPending).Count(&count).Error
+	return count, err
+}
+
+// recover puts extractions whose lease ran out, because their server crashed
+// or was killed, back to pending. Extractions other servers are still
+// working on keep renewing their lease.
+func (s *Service) recover() error {
+	result := s.db.Model(&models.PDF{}).
+		Where("text_status = ?", models.TextStatusExtracting).
+		Where("text_heartbeat_at IS NULL OR text_heartbeat_at < ?", time.Now().Add(-s.lease)).
+		Update("text_status", models.TextStatusPending)
+	if result.Error != nil {
+		return fmt.Errorf("failed to reset abandoned extractions: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		fmt.Printf("Reset %d abandoned text extractions\n", result.RowsAffected)
+	}
+	return nil
+}
+
+func (s *Service) poll(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.recover(); err != nil {
+			fmt.Println(err)
+		}
+
+		var ids []uint
+		if err := s.db.Model(&models.PDF{}).
+			Where("text_status = ?", models.TextStatusPending).
+			Order("id asc").
+			Limit(cap(s.queue)).
+			Pluck("id", &ids).Error; err != nil {
+			fmt.Printf("Failed to poll pending extractions: %v\n", err)
+		}
+		for _, id := range ids {
+			s.Enqueue(id)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) work(ctx context.Context) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			s.run(ctx, id)
+		}
+	}
+}
+
+// run claims a pending PDF, extracts it and records the outcome
+func (s *Service) run(ctx context.Context, id uint) {
+	// Claim atomically so a PDF notified twice is only extracted once
+	result := s.db.Model(&models.PDF{}).
+		Where("id = ? AND text_status = ?", id, models.TextStatusPending).
+		Updates(map[string]interface{}{
+			"text_status":       models.TextStatusExtracting,
+			"text_locked_by":    s.instance,
+			"text_heartbeat_at": time.Now(),
+		})
+	if result.Error != nil {
+		fmt.Printf("Failed to claim PDF %d for extraction: %v\n", id, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	var pdf models.PDF
+	if err := s.db.First(&pdf, id).Error; err != nil {
+		fmt.Printf("Failed to load PDF %d for extraction: %v\n", id, err)
+		return
+	}
+
+	stop := s.heartbeat(id)
+	err := s.ExtractPDF(ctx, &pdf)
+	stop()
+
+	if err != nil {
+		fmt.Printf("Text extraction for PDF %d failed: %v\n", id, err)
+
+		status := models.TextStatusFailed
+		if ctx.Err() != nil {
+			// Cut short by shutdown, try again on the next start
+			status = models.TextStatusPending
+		}
+		// Leave the status alone if the lease was lost, e.g. the file was replaced meanwhile
+		if err := s.leased(id).Update("text_status", status).Error; err != nil {
+			fmt.Printf("Failed to update text status for PDF %d: %v\n", id, err)
+		}
+	}
+}
+
+// heartbeat renews the lease on a running extraction until the returned func is called
+func (s *Service) heartbeat(id uint) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(s.lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.leased(id).Update("text_heartbeat_at", time.Now()).Error; err != nil {
+					fmt.Printf("Failed to renew lease on extraction of PDF %d: %v\n", id, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// leased selects a PDF while this instance holds the lease on its extraction
+func (s *Service) leased(id uint) *gorm.DB {
+	return s.db.Model(&models.PDF{}).
+		Where("id = ? AND text_status = ? AND text_locked_by = ?", id, models.TextStatusExtracting, s.instance)
+}
+
+// ExtractPDF reads the PDF's file and replaces its stored pages with freshly extracted text
+func (s *Service) ExtractPDF(ctx context.Context, pdf *models.PDF) error {
+	object, err := s.blobs.Get(ctx, pdf.Filename)
+	if err != nil {
+		return fmt.Errorf("failed to open PDF file: %w", err)
+	}
+	defer object.Close()
+
+	pages, err := Pages(object)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]models.PDFPage, len(pages))
+	for i, page := range pages {
+		rows[i] = models.PDFPage{
+			PDFID:      pdf.ID,
+			PageNumber: page.Number,
+			Text:       page.Text,
+			CharCount:  utf8.RuneCountInString(page.Text),
+		}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// The file may have been replaced while it was read. The new version is
+		// pending again, so leave its pages to the next extraction.
+		var current models.PDF
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "filename").First(&current, pdf.ID).Error; err != nil {
+			return err
+		}
+		if current.Filename != pdf.Filename {
+			return nil
+		}
+
+		if err := tx.Where("pdf_id = ?", pdf.ID).Delete(&models.PDFPage{}).Error; err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(rows, 100).Error; err != nil {
+				return err
+			}
+		}
+
+		pdf.TextStatus = models.TextStatusExtracted
+		return tx.Model(pdf).Update("text_status", models.TextStatusExtracted).Error
+	})
+}
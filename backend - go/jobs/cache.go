@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"backend-go/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// SummaryCache finds summaries already generated for the same document
+// content, style, language, provider and prompt version within one
+// workspace, so content never crosses tenants. Because the key is the
+// content hash, replacing a PDF's file naturally stops matching old summaries.
+type SummaryCache struct {
+	db            *gorm.DB
+	promptVersion string
+}
+
+// NewSummaryCache creates a cache. Bump promptVersion whenever prompts change to invalidate old entries.
+func NewSummaryCache(db *gorm.DB, promptVersion string) *SummaryCache {
+	return &SummaryCache{db: db, promptVersion: promptVersion}
+}
+
+// PromptVersion is stamped on every generated summary
+func (c *SummaryCache) PromptVersion() string {
+	return c.promptVersion
+}
+
+// Lookup returns a cached summary for pdf made by provider, or nil on a miss.
+// A hit generated for another PDF in the same workspace with identical
+// content is copied onto this PDF. Summaries edited by hand are only reused
+// for their own PDF.
+func (c *SummaryCache) Lookup(pdf models.PDF, style, language, provider string) (*models.Summaries, error) {
+	// Records uploaded before content hashing have nothing to key on
+	if pdf.ContentHash == "" {
+		return nil, nil
+	}
+
+	var cached models.Summaries
+	err := c.db.
+		Where("content_hash = ? AND style = ? AND language = ? AND prompt_version = ?", pdf.ContentHash, style, language, c.promptVersion).
+		Where("workspace_id IS NOT DISTINCT FROM ? AND provider = ?", pdf.WorkspaceID, provider).
+		Where("edited_at IS NULL OR pdf_id = ?", pdf.ID).
+		Order(fmt.Sprintf("pdf_id = %d DESC", pdf.ID)).
+		Order("created_at desc").
+		First(&cached).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up cached summary: %w", err)
+	}
+
+	if cached.PDFID == pdf.ID {
+		return &cached, nil
+	}
+
+	copied := cached
+	copied.Model = gorm.Model{}
+	copied.PDFID = pdf.ID
+	copied.PDFVersion = pdf.Version
+	copied.OwnerID = pdf.OwnerID
+	copied.WorkspaceID = pdf.WorkspaceID
+	copied.PDF = models.PDF{}
+	if err := c.db.Create(&copied).Error; err != nil {
+		return nil, fmt.Errorf("failed to copy cached summary: %w", err)
+	}
+
+	return &copied, nil
+}
@@ -0,0 +1,316 @@
+package jobs
+
+import (
+	"backend-go/models"
+	"backend-go/summarizer"
+	"backend-go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Processor performs the work for a single claimed job.
+// On success it may set job.SummaryID to link the produced summary.
+type Processor func(ctx context.Context, job *models.SummaryJob) error
+
+// Pool is a bounded set of workers draining summary jobs persisted in the database.
+// The database is the source of truth; the channel only wakes workers up early.
+// Running jobs are leased to this pool's instance and kept alive by heartbeats,
+// so pools on several replicas can share the table.
+type Pool struct {
+	db           *gorm.DB
+	process      Processor
+	workers      int
+	maxAttempts  int
+	pollInterval time.Duration
+	instance     string
+	lease        time.Duration
+	queue        chan uint
+	wg           sync.WaitGroup
+}
+
+// NewPool creates a worker pool. It does nothing until Start is called.
+func NewPool(db *gorm.DB, workers, queueSize, maxAttempts int, process Processor) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 100
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	return &Pool{
+		db:           db,
+		process:      process,
+		workers:      workers,
+		maxAttempts:  maxAttempts,
+		pollInterval: 5 * time.Second,
+		instance:     utils.InstanceID(),
+		lease:        time.Minute,
+		queue:        make(chan uint, queueSize),
+	}
+}
+
+// Start recovers jobs whose worker stopped without finishing them and launches the workers
+func (p *Pool) Start(ctx context.Context) error {
+	if err := p.recover(); err != nil {
+		return err
+	}
+
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work(ctx)
+	}
+
+	p.wg.Add(1)
+	go p.poll(ctx)
+
+	return nil
+}
+
+// Wait blocks until all workers have exited after ctx is cancelled
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+// Enqueue persists a new queued job and wakes up a worker
+func (p *Pool) Enqueue(job *models.SummaryJob) error {
+	job.Status = models.JobStatusQueued
+	job.Attempts = 0
+	job.MaxAttempts = p.maxAttempts
+
+	if err := p.db.Create(job).Error; err != nil {
+		return err
+	}
+
+	p.notify(job.ID)
+	return nil
+}
+
+// Depth counts the jobs waiting for a worker
+func (p *Pool) Depth() (int64, error) {
+	var count int64
+	err := p.db.Model(&models.SummaryJob{}).Where("status = ?", models.JobStatusQueued).Count(&count).Error
+	return count, err
+}
+
+// notify hands a job ID to the workers without blocking.
+// When the channel is full the poller will find the job later.
+func (p *Pool) notify(id uint) {
+	select {
+	case p.queue <- id:
+	default:
+	}
+}
+
+// recover puts running jobs whose lease ran out, because their server crashed
+// or was killed, back in the queue, or fails them if they are out of attempts.
+// Jobs other servers are still working on keep renewing their lease.
+func (p *Pool) recover() error {
+	now := time.Now()
+	expired := p.db.Where("heartbeat_at IS NULL OR heartbeat_at < ?", now.Add(-p.lease))
+
+	if err := p.db.Model(&models.SummaryJob{}).
+		Where("status = ? AND attempts >= max_attempts", models.JobStatusRunning).
+		Where(expired).
+		Updates(map[string]interface{}{
+			"status":      models.JobStatusFailed,
+			"error":       "job's server stopped while running it",
+			"finished_at": now,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to fail abandoned jobs: %w", err)
+	}
+
+	result := p.db.Model(&models.SummaryJob{}).
+		Where("status = ?", models.JobStatusRunning).
+		Where(expired).
+		Updates(map[string]interface{}{
+			"status":     models.JobStatusQueued,
+			"started_at": nil,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to requeue abandoned jobs: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		fmt.Printf("Requeued %d abandoned summary jobs\n", result.RowsAffected)
+	}
+
+	return nil
+}
+
+// poll periodically feeds queued jobs to the workers so nothing is lost
+// when the channel overflows or the job was created before a restart
+func (p *Pool) poll(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.recover(); err != nil {
+			fmt.Println(err)
+		}
+		p.feed()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Pool) feed() {
+	var ids []uint
+	if err := p.db.Model(&models.SummaryJob{}).
+		Where("status = ?", models.JobStatusQueued).
+		Order("id asc").
+		Limit(cap(p.queue)).
+		Pluck("id", &ids).Error; err != nil {
+		fmt.Printf("Failed to poll summary jobs: %v\n", err)
+		return
+	}
+
+	for _, id := range ids {
+		p.notify(id)
+	}
+}
+
+func (p *Pool) work(ctx context.Context) {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-p.queue:
+			p.run(ctx, id)
+		}
+	}
+}
+
+// run claims a job, processes it and records the outcome
+func (p *Pool) run(ctx context.Context, id uint) {
+	// Claim atomically so a job notified twice only runs once
+	now := time.Now()
+	result := p.db.Model(&models.SummaryJob{}).
+		Where("id = ? AND status = ?", id, models.JobStatusQueued).
+		Updates(map[string]interface{}{
+			"status":       models.JobStatusRunning,
+			"started_at":   now,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"locked_by":    p.instance,
+			"heartbeat_at": now,
+		})
+	if result.Error != nil {
+		fmt.Printf("Failed to claim summary job %d: %v\n", id, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	var job models.SummaryJob
+	if err := p.db.First(&job, id).Error; err != nil {
+		fmt.Printf("Failed to load summary job %d: %v\n", id, err)
+		return
+	}
+
+	stop := p.heartbeat(id)
+	err := p.process(ctx, &job)
+	stop()
+	now = time.Now()
+
+	if err == nil {
+		p.finish(id, map[string]interface{}{
+			"status":      models.JobStatusSucceeded,
+			"error":       "",
+			"summary_id":  job.SummaryID,
+			"cached":      job.Cached,
+			"finished_at": now,
+		})
+		return
+	}
+
+	// An open breaker means the provider was never called, so don't spend an attempt
+	var open *summarizer.CircuitOpenError
+	if errors.As(err, &open) {
+		p.finish(id, map[string]interface{}{
+			"status":   models.JobStatusQueued,
+			"attempts": gorm.Expr("attempts - 1"),
+			"error":    err.Error(),
+		})
+		return
+	}
+
+	fmt.Printf("Summary job %d attempt %d failed: %v\n", id, job.Attempts, err)
+
+	// Retried jobs are picked up again by the poller, which doubles as a short backoff.
+	// Jobs cut short by shutdown go back to the queue regardless of attempts left.
+	if job.Attempts < job.MaxAttempts || ctx.Err() != nil {
+		p.finish(id, map[string]interface{}{
+			"status": models.JobStatusQueued,
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	p.finish(id, map[string]interface{}{
+		"status":      models.JobStatusFailed,
+		"error":       err.Error(),
+		"finished_at": now,
+	})
+}
+
+// heartbeat renews the lease on a running job until the returned func is called
+func (p *Pool) heartbeat(id uint) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(p.lease / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := p.leased(id).Update("heartbeat_at", time.Now()).Error; err != nil {
+					fmt.Printf("Failed to renew lease on summary job %d: %v\n", id, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// finish records a job's outcome, unless the lease was lost and the job was handed to another worker
+func (p *Pool) finish(id uint, updates map[string]interface{}) {
+	result := p.leased(id).Updates(updates)
+	if result.Error != nil {
+		fmt.Printf("Failed to update summary job %d: %v\n", id, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		fmt.Printf("Summary job %d was taken back before it finished, discarding the outcome\n", id)
+	}
+}
+
+// leased selects a job while this instance holds its lease
+func (p *Pool) leased(id uint) *gorm.DB {
+	return p.db.Model(&models.SummaryJob{}).
+		Where("id = ? AND status = ? AND locked_by = ?", id, models.JobStatusRunning, p.instance)
+}
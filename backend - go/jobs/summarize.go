@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"backend-go/models"
+	"backend-go/storage"
+	"backend-go/summarizer"
+	"context"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+)
+
+// NewSummarizeProcessor returns a Processor that summarizes the job's PDF
+// with the given summarizer and stores the resulting summary. Unless the
+// job is forced, a cached summary of the same content is reused instead.
+func NewSummarizeProcessor(db *gorm.DB, blobs storage.BlobStore, s summarizer.Summarizer, cache *SummaryCache) Processor {
+	return func(ctx context.Context, job *models.SummaryJob) error {
+		var pdf models.PDF
+		if err := db.First(&pdf, job.PDFID).Error; err != nil {
+			return fmt.Errorf("failed to find PDF: %w", err)
+		}
+
+		opts := summarizer.Options{
+			Style:    job.Style,
+			Language: job.Language,
+		}
+		provider := ProviderFor(s, opts)
+
+		// An identical job may have finished while this one was queued
+		if !job.Force {
+			cached, err := cache.Lookup(pdf, job.Style, job.Language, provider)
+			if err != nil {
+				return err
+			}
+			if cached != nil {
+				job.SummaryID = &cached.ID
+				job.Cached = true
+				return nil
+			}
+		}
+
+		doc := summarizer.Document{
+			Filename: pdf.Filename,
+			Size:     pdf.FileSize,
+			Open: func() (io.ReadCloser, error) {
+				return blobs.Get(ctx, pdf.Filename)
+			},
+		}
+
+		result, err := s.Summarize(ctx, doc, opts)
+		if err != nil {
+			return err
+		}
+		if result.Provider != "" {
+			provider = result.Provider
+		}
+
+		summary := models.Summaries{
+			Style:         result.Style,
+			Content:       result.Summary,
+			PDFID:         pdf.ID,
+			PDFVersion:    pdf.Version,
+			Language:      result.Language,
+			SummaryTime:   result.ProcessingTime,
+			ContentHash:   pdf.ContentHash,
+			PromptVersion: cache.PromptVersion(),
+			Provider:      provider,
+			OwnerID:       pdf.OwnerID,
+			WorkspaceID:   pdf.WorkspaceID,
+
+			WordCount:        result.WordCount,
+			ReadingTime:      result.ReadingTime,
+			ChunksProcessed:  result.ChunksProcessed,
+			ChunkingUsed:     result.ChunkingUsed,
+			OriginalFilename: result.OriginalFilename,
+			SourceFileSize:   result.FileSize,
+			TextStatistics:   result.TextStats,
+		}
+
+		if err := db.Create(&summary).Error; err != nil {
+			return fmt.Errorf("failed to save summary: %w", err)
+		}
+
+		job.SummaryID = &summary.ID
+		return nil
+	}
+}
+
+// ProviderFor names the provider s will use for opts, resolving routes when
+// s is a router. Cached summaries are keyed on it.
+func ProviderFor(s summarizer.Summarizer, opts summarizer.Options) string {
+	if router, ok := s.(interface {
+		Select(summarizer.Options) summarizer.Summarizer
+	}); ok {
+		return router.Select(opts).Name()
+	}
+	return s.Name()
+}
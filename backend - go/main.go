@@ -1,26 +1,52 @@
 package main
 
 import (
+	"backend-go/audit"
+	"backend-go/auth"
+	"backend-go/batch"
+	"backend-go/collections"
+	"backend-go/download"
 	"backend-go/dto"
+	"backend-go/extract"
+	"backend-go/jobs"
+	"backend-go/metrics"
 	"backend-go/models"
+	"backend-go/ratelimit"
+	"backend-go/resumable"
+	"backend-go/revisions"
+	"backend-go/search"
+	"backend-go/shares"
+	"backend-go/storage"
+	"backend-go/summarizer"
+	"backend-go/tags"
+	"backend-go/trash"
 	"backend-go/utils"
-	"bytes"
+	"backend-go/versions"
+	"backend-go/workspaces"
+	"bufio"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/extemporalgenome/npdfpages"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/google/uuid"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func main() {
@@ -34,19 +60,191 @@ func main() {
 		panic("failed to connect database")
 	}
 
+	// Cancelled on SIGINT or SIGTERM to stop the background workers
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	blobs, err := storage.FromEnv(context.Background())
+	if err != nil {
+		panic("failed to initialize blob storage: " + err.Error())
+	}
+	contentStore := storage.NewContentStore(db, blobs)
+
+	summarizers, err := summarizer.FromEnv()
+	if err != nil {
+		panic("invalid summarizer configuration: " + err.Error())
+	}
+
+	limiter, err := ratelimit.FromEnv(context.Background())
+	if err != nil {
+		panic("invalid rate limit configuration: " + err.Error())
+	}
+
+	tokens, err := auth.TokensFromEnv()
+	if err != nil {
+		panic("invalid auth configuration: " + err.Error())
+	}
+	authService := auth.NewService(db, tokens, utils.GetEnv("AUTH_ADOPT_ORPHANS", "false") == "true")
+	apiKeys := auth.NewAPIKeys(db)
+
+	workspaceService := workspaces.NewService(db)
+
+	// scoped restricts queries to documents in the request's workspace
+	scoped := func(c *fiber.Ctx) *gorm.DB {
+		return db.Scopes(utils.InWorkspace(utils.CurrentWorkspaceID(c)))
+	}
+
+	summaryCache := jobs.NewSummaryCache(db, utils.GetEnv("SUMMARY_PROMPT_VERSION", "v1"))
+
+	// Summaries are generated in the background so long documents don't hold requests open
+	summaryJobs := jobs.NewPool(
+		db,
+		utils.GetEnvInt("SUMMARY_WORKERS", 2),
+		utils.GetEnvInt("SUMMARY_QUEUE_SIZE", 100),
+		utils.GetEnvInt("SUMMARY_MAX_ATTEMPTS", 3),
+		jobs.NewSummarizeProcessor(db, blobs, summarizers, summaryCache),
+	)
+	if err := summaryJobs.Start(ctx); err != nil {
+		panic("failed to start summary workers: " + err.Error())
+	}
+
+	// Page text is extracted after upload so search can see document content
+	extractor := extract.NewService(db, blobs, utils.GetEnvInt("EXTRACT_WORKERS", 2))
+	if err := extractor.Start(ctx); err != nil {
+		panic("failed to start text extraction: " + err.Error())
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic("failed to get database handle: " + err.Error())
+	}
+	metrics.RegisterDB(sqlDB, "postgres")
+	metrics.RegisterQueue("summary", summaryJobs.Depth)
+	metrics.RegisterQueue("extraction", extractor.Depth)
+
+	revisionService := revisions.NewService(db)
+	versionService := versions.NewService(db, contentStore)
+
+	// Deleted documents stay in the trash until the purger removes them for good
+	trashService := trash.NewService(
+		db,
+		contentStore,
+		utils.GetEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+		utils.GetEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+	)
+	trashService.Start(ctx)
+
+	// ZIP archives uploaded to /pdf/upload/batch may not expand beyond these
+	batchLimits := batch.Limits{
+		MaxFiles:     utils.GetEnvInt("BATCH_MAX_FILES", 500),
+		MaxTotalSize: int64(utils.GetEnvInt("BATCH_MAX_UNCOMPRESSED_MB", 2048)) << 20,
+		MaxRatio:     int64(utils.GetEnvInt("BATCH_MAX_COMPRESSION_RATIO", 100)),
+	}
+
+	// Resumable uploads keep their chunks until finished or abandoned for UPLOAD_EXPIRY
+	uploadService := resumable.NewService(
+		db,
+		blobs,
+		contentStore,
+		utils.GetEnvDuration("UPLOAD_EXPIRY", 24*time.Hour),
+		utils.GetEnvDuration("UPLOAD_PURGE_INTERVAL", time.Hour),
+	)
+	uploadService.Start(ctx)
+	maxUploadChunk := int64(utils.GetEnvInt("UPLOAD_MAX_CHUNK_MB", 32)) << 20
+
+	tagService := tags.NewService(db)
+	collectionService := collections.NewService(db, trashService)
+
+	shareSecret, err := shares.SecretFromEnv()
+	if err != nil {
+		panic("invalid share link configuration: " + err.Error())
+	}
+	shareService := shares.NewService(db, shareSecret)
+	shareDefaultTTL := utils.GetEnvDuration("SHARE_LINK_DEFAULT_TTL", 7*24*time.Hour)
+	shareMaxTTL := utils.GetEnvDuration("SHARE_LINK_MAX_TTL", 30*24*time.Hour)
+	// Public address of the /s/ routes when the API sits behind a proxy
+	shareBaseURL := strings.TrimSuffix(utils.GetEnv("SHARE_LINK_BASE_URL", ""), "/")
+
+	auditService := audit.NewService(db)
+
+	// recordAudit appends event to the audit log, filling in the caller, their IP,
+	// the request ID and, unless the event names one, the current workspace. The
+	// change has already been made, but a change missing from the log must not be
+	// reported as a success, so handlers return the error when it can't be recorded.
+	recordAudit := func(c *fiber.Ctx, event models.AuditEvent) error {
+		if principal := utils.CurrentPrincipal(c); principal != nil {
+			if event.ActorID == nil {
+				event.ActorID = &principal.UserID
+			}
+			if principal.APIKeyID != 0 {
+				event.APIKeyID = &principal.APIKeyID
+			}
+		}
+		if workspaceID := utils.CurrentWorkspaceID(c); event.WorkspaceID == nil && workspaceID != 0 {
+			event.WorkspaceID = &workspaceID
+		}
+		event.IP = c.IP()
+		event.RequestID = c.GetRespHeader(fiber.HeaderXRequestID)
+		// Clients may send their own request IDs, which could be any length
+		if len(event.RequestID) > 64 {
+			event.RequestID = event.RequestID[:64]
+		}
+
+		if err := auditService.Record(&event); err != nil {
+			fmt.Printf("Failed to record %s of %s %s: %v\n", event.Action, event.TargetType, event.TargetID, err)
+			return fiber.NewError(fiber.StatusInternalServerError, "The change was saved but could not be recorded in the audit log")
+		}
+		return nil
+	}
+
+	// Bodies up to bodyLimit are read before the handler runs, larger ones are
+	// streamed so that file routes can accept more than the other routes
+	bodyLimit := utils.GetEnvInt("BODY_LIMIT_MB", 4) << 20
+	uploadBodyLimit := utils.MaxFileSize + 1<<20 // one PDF plus the other form fields
+	batchBodyLimit := utils.GetEnvInt("BATCH_BODY_LIMIT_MB", 256) << 20
+
 	app := fiber.New(fiber.Config{
-		ErrorHandler: utils.ErrorHandler,
+		ErrorHandler:                 utils.ErrorHandler,
+		BodyLimit:                    bodyLimit,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
 	})
 
 	// Apply middleware
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     "http://localhost:3000",
-		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
+		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-API-Key,X-Workspace-ID,Tus-Resumable,Upload-Length,Upload-Offset,Upload-Metadata,X-Share-Password",
+		ExposeHeaders:    "RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,RateLimit-Policy,Retry-After,Location,Tus-Resumable,Tus-Version,Upload-Offset,Upload-Length,Upload-Expires,Content-Disposition,Content-Range,Accept-Ranges,ETag,X-Request-ID",
 		AllowCredentials: true,
 	}))
+	// Every response carries an X-Request-ID, kept from the request when the client sent one
+	app.Use(requestid.New())
+	app.Use(metrics.Middleware())
 	app.Use(utils.LoggingMiddleware())
-	app.Use(utils.RateLimitMiddleware())
+	app.Use(limiter.ByIP("/ping", "/health", "/metrics"))
+
+	// Document routes are also served under "/workspaces/:workspace_id/..."
+	workspaceRoutes := []string{"/pdf", "/summaries", "/search", "/jobs", "/trash", "/tags", "/collections", "/uploads", "/shares", "/audit"}
+	app.Use(utils.WorkspacePathMiddleware("pdf", "summaries", "search", "jobs", "trash", "tags", "collections", "uploads", "shares", "audit"))
+
+	// Routes taking files set their own, larger body limits
+	app.Use(utils.BodyLimit(bodyLimit, func(c *fiber.Ctx) bool {
+		path := c.Path()
+		switch c.Method() {
+		case fiber.MethodPost:
+			return path == "/pdf/upload" || path == "/pdf/upload/batch"
+		case fiber.MethodPut:
+			return strings.HasPrefix(path, "/pdf/") && strings.HasSuffix(path, "/file")
+		case fiber.MethodPatch:
+			return strings.HasPrefix(path, "/uploads/")
+		}
+		return false
+	}))
+
+	// Prometheus scrape endpoint, protected by METRICS_TOKEN. Without a token it is
+	// disabled unless METRICS_PUBLIC=true opens it to anyone who can reach the API.
+	app.Get("/metrics", metrics.Handler(utils.GetEnv("METRICS_TOKEN", ""), utils.GetEnv("METRICS_PUBLIC", "false") == "true"))
 
 	app.Get("/ping", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -79,302 +277,580 @@ func main() {
 		db.Model(&models.PDF{}).Count(&pdfCount)
 		db.Model(&models.Summaries{}).Count(&summaryCount)
 
+		// Report open summarizer breakers as degraded rather than unhealthy: uploads still work
+		status := "healthy"
+		breakers := summarizers.Status()
+		for _, breaker := range breakers {
+			if breaker.State != summarizer.BreakerClosed {
+				status = "degraded"
+			}
+		}
+
 		return c.JSON(fiber.Map{
-			"status":          "healthy",
+			"status":          status,
 			"database":        "connected",
+			"summarizers":     breakers,
 			"total_pdfs":      pdfCount,
 			"total_summaries": summaryCount,
 			"version":         "1.0.0",
 		})
 	})
 
-	app.Get("/pdf", func(c *fiber.Ctx) error {
-		var pdfs []models.PDF
-
-		// Pagination parameters with validation
-		page := c.QueryInt("page", 1)
-		itemsPerPage := c.QueryInt("itemsperpage", 10)
-
-		if page < 1 {
-			page = 1
+	tokenResponse := func(session *auth.Session) dto.TokenResponse {
+		return dto.TokenResponse{
+			TokenType:        "Bearer",
+			AccessToken:      session.AccessToken,
+			ExpiresAt:        session.AccessExpiresAt,
+			RefreshToken:     session.RefreshToken,
+			RefreshExpiresAt: session.RefreshExpiresAt,
+			User:             utils.ConvertUserToResponse(session.User),
 		}
-		if itemsPerPage < 1 || itemsPerPage > 100 {
-			itemsPerPage = 10
-		}
-
-		// Calculate offset from page and itemsPerPage
-		offset := (page - 1) * itemsPerPage
-		limit := itemsPerPage
-
-		// Other query parameters
-		sortBy := c.Query("sort", "created_at")
-		order := c.Query("order", "desc")
-		search := c.Query("search", "")
+	}
 
-		// Validate sort parameters
-		validSortFields := map[string]bool{
-			"created_at": true,
-			"updated_at": true,
-			"title":      true,
-			"file_size":  true,
-			"page_count": true,
-		}
-		if !validSortFields[sortBy] {
-			sortBy = "created_at"
-		}
-		if order != "asc" && order != "desc" {
-			order = "desc"
+	app.Post("/auth/register", func(c *fiber.Ctx) error {
+		if utils.GetEnv("AUTH_ALLOW_REGISTRATION", "true") != "true" {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "registration_disabled",
+				"message": "Registration is disabled",
+			})
 		}
 
-		query := db.Model(&models.PDF{})
-
-		if search != "" {
-			query = query.Where("title ILIKE ? OR filename ILIKE ?", "%"+search+"%", "%"+search+"%")
+		var req dto.RegisterRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
 		}
 
-		// Get total count for pagination
-		var totalCount int64
-		if err := query.Count(&totalCount).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error":   "database_error",
-				"message": "Failed to count PDFs",
-				"details": err.Error(),
+		if err := utils.ValidateEmail(req.Email); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_email",
+				"message": err.Error(),
 			})
 		}
 
-		// Calculate total pages
-		totalPages := int((totalCount + int64(itemsPerPage) - 1) / int64(itemsPerPage))
+		if err := utils.ValidatePassword(req.Password); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_password",
+				"message": err.Error(),
+			})
+		}
 
-		if err := query.Preload("Summaries").Order(fmt.Sprintf("%s %s", sortBy, order)).Limit(limit).Offset(offset).Find(&pdfs).Error; err != nil {
+		user, err := authService.Register(req.Email, req.Name, req.Password)
+		if err != nil {
+			if err == auth.ErrEmailTaken {
+				return c.Status(409).JSON(fiber.Map{
+					"error":   "email_taken",
+					"message": err.Error(),
+				})
+			}
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to fetch PDFs",
+				"message": "Failed to register user",
 				"details": err.Error(),
 			})
 		}
 
-		response := dto.PDFListResponse{
-			Data:         utils.ConvertPDFsToResponse(pdfs),
-			Page:         page,
-			ItemsPerPage: itemsPerPage,
-			TotalPages:   totalPages,
-			TotalItems:   totalCount,
+		response := utils.ConvertUserToResponse(*user)
+		if err := recordAudit(c, models.AuditEvent{
+			ActorID:    &user.ID,
+			Action:     audit.ActionUserRegister,
+			TargetType: audit.TargetUser,
+			TargetID:   fmt.Sprint(user.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
 		}
 
-		return c.Status(200).JSON(response)
+		return c.Status(201).JSON(response)
 	})
 
-	app.Get("/pdf/count", func(c *fiber.Ctx) error {
-		var count int64
-
-		if err := db.Model(&models.PDF{}).Count(&count).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"message": "Failed to count PDFs: " + err.Error(),
+	app.Post("/auth/login", func(c *fiber.Ctx) error {
+		var req dto.LoginRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
 			})
 		}
 
-		response := dto.PDFCountResponse{
-			Count: count,
+		session, err := authService.Login(req.Email, req.Password)
+		if err != nil {
+			if err == auth.ErrInvalidCredentials {
+				return c.Status(401).JSON(fiber.Map{
+					"error":   "invalid_credentials",
+					"message": err.Error(),
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "server_error",
+				"message": "Failed to log in",
+				"details": err.Error(),
+			})
 		}
 
-		return c.Status(200).JSON(response)
+		return c.Status(200).JSON(tokenResponse(session))
 	})
 
-	app.Post("/pdf", func(c *fiber.Ctx) error {
-		var req dto.PDFCreateRequest
-
-		if err := c.BodyParser(&req); err != nil {
+	app.Post("/auth/refresh", func(c *fiber.Ctx) error {
+		var req dto.RefreshRequest
+		if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
 			return c.Status(400).JSON(fiber.Map{
-				"message": "Invalid request body: " + err.Error(),
+				"error":   "invalid_request",
+				"message": "refresh_token is required",
 			})
 		}
 
-		// Convert DTO to model
-		pdf := models.PDF{
-			Filename:  req.Filename,
-			FileSize:  req.FileSize,
-			Title:     req.Title,
-			PageCount: req.PageCount,
-		}
-
-		if err := db.Create(&pdf).Error; err != nil {
+		session, err := authService.Refresh(req.RefreshToken)
+		if err != nil {
+			if err == auth.ErrInvalidToken {
+				return c.Status(401).JSON(fiber.Map{
+					"error":   "invalid_token",
+					"message": err.Error(),
+				})
+			}
 			return c.Status(500).JSON(fiber.Map{
-				"message": "Failed to create PDF record: " + err.Error(),
+				"error":   "server_error",
+				"message": "Failed to refresh session",
+				"details": err.Error(),
 			})
 		}
 
-		// Convert model to response DTO
-		response := utils.ConvertPDFToResponse(pdf)
-		return c.Status(201).JSON(response)
+		return c.Status(200).JSON(tokenResponse(session))
 	})
 
-	app.Get("/pdf/:id", func(c *fiber.Ctx) error {
-		var pdf models.PDF
+	app.Post("/auth/logout", func(c *fiber.Ctx) error {
+		var req dto.RefreshRequest
+		if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "refresh_token is required",
+			})
+		}
 
-		if err := db.Preload("Summaries").First(&pdf, c.Params("id")).Error; err != nil {
-			return c.Status(404).JSON(fiber.Map{
-				"message": "PDF not found",
+		if err := authService.Logout(req.RefreshToken); err != nil {
+			if err == auth.ErrInvalidToken {
+				return c.Status(401).JSON(fiber.Map{
+					"error":   "invalid_token",
+					"message": err.Error(),
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "server_error",
+				"message": "Failed to log out",
+				"details": err.Error(),
 			})
 		}
 
-		response := utils.ConvertPDFToResponse(pdf)
-		return c.Status(200).JSON(response)
+		return c.Status(200).JSON(fiber.Map{
+			"message": "Logged out successfully",
+		})
 	})
 
-	app.Get("/pdf/:id/download", func(c *fiber.Ctx) error {
-		var pdf models.PDF
+	// sendPDFFile serves a stored PDF file named after title, as an attachment or
+	// with ?inline=true for viewing in the browser. The content hash is the ETag,
+	// so unchanged files get 304, and Range requests get just the bytes asked for.
+	// When record is set it is called before sending a GET response that includes
+	// the file's first byte, and the file isn't sent if it fails.
+	sendPDFFile := func(c *fiber.Ctx, filename, hash string, modified time.Time, title string, record func() error) error {
+		etag := download.ETag(hash)
+		if etag != "" {
+			c.Set("ETag", etag)
+		}
+		if !modified.IsZero() {
+			c.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+		}
+		// Private files may be cached but must be revalidated with the ETag
+		c.Set("Cache-Control", "private, no-cache")
+		c.Set("Accept-Ranges", "bytes")
 
-		if err := db.First(&pdf, c.Params("id")).Error; err != nil {
-			return c.Status(404).JSON(fiber.Map{
-				"error":   "not_found",
-				"message": "PDF not found",
+		if download.NotModified(c.Get("If-None-Match"), c.Get("If-Modified-Since"), etag, modified) {
+			return c.SendStatus(304)
+		}
+
+		object, err := blobs.Get(c.UserContext(), filename)
+		if err != nil {
+			if err == storage.ErrNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "file_not_found",
+					"message": "PDF file not found on server",
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "storage_error",
+				"message": "Failed to open PDF file",
+				"details": err.Error(),
 			})
 		}
 
-		filePath := filepath.Join("uploads", pdf.Filename)
+		size, err := object.Seek(0, io.SeekEnd)
+		if err == nil {
+			_, err = object.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			object.Close()
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "storage_error",
+				"message": "Failed to read PDF file",
+				"details": err.Error(),
+			})
+		}
 
-		// Check if file exists
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			return c.Status(404).JSON(fiber.Map{
-				"error":   "file_not_found",
-				"message": "PDF file not found on server",
+		ranges, err := download.Ranges(c.Get("Range"), c.Get("If-Range"), etag, modified, size)
+		if err == download.ErrUnsatisfiable {
+			object.Close()
+			c.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			return c.Status(416).JSON(fiber.Map{
+				"error":   "range_not_satisfiable",
+				"message": fmt.Sprintf("The requested range is outside the file's %d bytes", size),
 			})
 		}
 
-		// Set appropriate headers for file download
-		c.Set("Content-Type", "application/pdf")
-		c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", pdf.Title+".pdf"))
+		if record != nil && c.Method() != fiber.MethodHead && download.IncludesStart(ranges) {
+			if err := record(); err != nil {
+				object.Close()
+				return err
+			}
+		}
 
-		return c.SendFile(filePath)
-	})
+		disposition := "attachment"
+		if c.QueryBool("inline", false) {
+			disposition = "inline"
+		}
+		c.Set("Content-Disposition", download.ContentDisposition(disposition, title+".pdf"))
 
-	app.Delete("/pdf/:id", func(c *fiber.Ctx) error {
-		id := c.Params("id")
+		// The stream is closed by fasthttp once the body has been sent
+		switch len(ranges) {
+		case 0:
+			c.Set("Content-Type", "application/pdf")
+			return c.SendStream(object, int(size))
+		case 1:
+			c.Set("Content-Type", "application/pdf")
+			c.Set("Content-Range", ranges[0].ContentRange(size))
+			body := io.NewSectionReader(object, ranges[0].Start, ranges[0].Length)
+			return c.Status(206).SendStream(download.ReadCloser(body, object), int(ranges[0].Length))
+		default:
+			body, length, boundary := download.Multipart(object, ranges, size, "application/pdf")
+			c.Set("Content-Type", "multipart/byteranges; boundary="+boundary)
+			return c.Status(206).SendStream(download.ReadCloser(body, object), int(length))
+		}
+	}
 
-		var pdf models.PDF
+	// shareOpenError responds to a share link that can't be opened. Browsers
+	// get a password form when one is needed.
+	shareOpenError := func(c *fiber.Ctx, err error) error {
+		switch err {
+		case shares.ErrPasswordRequired, shares.ErrWrongPassword:
+			if c.Accepts("application/json", "text/html") == "text/html" {
+				c.Status(401).Type("html", "utf-8")
+				return shares.RenderPasswordForm(c, err == shares.ErrWrongPassword)
+			}
+			if err == shares.ErrWrongPassword {
+				return c.Status(401).JSON(fiber.Map{
+					"error":   "invalid_password",
+					"message": "The password is not correct",
+				})
+			}
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "password_required",
+				"message": "This link needs a password, send it in the X-Share-Password header",
+			})
+		case shares.ErrInvalidToken, shares.ErrTargetNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Share link not found",
+			})
+		case shares.ErrExpired:
+			return c.Status(410).JSON(fiber.Map{
+				"error":   "link_expired",
+				"message": "This link has expired",
+			})
+		case shares.ErrRevoked:
+			return c.Status(410).JSON(fiber.Map{
+				"error":   "link_revoked",
+				"message": "This link has been revoked",
+			})
+		case shares.ErrExhausted:
+			return c.Status(410).JSON(fiber.Map{
+				"error":   "download_limit_reached",
+				"message": "This link has reached its download limit",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "server_error",
+			"message": "Failed to open share link",
+			"details": err.Error(),
+		})
+	}
 
-		db.First(&pdf, id)
+	// Opens a share link without signing in: the PDF, or the summary as a web
+	// page (format=json for JSON). A password comes in the X-Share-Password
+	// header or as the "password" field posted by the password form.
+	openShare := func(c *fiber.Ctx) error {
+		// Keep the token out of Referer headers and search engines
+		c.Set("Referrer-Policy", "no-referrer")
+		c.Set("X-Robots-Tag", "noindex")
 
-		if pdf.ID == 0 {
-			return c.Status(404).JSON(fiber.Map{
-				"message": "PDF not found",
+		password := c.Get("X-Share-Password")
+		if password == "" && c.Method() == fiber.MethodPost {
+			password = c.FormValue("password")
+		}
+
+		link, err := shareService.Open(c.Params("token"), password)
+		if err != nil {
+			return shareOpenError(c, err)
+		}
+
+		if pdf := link.PDF; pdf != nil {
+			// A download is counted once, not for revalidations or the later parts of a ranged download
+			var recordErr error
+			err := sendPDFFile(c, pdf.Filename, pdf.ContentHash, pdf.UpdatedAt, pdf.Title, func() error {
+				recordErr = shareService.Record(link)
+				return recordErr
 			})
+			if recordErr != nil {
+				return shareOpenError(c, recordErr)
+			}
+			return err
 		}
 
-		filePath := filepath.Join("uploads", pdf.Filename)
-		if _, err := os.Stat(filePath); err == nil {
-			if err := os.Remove(filePath); err != nil {
-				return c.Status(500).JSON(fiber.Map{
-					"message": "Failed to delete file",
-				})
+		if c.Method() != fiber.MethodHead {
+			if err := shareService.Record(link); err != nil {
+				return shareOpenError(c, err)
 			}
-		} else if !os.IsNotExist(err) {
-			return c.Status(500).JSON(fiber.Map{
-				"message": "Failed to check file existence",
+		}
+
+		summary := *link.Summary
+		c.Set("Cache-Control", "no-store")
+		if c.Query("format") == "json" {
+			return c.Status(200).JSON(dto.SharedSummaryResponse{
+				Title:     summary.PDF.Title,
+				Style:     summary.Style,
+				Language:  summary.Language,
+				Content:   summary.Content,
+				CreatedAt: summary.CreatedAt,
 			})
 		}
+		c.Type("html", "utf-8")
+		return shares.RenderSummary(c, summary)
+	}
+	app.Get("/s/:token", openShare)
+	app.Post("/s/:token", openShare)
 
-		db.Unscoped().Delete(&pdf)
+	// Every route registered below requires an access token or API key
+	app.Use(utils.AuthMiddleware(tokens, apiKeys))
+	app.Use(limiter.ByPrincipal())
+	app.Use(workspaceRoutes, utils.WorkspaceMiddleware(workspaceService))
 
-		return c.Status(200).JSON(fiber.Map{
-			"message": "PDF deleted successfully",
-		})
+	app.Get("/auth/me", func(c *fiber.Ctx) error {
+		var user models.User
+
+		if err := db.First(&user, utils.CurrentUserID(c)).Error; err != nil {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "unauthorized",
+				"message": "User no longer exists",
+			})
+		}
+
+		return c.Status(200).JSON(utils.ConvertUserToResponse(user))
 	})
 
-	app.Post("/pdf/upload", func(c *fiber.Ctx) error {
-		file, err := c.FormFile("file")
-		if err != nil {
+	app.Post("/api-keys", utils.RequireScope(auth.ScopeAdmin), func(c *fiber.Ctx) error {
+		var req dto.CreateAPIKeyRequest
+		if err := c.BodyParser(&req); err != nil {
 			return c.Status(400).JSON(fiber.Map{
 				"error":   "invalid_request",
-				"message": "File is required",
+				"message": "Invalid request body",
+				"details": err.Error(),
 			})
 		}
 
-		// Validate file extension
-		if err := utils.ValidateFileExtension(file.Filename); err != nil {
+		if strings.TrimSpace(req.Name) == "" {
 			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_file",
-				"message": err.Error(),
+				"error":   "invalid_name",
+				"message": "Name is required",
 			})
 		}
 
-		// Validate file size
-		if err := utils.ValidateFileSize(file.Size); err != nil {
+		if len(req.Scopes) == 0 {
 			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_file",
-				"message": err.Error(),
+				"error":   "invalid_scopes",
+				"message": "At least one scope is required",
+				"details": "Valid scopes: " + strings.Join(auth.Scopes, ", "),
 			})
 		}
 
-		// Get title from form data, fallback to filename without extension
-		title := c.FormValue("title")
-		if title == "" {
-			ext := filepath.Ext(file.Filename)
-			title = strings.TrimSuffix(file.Filename, ext)
+		// A key can't grant more than the caller holds
+		principal := utils.CurrentPrincipal(c)
+		for _, scope := range req.Scopes {
+			if !auth.ValidScope(scope) {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_scopes",
+					"message": "Unknown scope: " + scope,
+					"details": "Valid scopes: " + strings.Join(auth.Scopes, ", "),
+				})
+			}
+			if !principal.HasScope(scope) {
+				return c.Status(403).JSON(fiber.Map{
+					"error":   "insufficient_scope",
+					"message": "Cannot grant a scope you don't hold: " + scope,
+				})
+			}
 		}
 
-		// Validate title
-		if err := utils.ValidateTitle(title); err != nil {
+		if req.RateLimit < 0 {
 			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_title",
-				"message": err.Error(),
+				"error":   "invalid_rate_limit",
+				"message": "rate_limit must be 0 (default) or a positive number of requests per minute",
 			})
 		}
 
-		if err := os.MkdirAll("./uploads", os.ModePerm); err != nil {
+		if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_expires_at",
+				"message": "expires_at must be in the future",
+			})
+		}
+
+		key, record, err := apiKeys.Create(principal.UserID, req.Name, req.Scopes, req.RateLimit, req.ExpiresAt)
+		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
-				"error":   "server_error",
-				"message": "Failed to create upload directory",
+				"error":   "database_error",
+				"message": "Failed to create API key",
 				"details": err.Error(),
 			})
 		}
 
-		ext := filepath.Ext(file.Filename)
-		filename := uuid.New().String() + ext
+		response := utils.ConvertAPIKeyToResponse(*record)
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionAPIKeyCreate,
+			TargetType: audit.TargetAPIKey,
+			TargetID:   fmt.Sprint(record.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(201).JSON(dto.APIKeyCreatedResponse{
+			APIKeyResponse: response,
+			Key:            key,
+		})
+	})
 
-		if err := c.SaveFile(file, "./uploads/"+filename); err != nil {
+	app.Get("/api-keys", utils.RequireScope(auth.ScopeAdmin), func(c *fiber.Ctx) error {
+		keys, err := apiKeys.List(utils.CurrentUserID(c))
+		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
-				"error":   "server_error",
-				"message": "Failed to save file",
+				"error":   "database_error",
+				"message": "Failed to fetch API keys",
 				"details": err.Error(),
 			})
 		}
 
-		// Get page count
-		pageCount := npdfpages.PagesAtPath(filepath.Join("uploads", filename))
-		if pageCount <= 0 {
-			// Clean up the uploaded file if it's invalid
-			os.Remove(filepath.Join("uploads", filename))
-			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_file",
-				"message": "Invalid PDF file or unable to read page count",
-			})
+		response := make([]dto.APIKeyResponse, len(keys))
+		for i, key := range keys {
+			response[i] = utils.ConvertAPIKeyToResponse(key)
 		}
 
-		pdf := models.PDF{
-			Filename:  filename,
-			FileSize:  file.Size,
-			Title:     title,
-			PageCount: pageCount,
+		return c.Status(200).JSON(fiber.Map{
+			"data": response,
+		})
+	})
+
+	app.Delete("/api-keys/:id", utils.RequireScope(auth.ScopeAdmin), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "API key ID must be a number",
+			})
 		}
 
-		if err := db.Create(&pdf).Error; err != nil {
-			// Clean up the uploaded file if database save fails
-			os.Remove(filepath.Join("uploads", filename))
+		key, err := apiKeys.Revoke(utils.CurrentUserID(c), uint(id))
+		if err != nil {
+			if err == auth.ErrAPIKeyNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "API key not found",
+				})
+			}
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to create PDF record",
+				"message": "Failed to revoke API key",
 				"details": err.Error(),
 			})
 		}
 
-		response := utils.ConvertPDFToResponse(pdf)
-		return c.Status(201).JSON(response)
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionAPIKeyRevoke,
+			TargetType: audit.TargetAPIKey,
+			TargetID:   fmt.Sprint(key.ID),
+			Before:     audit.Snapshot(utils.ConvertAPIKeyToResponse(*key)),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"message": "API key revoked successfully",
+			"id":      key.ID,
+		})
 	})
 
-	app.Post("/pdf/:id/summarize", func(c *fiber.Ctx) error {
-		var req dto.SummarizeRequest
+	// workspaceMembership loads the caller's membership in the ":id" workspace,
+	// responding with 404 when there is none
+	workspaceMembership := func(c *fiber.Ctx) (*models.Membership, error) {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return nil, c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Workspace ID must be a number",
+			})
+		}
+
+		membership, err := workspaceService.Membership(uint(id), utils.CurrentUserID(c))
+		if err != nil {
+			if err == workspaces.ErrNotFound {
+				return nil, c.Status(404).JSON(fiber.Map{
+					"error":   "workspace_not_found",
+					"message": "Workspace not found or you are not a member",
+				})
+			}
+			return nil, c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch workspace",
+				"details": err.Error(),
+			})
+		}
+
+		return membership, nil
+	}
+
+	// workspaceMemberError maps membership management errors to responses
+	workspaceMemberError := func(c *fiber.Ctx, err error) error {
+		switch err {
+		case workspaces.ErrUserNotFound, workspaces.ErrMemberNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": err.Error(),
+			})
+		case workspaces.ErrAlreadyMember, workspaces.ErrLastOwner:
+			return c.Status(409).JSON(fiber.Map{
+				"error":   "conflict",
+				"message": err.Error(),
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "database_error",
+			"message": "Failed to update workspace members",
+			"details": err.Error(),
+		})
+	}
 
+	app.Post("/workspaces", utils.RequireScope(auth.ScopeAdmin), func(c *fiber.Ctx) error {
+		var req dto.CreateWorkspaceRequest
 		if err := c.BodyParser(&req); err != nil {
 			return c.Status(400).JSON(fiber.Map{
 				"error":   "invalid_request",
@@ -383,126 +859,2752 @@ func main() {
 			})
 		}
 
-		// Validate style and language
-		if err := utils.ValidateSummaryStyle(req.Style); err != nil {
+		if strings.TrimSpace(req.Name) == "" {
 			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_style",
-				"message": err.Error(),
+				"error":   "invalid_name",
+				"message": "Name is required",
 			})
 		}
 
-		if err := utils.ValidateLanguage(req.Language); err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error":   "invalid_language",
-				"message": err.Error(),
+		workspace, err := workspaceService.Create(utils.CurrentUserID(c), req.Name)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to create workspace",
+				"details": err.Error(),
 			})
 		}
 
-		id := c.Params("id")
-		var pdf models.PDF
+		response := utils.ConvertMembershipToWorkspaceResponse(models.Membership{
+			Workspace: *workspace,
+			Role:      models.RoleOwner,
+		})
+		if err := recordAudit(c, models.AuditEvent{
+			WorkspaceID: &workspace.ID,
+			Action:      audit.ActionWorkspaceCreate,
+			TargetType:  audit.TargetWorkspace,
+			TargetID:    fmt.Sprint(workspace.ID),
+			After:       audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
 
-		if err := db.First(&pdf, id).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return c.Status(404).JSON(fiber.Map{
-					"error":   "not_found",
-					"message": "PDF not found",
-				})
-			}
+		return c.Status(201).JSON(response)
+	})
+
+	app.Get("/workspaces", func(c *fiber.Ctx) error {
+		memberships, err := workspaceService.List(utils.CurrentUserID(c))
+		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to find PDF",
+				"message": "Failed to fetch workspaces",
 				"details": err.Error(),
 			})
 		}
 
-		filePath := filepath.Join("uploads", pdf.Filename)
-		file, err := os.Open(filePath)
+		response := make([]dto.WorkspaceResponse, len(memberships))
+		for i, membership := range memberships {
+			response[i] = utils.ConvertMembershipToWorkspaceResponse(membership)
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"data": response,
+		})
+	})
+
+	app.Get("/workspaces/:id", func(c *fiber.Ctx) error {
+		membership, err := workspaceMembership(c)
+		if membership == nil {
+			return err
+		}
+
+		return c.Status(200).JSON(utils.ConvertMembershipToWorkspaceResponse(*membership))
+	})
+
+	app.Get("/workspaces/:id/members", func(c *fiber.Ctx) error {
+		membership, err := workspaceMembership(c)
+		if membership == nil {
+			return err
+		}
+
+		members, err := workspaceService.Members(membership.WorkspaceID)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
-				"error":   "file_error",
-				"message": "Failed to open PDF file",
+				"error":   "database_error",
+				"message": "Failed to fetch workspace members",
+				"details": err.Error(),
+			})
+		}
+
+		response := make([]dto.MemberResponse, len(members))
+		for i, member := range members {
+			response[i] = utils.ConvertMembershipToMemberResponse(member)
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"data": response,
+		})
+	})
+
+	app.Post("/workspaces/:id/members", utils.RequireScope(auth.ScopeAdmin), func(c *fiber.Ctx) error {
+		membership, err := workspaceMembership(c)
+		if membership == nil {
+			return err
+		}
+
+		if membership.Role != models.RoleOwner {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "forbidden",
+				"message": "Only workspace owners can add members",
+			})
+		}
+
+		var req dto.AddMemberRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
 				"details": err.Error(),
 			})
 		}
-		defer file.Close()
 
-		body := &bytes.Buffer{}
-		writer := multipart.NewWriter(body)
+		if !models.ValidRole(req.Role) {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_role",
+				"message": "Role must be one of: owner, editor, viewer",
+			})
+		}
 
-		filePart, err := writer.CreateFormFile("file", pdf.Filename)
+		member, err := workspaceService.AddMember(membership.WorkspaceID, req.Email, req.Role)
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error":   "server_error",
-				"message": "Failed to create form file",
+			return workspaceMemberError(c, err)
+		}
+
+		response := utils.ConvertMembershipToMemberResponse(*member)
+		if err := recordAudit(c, models.AuditEvent{
+			WorkspaceID: &membership.WorkspaceID,
+			Action:      audit.ActionMemberAdd,
+			TargetType:  audit.TargetMember,
+			TargetID:    fmt.Sprint(member.UserID),
+			After:       audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(201).JSON(response)
+	})
+
+	app.Patch("/workspaces/:id/members/:user_id", utils.RequireScope(auth.ScopeAdmin), func(c *fiber.Ctx) error {
+		membership, err := workspaceMembership(c)
+		if membership == nil {
+			return err
+		}
+
+		if membership.Role != models.RoleOwner {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "forbidden",
+				"message": "Only workspace owners can change roles",
+			})
+		}
+
+		userID, err := strconv.ParseUint(c.Params("user_id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "User ID must be a number",
+			})
+		}
+
+		var req dto.UpdateMemberRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
 				"details": err.Error(),
 			})
 		}
-		io.Copy(filePart, file)
 
-		writer.WriteField("style", req.Style)
-		writer.WriteField("language", req.Language)
-		writer.Close()
+		if !models.ValidRole(req.Role) {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_role",
+				"message": "Role must be one of: owner, editor, viewer",
+			})
+		}
+
+		// The previous role, for the audit log
+		before, err := workspaceService.Membership(membership.WorkspaceID, uint(userID))
+		if err != nil {
+			return workspaceMemberError(c, err)
+		}
+
+		member, err := workspaceService.UpdateRole(membership.WorkspaceID, uint(userID), req.Role)
+		if err != nil {
+			return workspaceMemberError(c, err)
+		}
+
+		response := utils.ConvertMembershipToMemberResponse(*member)
+		if err := recordAudit(c, models.AuditEvent{
+			WorkspaceID: &membership.WorkspaceID,
+			Action:      audit.ActionMemberUpdate,
+			TargetType:  audit.TargetMember,
+			TargetID:    fmt.Sprint(member.UserID),
+			Before:      audit.Snapshot(fiber.Map{"user_id": before.UserID, "role": before.Role}),
+			After:       audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(response)
+	})
 
-		pythonAPIURL := os.Getenv("PYTHON_API_URL")
-		if pythonAPIURL == "" {
-			pythonAPIURL = "127.0.0.1:8000"
+	app.Delete("/workspaces/:id/members/:user_id", utils.RequireScope(auth.ScopeAdmin), func(c *fiber.Ctx) error {
+		membership, err := workspaceMembership(c)
+		if membership == nil {
+			return err
+		}
+
+		userID, err := strconv.ParseUint(c.Params("user_id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "User ID must be a number",
+			})
 		}
 
-		httpReq, _ := http.NewRequest(
-			"POST",
-			fmt.Sprintf("http://%s/summarize", pythonAPIURL),
-			body,
-		)
-		httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+		// Members may leave on their own, only owners can remove others
+		if membership.Role != models.RoleOwner && uint(userID) != membership.UserID {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "forbidden",
+				"message": "Only workspace owners can remove other members",
+			})
+		}
 
-		client := &http.Client{}
-		resp, err := client.Do(httpReq)
+		before, err := workspaceService.Membership(membership.WorkspaceID, uint(userID))
 		if err != nil {
+			return workspaceMemberError(c, err)
+		}
+
+		if err := workspaceService.RemoveMember(membership.WorkspaceID, uint(userID)); err != nil {
+			return workspaceMemberError(c, err)
+		}
+
+		if err := recordAudit(c, models.AuditEvent{
+			WorkspaceID: &membership.WorkspaceID,
+			Action:      audit.ActionMemberRemove,
+			TargetType:  audit.TargetMember,
+			TargetID:    fmt.Sprint(userID),
+			Before:      audit.Snapshot(fiber.Map{"user_id": before.UserID, "role": before.Role}),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"message": "Member removed successfully",
+		})
+	})
+
+	// listPDFs responds with a page of the PDFs matched by query, applying the
+	// request's search, tag filter, sort and pagination parameters
+	listPDFs := func(c *fiber.Ctx, query *gorm.DB) error {
+		var pdfs []models.PDF
+
+		// Pagination parameters with validation
+		page := c.QueryInt("page", 1)
+		itemsPerPage := c.QueryInt("itemsperpage", 10)
+
+		if page < 1 {
+			page = 1
+		}
+		if itemsPerPage < 1 || itemsPerPage > 100 {
+			itemsPerPage = 10
+		}
+
+		// Calculate offset from page and itemsPerPage
+		offset := (page - 1) * itemsPerPage
+		limit := itemsPerPage
+
+		// Other query parameters
+		sortBy := c.Query("sort", "created_at")
+		order := c.Query("order", "desc")
+		searchText := c.Query("search", "")
+		tagNames := tags.ParseNames(c.Query("tags", ""))
+		tagMode := c.Query("tag_mode", tags.ModeAll)
+
+		if tagMode != tags.ModeAll && tagMode != tags.ModeAny {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "tag_mode must be one of: all, any",
+			})
+		}
+
+		// Validate sort parameters
+		validSortFields := map[string]bool{
+			"created_at": true,
+			"updated_at": true,
+			"title":      true,
+			"file_size":  true,
+			"page_count": true,
+		}
+		if !validSortFields[sortBy] {
+			sortBy = "created_at"
+		}
+		if order != "asc" && order != "desc" {
+			order = "desc"
+		}
+
+		// Matches the title or any extracted page text
+		if searchText != "" {
+			query = query.Where(search.PDFCondition("pdfs"), map[string]interface{}{"q": searchText})
+		}
+
+		if len(tagNames) > 0 {
+			query = query.Scopes(tags.Filter("pdfs.id", tagNames, tagMode))
+		}
+
+		// Get total count for pagination
+		var totalCount int64
+		if err := query.Count(&totalCount).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
-				"error":   "backend_error",
-				"message": "Failed to connect to Python backend",
+				"error":   "database_error",
+				"message": "Failed to count PDFs",
 				"details": err.Error(),
 			})
 		}
-		defer resp.Body.Close()
 
-		if resp.StatusCode != 200 {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			return c.Status(resp.StatusCode).JSON(fiber.Map{
-				"error":   "backend_error",
-				"message": "Python backend error",
-				"details": string(bodyBytes),
+		// Calculate total pages
+		totalPages := int((totalCount + int64(itemsPerPage) - 1) / int64(itemsPerPage))
+
+		if err := query.Preload("Summaries").Preload("Tags", tags.ByName).Order(fmt.Sprintf("%s %s", sortBy, order)).Limit(limit).Offset(offset).Find(&pdfs).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch PDFs",
+				"details": err.Error(),
 			})
 		}
 
-		// Parse response into PythonSummaryResponse struct
-		var pythonResponse dto.PythonSummaryResponse
-		if err := json.NewDecoder(resp.Body).Decode(&pythonResponse); err != nil {
+		response := dto.PDFListResponse{
+			Data:         utils.ConvertPDFsToResponse(pdfs),
+			Page:         page,
+			ItemsPerPage: itemsPerPage,
+			TotalPages:   totalPages,
+			TotalItems:   totalCount,
+		}
+
+		return c.Status(200).JSON(response)
+	}
+
+	app.Get("/pdf", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		query := scoped(c).Model(&models.PDF{})
+
+		// PDFs that are not filed in any collection
+		if c.QueryBool("unfiled", false) {
+			query = query.Where("pdfs.collection_id IS NULL")
+		}
+
+		return listPDFs(c, query)
+	})
+
+	app.Get("/pdf/count", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		var count int64
+
+		if err := scoped(c).Model(&models.PDF{}).Count(&count).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"message": "Failed to count PDFs: " + err.Error(),
+			})
+		}
+
+		response := dto.PDFCountResponse{
+			Count: count,
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Post("/pdf", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		var req dto.PDFCreateRequest
+
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"message": "Invalid request body: " + err.Error(),
+			})
+		}
+
+		// Convert DTO to model
+		ownerID := utils.CurrentUserID(c)
+		workspaceID := utils.CurrentWorkspaceID(c)
+		pdf := models.PDF{
+			Filename:    req.Filename,
+			FileSize:    req.FileSize,
+			Title:       req.Title,
+			PageCount:   req.PageCount,
+			OwnerID:     &ownerID,
+			WorkspaceID: &workspaceID,
+		}
+
+		if err := versionService.Create(c.UserContext(), &pdf, nil, req.Filename); err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"message": "Failed to create PDF record: " + err.Error(),
+			})
+		}
+
+		// Convert model to response DTO
+		response := utils.ConvertPDFToResponse(pdf)
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionPDFCreate,
+			TargetType: audit.TargetPDF,
+			TargetID:   fmt.Sprint(pdf.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+		return c.Status(201).JSON(response)
+	})
+
+	app.Get("/pdf/:id", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		var pdf models.PDF
+
+		if err := scoped(c).Preload("Summaries").Preload("Tags", tags.ByName).First(&pdf, c.Params("id")).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"message": "PDF not found",
+			})
+		}
+
+		response := utils.ConvertPDFToResponse(pdf)
+
+		if pdf.CollectionID != nil {
+			crumbs, err := collectionService.Breadcrumbs(utils.CurrentWorkspaceID(c), *pdf.CollectionID)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "database_error",
+					"message": "Failed to load breadcrumbs",
+					"details": err.Error(),
+				})
+			}
+			response.Breadcrumbs = utils.ConvertBreadcrumbsToResponse(crumbs)
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Patch("/pdf/:id", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		var req dto.PDFUpdateRequest
+
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+
+		updates := map[string]interface{}{}
+
+		if req.Title != nil {
+			if err := utils.ValidateTitle(*req.Title); err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_title",
+					"message": err.Error(),
+				})
+			}
+			updates["title"] = strings.TrimSpace(*req.Title)
+		}
+
+		if req.Description != nil {
+			if err := utils.ValidateDescription(*req.Description); err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_description",
+					"message": err.Error(),
+				})
+			}
+			updates["description"] = strings.TrimSpace(*req.Description)
+		}
+
+		if len(updates) == 0 && req.CustomFields == nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Nothing to update, provide title, description or custom_fields",
+			})
+		}
+
+		var pdf models.PDF
+		var before dto.PDFResponse
+		var invalidFields error
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Locked so concurrent edits to different custom fields are all kept
+			if err := tx.Scopes(utils.InWorkspace(utils.CurrentWorkspaceID(c))).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				First(&pdf, c.Params("id")).Error; err != nil {
+				return err
+			}
+			before = utils.ConvertPDFToResponse(pdf)
+
+			if req.CustomFields != nil {
+				fields := models.JSONMap{}
+				for key, value := range pdf.CustomFields {
+					fields[key] = value
+				}
+				for key, value := range req.CustomFields {
+					if value == nil {
+						delete(fields, key)
+					} else {
+						fields[key] = value
+					}
+				}
+				if invalidFields = utils.ValidateCustomFields(fields); invalidFields != nil {
+					return invalidFields
+				}
+				updates["custom_fields"] = fields
+			}
+
+			return tx.Model(&pdf).Updates(updates).Error
+		})
+		if invalidFields != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_custom_fields",
+				"message": invalidFields.Error(),
+			})
+		}
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "PDF not found",
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to update PDF",
+				"details": err.Error(),
+			})
+		}
+
+		if err := scoped(c).Preload("Summaries").Preload("Tags", tags.ByName).First(&pdf, pdf.ID).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to load updated PDF",
+				"details": err.Error(),
+			})
+		}
+
+		response := utils.ConvertPDFToResponse(pdf)
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionPDFUpdate,
+			TargetType: audit.TargetPDF,
+			TargetID:   fmt.Sprint(pdf.ID),
+			Before:     audit.Snapshot(before),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Get("/pdf/:id/download", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		var pdf models.PDF
+
+		if err := scoped(c).First(&pdf, c.Params("id")).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
+		}
+
+		return sendPDFFile(c, pdf.Filename, pdf.ContentHash, pdf.UpdatedAt, pdf.Title, nil)
+	})
+
+	app.Delete("/pdf/:id", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		permanent := c.QueryBool("permanent", false)
+
+		var pdf models.PDF
+
+		// Permanent deletes also reach PDFs already in the trash
+		query := scoped(c)
+		if permanent {
+			query = query.Unscoped()
+		}
+		query.First(&pdf, id)
+
+		if pdf.ID == 0 {
+			return c.Status(404).JSON(fiber.Map{
+				"message": "PDF not found",
+			})
+		}
+
+		event := models.AuditEvent{
+			Action:     audit.ActionPDFDelete,
+			TargetType: audit.TargetPDF,
+			TargetID:   fmt.Sprint(pdf.ID),
+			Before:     audit.Snapshot(utils.ConvertPDFToResponse(pdf)),
+			After:      models.JSONMap{"permanent": permanent},
+		}
+
+		if permanent {
+			// The file is only removed once no other record shares its content
+			if err := trashService.PurgePDF(c.UserContext(), &pdf); err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"message": "Failed to delete PDF: " + err.Error(),
+				})
+			}
+
+			if err := recordAudit(c, event); err != nil {
+				return err
+			}
+
+			return c.Status(200).JSON(fiber.Map{
+				"message": "PDF deleted permanently",
+			})
+		}
+
+		if err := trashService.TrashPDF(&pdf); err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"message": "Failed to delete PDF: " + err.Error(),
+			})
+		}
+		if err := recordAudit(c, event); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"message":  "PDF moved to trash",
+			"purge_at": pdf.DeletedAt.Time.Add(trashService.Retention()),
+		})
+	})
+
+	// errUnreadablePDF means an uploaded file is not a PDF whose pages can be counted
+	errUnreadablePDF := errors.New("invalid PDF file or unable to read page count")
+
+	// newPDF counts the pages of a staged upload and describes the PDF record
+	// to create for it in the current workspace
+	newPDF := func(c *fiber.Ctx, staged *storage.Staged, title string) (models.PDF, error) {
+		pageCount := npdfpages.PagesAtPath(staged.Path)
+		if pageCount <= 0 {
+			return models.PDF{}, errUnreadablePDF
+		}
+
+		ownerID := utils.CurrentUserID(c)
+		workspaceID := utils.CurrentWorkspaceID(c)
+		return models.PDF{
+			Filename:    storage.Filename(staged.Hash),
+			FileSize:    staged.Size,
+			Title:       title,
+			PageCount:   pageCount,
+			ContentHash: staged.Hash,
+			OwnerID:     &ownerID,
+			WorkspaceID: &workspaceID,
+		}, nil
+	}
+
+	// pdfCreated queues text extraction of a new PDF and records its upload,
+	// returning the error of recordAudit
+	pdfCreated := func(c *fiber.Ctx, pdf models.PDF) error {
+		extractor.Enqueue(pdf.ID)
+		metrics.ObserveUpload(pdf.FileSize, pdf.PageCount)
+		return recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionPDFUpload,
+			TargetType: audit.TargetPDF,
+			TargetID:   fmt.Sprint(pdf.ID),
+			After:      audit.Snapshot(utils.ConvertPDFToResponse(pdf)),
+		})
+	}
+
+	// createPDF counts the pages of a staged upload and creates the PDF record
+	// with its first version. Callers pass the new PDF to pdfCreated. The caller
+	// still owns staged.
+	createPDF := func(c *fiber.Ctx, staged *storage.Staged, originalFilename, title string) (*models.PDF, error) {
+		pdf, err := newPDF(c, staged, title)
+		if err != nil {
+			return nil, err
+		}
+		if err := versionService.Create(c.UserContext(), &pdf, staged, originalFilename); err != nil {
+			return nil, err
+		}
+		return &pdf, nil
+	}
+
+	app.Post("/pdf/upload", utils.BodyLimit(uploadBodyLimit, nil), utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		file, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "File is required",
+			})
+		}
+
+		// Validate file extension
+		if err := utils.ValidateFileExtension(file.Filename); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_file",
+				"message": err.Error(),
+			})
+		}
+
+		// Validate file size
+		if err := utils.ValidateFileSize(file.Size); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_file",
+				"message": err.Error(),
+			})
+		}
+
+		// Get title from form data, fallback to filename without extension
+		title := c.FormValue("title")
+		if title == "" {
+			ext := filepath.Ext(file.Filename)
+			title = strings.TrimSuffix(file.Filename, ext)
+		}
+
+		// Validate title
+		if err := utils.ValidateTitle(title); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_title",
+				"message": err.Error(),
+			})
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "server_error",
+				"message": "Failed to read uploaded file",
+				"details": err.Error(),
+			})
+		}
+		defer src.Close()
+
+		// Hash while streaming to disk so identical files can share storage
+		staged, err := contentStore.Stage(src)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "server_error",
+				"message": "Failed to save file",
+				"details": err.Error(),
+			})
+		}
+
+		// Optionally hand back the existing record instead of creating a duplicate
+		if c.QueryBool("dedupe", false) || c.FormValue("dedupe") == "true" {
+			var existing models.PDF
+			err := scoped(c).Preload("Summaries").Preload("Tags", tags.ByName).Where("content_hash = ?", staged.Hash).Order("id asc").First(&existing).Error
+			if err == nil {
+				contentStore.Discard(staged)
+				return c.Status(200).JSON(utils.ConvertPDFToResponse(existing))
+			}
+			if err != gorm.ErrRecordNotFound {
+				contentStore.Discard(staged)
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "database_error",
+					"message": "Failed to look up existing PDF",
+					"details": err.Error(),
+				})
+			}
+		}
+
+		pdf, err := createPDF(c, staged, file.Filename, title)
+		contentStore.Discard(staged)
+		if err == errUnreadablePDF {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_file",
+				"message": "Invalid PDF file or unable to read page count",
+			})
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to create PDF record",
+				"details": err.Error(),
+			})
+		}
+		if err := pdfCreated(c, *pdf); err != nil {
+			return err
+		}
+
+		response := utils.ConvertPDFToResponse(*pdf)
+		return c.Status(201).JSON(response)
+	})
+
+	// summaryFromCache completes job with a summary already generated for the
+	// same content, reporting whether one was found
+	summaryFromCache := func(pdf models.PDF, job *models.SummaryJob) (bool, error) {
+		provider := jobs.ProviderFor(summarizers, summarizer.Options{Style: job.Style, Language: job.Language})
+		cached, err := summaryCache.Lookup(pdf, job.Style, job.Language, provider)
+		if err != nil || cached == nil {
+			return false, err
+		}
+
+		now := time.Now()
+		job.Status = models.JobStatusSucceeded
+		job.Cached = true
+		job.SummaryID = &cached.ID
+		job.FinishedAt = &now
+
+		if err := db.Create(job).Error; err != nil {
+			return false, fmt.Errorf("failed to record summary job: %w", err)
+		}
+
+		job.Summary = cached
+		return true, nil
+	}
+
+	// Uploads many PDFs at once, as several "files" fields or inside ZIP
+	// archives. Every file gets its own result so one bad file doesn't fail the batch.
+	app.Post("/pdf/upload/batch", utils.BodyLimit(batchBodyLimit, nil), utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		form, err := c.MultipartForm()
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Multipart form data is required",
+			})
+		}
+
+		files := append(form.File["files"], form.File["file"]...)
+		if len(files) == 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "At least one file is required",
+			})
+		}
+
+		dedupe := c.QueryBool("dedupe", false) || c.FormValue("dedupe") == "true"
+		summarize := c.QueryBool("summarize", false) || c.FormValue("summarize") == "true"
+		style := strings.ToLower(c.FormValue("style", "general"))
+		language := strings.ToLower(c.FormValue("language", "english"))
+
+		if summarize {
+			if err := utils.ValidateSummaryStyle(style); err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_style",
+					"message": err.Error(),
+				})
+			}
+
+			if err := utils.ValidateLanguage(language); err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_language",
+					"message": err.Error(),
+				})
+			}
+
+			// Refuse the whole batch rather than importing files whose summaries can't be queued
+			if err := summarizers.Check(summarizer.Options{Style: style, Language: language}); err != nil {
+				var open *summarizer.CircuitOpenError
+				if errors.As(err, &open) {
+					c.Set("Retry-After", strconv.Itoa(int(open.RetryAfter.Seconds())+1))
+				}
+				return c.Status(503).JSON(fiber.Map{
+					"error":   "summarizer_unavailable",
+					"message": err.Error(),
+				})
+			}
+		}
+
+		// Set when a created PDF could not be recorded in the audit log, which stops the batch
+		var auditErr error
+
+		// importPDF validates one file, stores it and creates its record
+		importPDF := func(name string, size int64, open func() (io.ReadCloser, error)) dto.BatchUploadResult {
+			result := dto.BatchUploadResult{Filename: name, Status: "failed"}
+			fail := func(code, message string) dto.BatchUploadResult {
+				result.Error = code
+				result.Message = message
+				return result
+			}
+
+			if err := utils.ValidateFileExtension(name); err != nil {
+				return fail("invalid_file", err.Error())
+			}
+			if err := utils.ValidateFileSize(size); err != nil {
+				return fail("invalid_file", err.Error())
+			}
+
+			base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+			title := strings.TrimSuffix(base, filepath.Ext(base))
+			if err := utils.ValidateTitle(title); err != nil {
+				return fail("invalid_title", err.Error())
+			}
+
+			src, err := open()
+			if err != nil {
+				if batch.Corrupt(err) {
+					return fail("invalid_file", err.Error())
+				}
+				return fail("server_error", "Failed to read file: "+err.Error())
+			}
+			staged, err := contentStore.Stage(src)
+			src.Close()
+			if err != nil {
+				if batch.Corrupt(err) {
+					return fail("invalid_file", err.Error())
+				}
+				return fail("server_error", "Failed to save file: "+err.Error())
+			}
+			defer contentStore.Discard(staged)
+
+			if dedupe {
+				var existing models.PDF
+				err := scoped(c).Preload("Summaries").Preload("Tags", tags.ByName).Where("content_hash = ?", staged.Hash).Order("id asc").First(&existing).Error
+				if err == nil {
+					response := utils.ConvertPDFToResponse(existing)
+					result.Status = "duplicate"
+					result.PDF = &response
+					return result
+				}
+				if err != gorm.ErrRecordNotFound {
+					return fail("database_error", "Failed to look up existing PDF: "+err.Error())
+				}
+			}
+
+			pdf, err := createPDF(c, staged, base, title)
+			if err == errUnreadablePDF {
+				return fail("invalid_file", "Invalid PDF file or unable to read page count")
+			}
+			if err != nil {
+				return fail("database_error", "Failed to create PDF record: "+err.Error())
+			}
+			if err := pdfCreated(c, *pdf); err != nil {
+				auditErr = err
+			}
+
+			response := utils.ConvertPDFToResponse(*pdf)
+			result.Status = "created"
+			result.PDF = &response
+
+			if summarize {
+				job := models.SummaryJob{PDFID: pdf.ID, Style: style, Language: language}
+				cached, err := summaryFromCache(*pdf, &job)
+				if err == nil && !cached {
+					err = summaryJobs.Enqueue(&job)
+				}
+				if err != nil {
+					result.JobError = "Failed to enqueue summary job: " + err.Error()
+				} else {
+					jobResponse := utils.ConvertSummaryJobToResponse(job)
+					result.Job = &jobResponse
+				}
+			}
+
+			return result
+		}
+
+		// Expand archives up front so their limits are checked before anything is stored
+		type upload struct {
+			name string
+			size int64
+			open func() (io.ReadCloser, error)
+			err  error
+		}
+		var uploads []upload
+		for _, file := range files {
+			if !strings.EqualFold(filepath.Ext(file.Filename), ".zip") {
+				uploads = append(uploads, upload{
+					name: file.Filename,
+					size: file.Size,
+					open: func() (io.ReadCloser, error) { return file.Open() },
+				})
+				continue
+			}
+
+			archive, err := file.Open()
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "server_error",
+					"message": "Failed to read uploaded archive",
+					"details": err.Error(),
+				})
+			}
+			defer archive.Close()
+
+			entries, err := batch.ReadZip(archive, file.Size, batchLimits)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_archive",
+					"message": fmt.Sprintf("%s: %s", file.Filename, err.Error()),
+				})
+			}
+			for _, entry := range entries {
+				uploads = append(uploads, upload{
+					name: file.Filename + "/" + entry.Name,
+					size: entry.Size,
+					open: entry.Open,
+					err:  entry.Err,
+				})
+			}
+		}
+
+		if len(uploads) > batchLimits.MaxFiles {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": fmt.Sprintf("Cannot upload more than %d files at once", batchLimits.MaxFiles),
+			})
+		}
+
+		response := dto.BatchUploadResponse{Results: make([]dto.BatchUploadResult, 0, len(uploads))}
+		for _, item := range uploads {
+			var result dto.BatchUploadResult
+			if item.err != nil {
+				result = dto.BatchUploadResult{Filename: item.name, Status: "failed", Error: "invalid_file", Message: item.err.Error()}
+			} else {
+				result = importPDF(item.name, item.size, item.open)
+			}
+			if auditErr != nil {
+				return auditErr
+			}
+
+			switch result.Status {
+			case "created":
+				response.Created++
+			case "duplicate":
+				response.Duplicate++
+			default:
+				response.Failed++
+			}
+			response.Results = append(response.Results, result)
+		}
+
+		// 207 tells clients to look at each result when some files were rejected
+		status := 201
+		if response.Failed > 0 {
+			status = 207
+		}
+		return c.Status(status).JSON(response)
+	})
+
+	// uploadError responds to errors from the upload service
+	uploadError := func(c *fiber.Ctx, err error, message string) error {
+		switch err {
+		case resumable.ErrNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Upload not found",
+			})
+		case resumable.ErrOffsetMismatch:
+			return c.Status(409).JSON(fiber.Map{
+				"error":   "offset_mismatch",
+				"message": "Upload-Offset does not match the bytes received, check the offset with HEAD",
+			})
+		case resumable.ErrTooLong:
+			return c.Status(413).JSON(fiber.Map{
+				"error":   "length_exceeded",
+				"message": "The chunk goes past the upload's length",
+			})
+		case resumable.ErrIncomplete:
+			return c.Status(409).JSON(fiber.Map{
+				"error":   "incomplete",
+				"message": "The upload has not received all of its bytes yet",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "server_error",
+			"message": message,
+			"details": err.Error(),
+		})
+	}
+
+	// setUploadHeaders reports an upload's progress in tus headers
+	setUploadHeaders := func(c *fiber.Ctx, upload *models.Upload) {
+		c.Set("Upload-Offset", strconv.FormatInt(upload.Received, 10))
+		c.Set("Upload-Length", strconv.FormatInt(upload.Size, 10))
+		c.Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+		c.Set("Cache-Control", "no-store")
+	}
+
+	// Every upload route speaks tus 1.0.0 and rejects other protocol versions
+	app.Use("/uploads", func(c *fiber.Ctx) error {
+		c.Set("Tus-Resumable", resumable.Version)
+		if version := c.Get("Tus-Resumable"); version != "" && version != resumable.Version {
+			c.Set("Tus-Version", resumable.Version)
+			return c.Status(412).JSON(fiber.Map{
+				"error":   "unsupported_version",
+				"message": "Only tus " + resumable.Version + " is supported",
+			})
+		}
+		return c.Next()
+	})
+
+	// Starts a resumable upload. Upload-Length is the file size and Upload-Metadata
+	// carries the base64 encoded "filename" and optional "title".
+	app.Post("/uploads", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		size, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Upload-Length header must be the file size in bytes",
+			})
+		}
+
+		metadata, err := resumable.ParseMetadata(c.Get("Upload-Metadata"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid Upload-Metadata header",
+				"details": err.Error(),
+			})
+		}
+
+		filename := path.Base(strings.ReplaceAll(metadata["filename"], "\\", "/"))
+		if metadata["filename"] == "" {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Upload-Metadata must include a filename",
+			})
+		}
+
+		// Validate up front so a bad file is refused before any of it is sent
+		if err := utils.ValidateFileExtension(filename); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_file",
+				"message": err.Error(),
+			})
+		}
+
+		if err := utils.ValidateFileSize(size); err != nil {
+			return c.Status(413).JSON(fiber.Map{
+				"error":   "invalid_file",
+				"message": err.Error(),
+			})
+		}
+
+		title := metadata["title"]
+		if title == "" {
+			title = strings.TrimSuffix(filename, filepath.Ext(filename))
+		}
+
+		if err := utils.ValidateTitle(title); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_title",
+				"message": err.Error(),
+			})
+		}
+
+		upload, err := uploadService.Create(utils.CurrentWorkspaceID(c), utils.CurrentUserID(c), filename, title, size)
+		if err != nil {
+			return uploadError(c, err, "Failed to create upload")
+		}
+
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionUploadCreate,
+			TargetType: audit.TargetUpload,
+			TargetID:   upload.ID,
+			After:      audit.Snapshot(fiber.Map{"filename": upload.Filename, "title": upload.Title, "length": upload.Size}),
+		}); err != nil {
+			return err
+		}
+
+		location := utils.WorkspacePath(c, "/uploads/"+upload.ID)
+		setUploadHeaders(c, upload)
+		c.Location(location)
+		return c.Status(201).JSON(dto.UploadResponse{
+			ID:        upload.ID,
+			Filename:  upload.Filename,
+			Title:     upload.Title,
+			Length:    upload.Size,
+			Offset:    upload.Received,
+			ExpiresAt: upload.ExpiresAt,
+			Location:  location,
+		})
+	})
+
+	// Reports how many bytes have been received so an interrupted upload can resume
+	app.Head("/uploads/:id", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		upload, err := uploadService.Get(utils.CurrentWorkspaceID(c), utils.CurrentUserID(c), c.Params("id"))
+		if err != nil {
+			return uploadError(c, err, "Failed to get upload")
+		}
+
+		setUploadHeaders(c, upload)
+		return c.SendStatus(200)
+	})
+
+	// Appends the request body at Upload-Offset
+	app.Patch("/uploads/:id", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		if c.Get("Content-Type") != "application/offset+octet-stream" {
+			return c.Status(415).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Content-Type must be application/offset+octet-stream",
+			})
+		}
+
+		offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+		if err != nil || offset < 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Upload-Offset header must be the number of bytes already sent",
+			})
+		}
+
+		// Chunks are streamed to the blob store, so their length must be known up front
+		length := int64(c.Request().Header.ContentLength())
+		if length < 0 {
+			return c.Status(411).JSON(fiber.Map{
+				"error":   "length_required",
+				"message": "Content-Length header is required",
+			})
+		}
+		if length > maxUploadChunk {
+			c.Context().SetConnectionClose()
+			return c.Status(413).JSON(fiber.Map{
+				"error":   "chunk_too_large",
+				"message": fmt.Sprintf("Chunks cannot exceed %d bytes", maxUploadChunk),
+			})
+		}
+
+		var chunk io.Reader = http.NoBody
+		if stream := c.Context().RequestBodyStream(); stream != nil {
+			chunk = io.LimitReader(stream, length)
+		}
+
+		upload, err := uploadService.Append(c.UserContext(), utils.CurrentWorkspaceID(c), utils.CurrentUserID(c), c.Params("id"), offset, chunk, length)
+		if err != nil {
+			// The chunk may not have been read, so the connection can't be reused
+			c.Context().SetConnectionClose()
+			return uploadError(c, err, "Failed to store chunk")
+		}
+
+		setUploadHeaders(c, upload)
+		return c.SendStatus(204)
+	})
+
+	// Turns a complete upload into a PDF, like POST /pdf/upload does with a single request
+	app.Post("/uploads/:id/finish", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		// The size and title were validated when the upload was created
+		var pdf models.PDF
+		err := uploadService.Finish(c.UserContext(), utils.CurrentWorkspaceID(c), utils.CurrentUserID(c), c.Params("id"), func(tx *gorm.DB, upload models.Upload, staged *storage.Staged) error {
+			var err error
+			if pdf, err = newPDF(c, staged, upload.Title); err != nil {
+				return err
+			}
+			return versionService.CreateIn(tx, &pdf, staged, upload.Filename)
+		})
+		if err == errUnreadablePDF {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_file",
+				"message": "Invalid PDF file or unable to read page count",
+			})
+		}
+		if err != nil {
+			return uploadError(c, err, "Failed to finish upload")
+		}
+
+		if err := pdfCreated(c, pdf); err != nil {
+			return err
+		}
+		return c.Status(201).JSON(utils.ConvertPDFToResponse(pdf))
+	})
+
+	// Cancels an upload and discards the chunks received so far
+	app.Delete("/uploads/:id", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		if err := uploadService.Delete(c.UserContext(), utils.CurrentWorkspaceID(c), utils.CurrentUserID(c), c.Params("id")); err != nil {
+			return uploadError(c, err, "Failed to cancel upload")
+		}
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionUploadCancel,
+			TargetType: audit.TargetUpload,
+			TargetID:   c.Params("id"),
+		}); err != nil {
+			return err
+		}
+		return c.SendStatus(204)
+	})
+
+	// shareResponse describes a link with its public URL
+	shareResponse := func(c *fiber.Ctx, link models.ShareLink) dto.ShareLinkResponse {
+		token := shareService.Token(link)
+		base := shareBaseURL
+		if base == "" {
+			base = c.BaseURL()
+		}
+		return utils.ConvertShareLinkToResponse(link, token, base+"/s/"+token)
+	}
+
+	// createShare validates a share request and creates the link with share
+	createShare := func(c *fiber.Ctx, share func(opts shares.Options) (*models.ShareLink, error), notFound string) error {
+		var req dto.CreateShareRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_request",
+					"message": "Invalid request body",
+					"details": err.Error(),
+				})
+			}
+		}
+
+		now := time.Now()
+		opts := shares.Options{
+			ExpiresAt:    now.Add(shareDefaultTTL),
+			Password:     req.Password,
+			MaxDownloads: req.MaxDownloads,
+		}
+		if req.ExpiresAt != nil {
+			opts.ExpiresAt = *req.ExpiresAt
+		}
+
+		if !opts.ExpiresAt.After(now) || opts.ExpiresAt.After(now.Add(shareMaxTTL)) {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_expires_at",
+				"message": fmt.Sprintf("expires_at must be in the future and at most %s away", shareMaxTTL),
+			})
+		}
+
+		if req.Password != "" {
+			if err := utils.ValidatePassword(req.Password); err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_password",
+					"message": err.Error(),
+				})
+			}
+		}
+
+		if req.MaxDownloads != nil && *req.MaxDownloads < 1 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_max_downloads",
+				"message": "max_downloads must be at least 1, leave it out for no limit",
+			})
+		}
+
+		link, err := share(opts)
+		if err != nil {
+			if err == shares.ErrTargetNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": notFound,
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to create share link",
+				"details": err.Error(),
+			})
+		}
+
+		// Snapshots leave out the token, which would open the link
+		event := models.AuditEvent{
+			Action:     audit.ActionPDFShare,
+			TargetType: audit.TargetPDF,
+			After:      audit.Snapshot(utils.ConvertShareLinkToResponse(*link, "", "")),
+		}
+		if link.SummaryID != nil {
+			event.Action = audit.ActionSummaryShare
+			event.TargetType = audit.TargetSummary
+			event.TargetID = fmt.Sprint(*link.SummaryID)
+		} else {
+			event.TargetID = fmt.Sprint(*link.PDFID)
+		}
+		if err := recordAudit(c, event); err != nil {
+			return err
+		}
+
+		return c.Status(201).JSON(shareResponse(c, *link))
+	}
+
+	app.Post("/pdf/:id/share", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "PDF ID must be a number",
+			})
+		}
+
+		return createShare(c, func(opts shares.Options) (*models.ShareLink, error) {
+			return shareService.SharePDF(utils.CurrentWorkspaceID(c), utils.CurrentUserID(c), uint(id), opts)
+		}, "PDF not found")
+	})
+
+	app.Post("/summaries/:id/share", utils.RequireScope(auth.ScopeSummaryWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Summary ID must be a number",
+			})
+		}
+
+		return createShare(c, func(opts shares.Options) (*models.ShareLink, error) {
+			return shareService.ShareSummary(utils.CurrentWorkspaceID(c), utils.CurrentUserID(c), uint(id), opts)
+		}, "Summary not found")
+	})
+
+	// Lists the workspace's active links, optionally for one PDF or summary (all=true includes inactive ones)
+	app.Get("/shares", utils.RequireScope(auth.ScopePDFWrite, auth.ScopeSummaryWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		filter := shares.Filter{Inactive: c.QueryBool("all", false)}
+		if c.Query("pdf_id") != "" {
+			id, err := strconv.ParseUint(c.Query("pdf_id"), 10, 64)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_id",
+					"message": "pdf_id must be a number",
+				})
+			}
+			pdfID := uint(id)
+			filter.PDFID = &pdfID
+		}
+		if c.Query("summary_id") != "" {
+			id, err := strconv.ParseUint(c.Query("summary_id"), 10, 64)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_id",
+					"message": "summary_id must be a number",
+				})
+			}
+			summaryID := uint(id)
+			filter.SummaryID = &summaryID
+		}
+
+		links, err := shareService.List(utils.CurrentWorkspaceID(c), filter)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch share links",
+				"details": err.Error(),
+			})
+		}
+
+		response := make([]dto.ShareLinkResponse, len(links))
+		for i, link := range links {
+			response[i] = shareResponse(c, link)
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"data": response,
+		})
+	})
+
+	app.Delete("/shares/:id", utils.RequireScope(auth.ScopePDFWrite, auth.ScopeSummaryWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Share link ID must be a number",
+			})
+		}
+
+		link, err := shareService.Revoke(utils.CurrentWorkspaceID(c), uint(id))
+		if err != nil {
+			if err == shares.ErrNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "Share link not found",
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to revoke share link",
+				"details": err.Error(),
+			})
+		}
+
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionShareRevoke,
+			TargetType: audit.TargetShare,
+			TargetID:   fmt.Sprint(link.ID),
+			After:      audit.Snapshot(utils.ConvertShareLinkToResponse(*link, "", "")),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"message": "Share link revoked successfully",
+			"id":      link.ID,
+		})
+	})
+
+	// versionError responds to errors from the version service
+	versionError := func(c *fiber.Ctx, err error, message string) error {
+		switch err {
+		case versions.ErrNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
+		case versions.ErrVersionNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "version_not_found",
+				"message": "Version not found",
+			})
+		case versions.ErrSameFile:
+			return c.Status(409).JSON(fiber.Map{
+				"error":   "same_file",
+				"message": "The file is identical to the current version",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "database_error",
+			"message": message,
+			"details": err.Error(),
+		})
+	}
+
+	app.Put("/pdf/:id/file", utils.BodyLimit(uploadBodyLimit, nil), utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "PDF ID must be a number",
+			})
+		}
+
+		// Check before reading the upload so a bad ID doesn't cost a full transfer to disk
+		var current models.PDF
+		if err := scoped(c).First(&current, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return versionError(c, versions.ErrNotFound, "")
+			}
+			return versionError(c, err, "Failed to find PDF")
+		}
+
+		file, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "File is required",
+			})
+		}
+
+		if err := utils.ValidateFileExtension(file.Filename); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_file",
+				"message": err.Error(),
+			})
+		}
+
+		if err := utils.ValidateFileSize(file.Size); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_file",
+				"message": err.Error(),
+			})
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "server_error",
+				"message": "Failed to read uploaded file",
+				"details": err.Error(),
+			})
+		}
+		defer src.Close()
+
+		staged, err := contentStore.Stage(src)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "server_error",
+				"message": "Failed to save file",
+				"details": err.Error(),
+			})
+		}
+		defer contentStore.Discard(staged)
+
+		pageCount := npdfpages.PagesAtPath(staged.Path)
+		if pageCount <= 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_file",
+				"message": "Invalid PDF file or unable to read page count",
+			})
+		}
+
+		pdf, version, err := versionService.Replace(c.UserContext(), utils.CurrentWorkspaceID(c), uint(id), staged, file.Filename, pageCount, utils.CurrentUserID(c))
+		if err != nil {
+			return versionError(c, err, "Failed to replace PDF file")
+		}
+
+		extractor.Enqueue(pdf.ID)
+		metrics.ObserveUpload(staged.Size, pageCount)
+
+		// Summaries of the previous file come back flagged as stale
+		if err := db.Preload("Summaries").Preload("Tags", tags.ByName).First(pdf, pdf.ID).Error; err != nil {
+			return versionError(c, err, "Failed to load updated PDF")
+		}
+
+		response := dto.PDFReplaceResponse{
+			PDF:     utils.ConvertPDFToResponse(*pdf),
+			Version: utils.ConvertPDFVersionToResponse(*version, pdf.Version),
+		}
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionPDFReplaceFile,
+			TargetType: audit.TargetPDF,
+			TargetID:   fmt.Sprint(pdf.ID),
+			Before:     audit.Snapshot(utils.ConvertPDFToResponse(current)),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Get("/pdf/:id/versions", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "PDF ID must be a number",
+			})
+		}
+
+		var pdf models.PDF
+		if err := scoped(c).First(&pdf, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return versionError(c, versions.ErrNotFound, "")
+			}
+			return versionError(c, err, "Failed to find PDF")
+		}
+
+		page, itemsPerPage := utils.ValidatePaginationParams(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 10))
+
+		history, total, err := versionService.List(pdf.ID, itemsPerPage, (page-1)*itemsPerPage)
+		if err != nil {
+			return versionError(c, err, "Failed to list versions")
+		}
+
+		data := make([]dto.PDFVersionResponse, len(history))
+		for i, version := range history {
+			data[i] = utils.ConvertPDFVersionToResponse(version, pdf.Version)
+		}
+
+		return c.Status(200).JSON(dto.PDFVersionListResponse{
+			Data:         data,
+			Page:         page,
+			ItemsPerPage: itemsPerPage,
+			TotalPages:   int((total + int64(itemsPerPage) - 1) / int64(itemsPerPage)),
+			TotalItems:   total,
+		})
+	})
+
+	app.Get("/pdf/:id/versions/:version/download", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "PDF ID must be a number",
+			})
+		}
+
+		number, err := strconv.Atoi(c.Params("version"))
+		if err != nil || number < 1 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_version",
+				"message": "Version must be a positive number",
+			})
+		}
+
+		pdf, version, err := versionService.Get(utils.CurrentWorkspaceID(c), uint(id), number)
+		if err != nil {
+			return versionError(c, err, "Failed to find version")
+		}
+
+		return sendPDFFile(c, version.Filename, version.ContentHash, version.CreatedAt, fmt.Sprintf("%s (v%d)", pdf.Title, version.Version), nil)
+	})
+
+	app.Get("/pdf/:id/pages", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "PDF ID must be a number",
+			})
+		}
+
+		var pdf models.PDF
+		if err := scoped(c).First(&pdf, id).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
+		}
+
+		var pages []models.PDFPage
+		if err := db.Where("pdf_id = ?", pdf.ID).Order("page_number asc").Find(&pages).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch pages",
+				"details": err.Error(),
+			})
+		}
+
+		response := dto.PDFPagesResponse{
+			PDFID:      pdf.ID,
+			TextStatus: pdf.TextStatus,
+			PageCount:  pdf.PageCount,
+			Pages:      utils.ConvertPDFPagesToResponse(pages, c.QueryBool("include_text", false)),
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Get("/pdf/:id/pages/:n/text", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "PDF ID must be a number",
+			})
+		}
+
+		var pdf models.PDF
+		if err := scoped(c).First(&pdf, id).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
+		}
+
+		pageNumber, err := strconv.Atoi(c.Params("n"))
+		if err != nil || pageNumber < 1 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Page number must be a positive integer",
+			})
+		}
+
+		var page models.PDFPage
+		if err := db.Where("pdf_id = ? AND page_number = ?", pdf.ID, pageNumber).First(&page).Error; err != nil {
+			if err != gorm.ErrRecordNotFound {
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "database_error",
+					"message": "Failed to fetch page",
+					"details": err.Error(),
+				})
+			}
+
+			// Distinguish a page that doesn't exist from text that isn't ready yet
+			if pdf.TextStatus != models.TextStatusExtracted {
+				return c.Status(404).JSON(fiber.Map{
+					"error":       "text_not_available",
+					"message":     "Text has not been extracted for this PDF",
+					"text_status": pdf.TextStatus,
+				})
+			}
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Page not found",
+			})
+		}
+
+		c.Set("Content-Type", "text/plain; charset=utf-8")
+		return c.Status(200).SendString(page.Text)
+	})
+
+	app.Post("/pdf/:id/summarize", utils.RequireScope(auth.ScopeSummaryGenerate), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		var req dto.SummarizeRequest
+
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+
+		// Validate style and language
+		if err := utils.ValidateSummaryStyle(req.Style); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_style",
+				"message": err.Error(),
+			})
+		}
+
+		if err := utils.ValidateLanguage(req.Language); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_language",
+				"message": err.Error(),
+			})
+		}
+
+		id := c.Params("id")
+		var pdf models.PDF
+
+		if err := scoped(c).First(&pdf, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "PDF not found",
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to find PDF",
+				"details": err.Error(),
+			})
+		}
+
+		job := models.SummaryJob{
+			PDFID:    pdf.ID,
+			Style:    strings.ToLower(req.Style),
+			Language: strings.ToLower(req.Language),
+			Force:    req.Force || c.QueryBool("force", false),
+		}
+
+		// Reuse a summary of the same content instead of calling the model again
+		if !job.Force {
+			cached, err := summaryFromCache(pdf, &job)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "database_error",
+					"message": "Failed to use cached summary",
+					"details": err.Error(),
+				})
+			}
+			if cached {
+				response := utils.ConvertSummaryJobToResponse(job)
+				if err := recordAudit(c, models.AuditEvent{
+					Action:     audit.ActionPDFSummarize,
+					TargetType: audit.TargetPDF,
+					TargetID:   fmt.Sprint(pdf.ID),
+					After:      audit.Snapshot(response),
+				}); err != nil {
+					return err
+				}
+				return c.Status(200).JSON(response)
+			}
+		}
+
+		// Fail fast while the summarizer's circuit breaker is open instead of queueing doomed work
+		if err := summarizers.Check(summarizer.Options{Style: job.Style, Language: job.Language}); err != nil {
+			var open *summarizer.CircuitOpenError
+			if errors.As(err, &open) {
+				c.Set("Retry-After", strconv.Itoa(int(open.RetryAfter.Seconds())+1))
+			}
+			return c.Status(503).JSON(fiber.Map{
+				"error":   "summarizer_unavailable",
+				"message": err.Error(),
+			})
+		}
+
+		if err := summaryJobs.Enqueue(&job); err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to enqueue summary job",
+				"details": err.Error(),
+			})
+		}
+
+		response := utils.ConvertSummaryJobToResponse(job)
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionPDFSummarize,
+			TargetType: audit.TargetPDF,
+			TargetID:   fmt.Sprint(pdf.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		c.Location(utils.WorkspacePath(c, fmt.Sprintf("/jobs/%d", job.ID)))
+		return c.Status(202).JSON(response)
+	})
+
+	app.Get("/pdf/:id/jobs", utils.RequireScope(auth.ScopeSummaryGenerate, auth.ScopeSummaryRead), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "PDF ID must be a number",
+			})
+		}
+
+		var pdf models.PDF
+		if err := scoped(c).First(&pdf, id).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
+		}
+
+		page, itemsPerPage := utils.ValidatePaginationParams(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 10))
+		offset := (page - 1) * itemsPerPage
+
+		query := db.Model(&models.SummaryJob{}).Where("pdf_id = ?", pdf.ID)
+
+		if status := c.Query("status", ""); status != "" {
+			query = query.Where("status = ?", status)
+		}
+
+		var totalCount int64
+		if err := query.Count(&totalCount).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to count summary jobs",
+				"details": err.Error(),
+			})
+		}
+
+		totalPages := int((totalCount + int64(itemsPerPage) - 1) / int64(itemsPerPage))
+
+		var jobs []models.SummaryJob
+		if err := query.Preload("Summary").Order("created_at desc").Limit(itemsPerPage).Offset(offset).Find(&jobs).Error; err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch summary jobs",
+				"details": err.Error(),
+			})
+		}
+
+		response := dto.SummaryJobListResponse{
+			Data:         utils.ConvertSummaryJobsToResponse(jobs),
+			Page:         page,
+			ItemsPerPage: itemsPerPage,
+			TotalPages:   totalPages,
+			TotalItems:   totalCount,
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Get("/jobs/:id", utils.RequireScope(auth.ScopeSummaryGenerate, auth.ScopeSummaryRead), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Job ID must be a number",
+			})
+		}
+
+		var job models.SummaryJob
+		err = db.Preload("Summary").
+			Joins("JOIN pdfs ON pdfs.id = summary_jobs.pdf_id").
+			Where("pdfs.workspace_id = ?", utils.CurrentWorkspaceID(c)).
+			First(&job, "summary_jobs.id = ?", id).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "Summary job not found",
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to find summary job",
+				"details": err.Error(),
+			})
+		}
+
+		return c.Status(200).JSON(utils.ConvertSummaryJobToResponse(job))
+	})
+
+	// searchDocuments runs a ranked full-text search, optionally within one PDF
+	searchDocuments := func(c *fiber.Ctx, pdfID uint) error {
+		q := strings.TrimSpace(c.Query("q", ""))
+		if q == "" {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Query parameter q is required",
+			})
+		}
+
+		page, itemsPerPage := utils.ValidatePaginationParams(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 10))
+
+		// Restrict to some of pdf, page and summary hits
+		var types []string
+		if raw := c.Query("type", ""); raw != "" {
+			for _, t := range strings.Split(raw, ",") {
+				t = strings.TrimSpace(t)
+				if t != search.TypePDF && t != search.TypePage && t != search.TypeSummary {
+					return c.Status(400).JSON(fiber.Map{
+						"error":   "invalid_request",
+						"message": "Type must be one of: pdf, page, summary",
+					})
+				}
+				types = append(types, t)
+			}
+		}
+
+		hits, total, err := search.Run(db, search.Query{
+			Text:        q,
+			WorkspaceID: utils.CurrentWorkspaceID(c),
+			PDFID:       pdfID,
+			Types:       types,
+			Limit:       itemsPerPage,
+			Offset:      (page - 1) * itemsPerPage,
+		})
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to search",
+				"details": err.Error(),
+			})
+		}
+
+		response := dto.SearchResponse{
+			Query:        q,
+			Data:         utils.ConvertSearchHitsToResponse(hits),
+			Page:         page,
+			ItemsPerPage: itemsPerPage,
+			TotalPages:   int((total + int64(itemsPerPage) - 1) / int64(itemsPerPage)),
+			TotalItems:   total,
+		}
+
+		return c.Status(200).JSON(response)
+	}
+
+	app.Get("/search", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		pdfID := c.QueryInt("pdf_id", 0)
+		if pdfID < 0 {
+			pdfID = 0
+		}
+		return searchDocuments(c, uint(pdfID))
+	})
+
+	app.Get("/pdf/:id/search", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		var pdf models.PDF
+
+		if err := scoped(c).First(&pdf, c.Params("id")).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
+		}
+
+		return searchDocuments(c, pdf.ID)
+	})
+
+	// tagError responds to errors from the tag service
+	tagError := func(c *fiber.Ctx, err error, message string) error {
+		switch err {
+		case tags.ErrNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Tag not found",
+			})
+		case tags.ErrPDFNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
+		case tags.ErrExists:
+			return c.Status(409).JSON(fiber.Map{
+				"error":   "tag_exists",
+				"message": "A tag with that name already exists",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "database_error",
+			"message": message,
+			"details": err.Error(),
+		})
+	}
+
+	// tagNames validates tag names from a request body and drops duplicates
+	tagNames := func(names []string) ([]string, error) {
+		for _, name := range names {
+			if err := utils.ValidateTagName(name); err != nil {
+				return nil, err
+			}
+		}
+		return tags.UniqueNames(names), nil
+	}
+
+	app.Get("/tags", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		counts, err := tagService.List(utils.CurrentWorkspaceID(c))
+		if err != nil {
+			return tagError(c, err, "Failed to list tags")
+		}
+
+		data := make([]dto.TagCountResponse, len(counts))
+		for i, count := range counts {
+			data[i] = dto.TagCountResponse{
+				ID:       count.ID,
+				Name:     count.Name,
+				PDFCount: count.PDFCount,
+			}
+		}
+
+		return c.Status(200).JSON(dto.TagListResponse{Data: data})
+	})
+
+	app.Post("/tags", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		var req dto.TagRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+
+		if err := utils.ValidateTagName(req.Name); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_tag",
+				"message": err.Error(),
+			})
+		}
+
+		tag, err := tagService.Create(utils.CurrentWorkspaceID(c), req.Name)
+		if err != nil {
+			return tagError(c, err, "Failed to create tag")
+		}
+
+		response := dto.TagResponse{ID: tag.ID, Name: tag.Name}
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionTagCreate,
+			TargetType: audit.TargetTag,
+			TargetID:   fmt.Sprint(tag.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(201).JSON(response)
+	})
+
+	app.Patch("/tags/:id", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Tag ID must be a number",
+			})
+		}
+
+		var req dto.TagRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+
+		if err := utils.ValidateTagName(req.Name); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_tag",
+				"message": err.Error(),
+			})
+		}
+
+		tag, err := tagService.Rename(utils.CurrentWorkspaceID(c), uint(id), req.Name)
+		if err != nil {
+			return tagError(c, err, "Failed to rename tag")
+		}
+
+		response := dto.TagResponse{ID: tag.ID, Name: tag.Name}
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionTagRename,
+			TargetType: audit.TargetTag,
+			TargetID:   fmt.Sprint(tag.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Delete("/tags/:id", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Tag ID must be a number",
+			})
+		}
+
+		if err := tagService.Delete(utils.CurrentWorkspaceID(c), uint(id)); err != nil {
+			return tagError(c, err, "Failed to delete tag")
+		}
+
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionTagDelete,
+			TargetType: audit.TargetTag,
+			TargetID:   fmt.Sprint(id),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"message": "Tag deleted",
+		})
+	})
+
+	// Tag or untag several PDFs in one transaction
+	app.Post("/pdf/tags/bulk", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		var req dto.BulkTagRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+
+		if len(req.PDFIDs) == 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "No PDF IDs provided",
+			})
+		}
+
+		if len(req.PDFIDs) > 100 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Cannot tag more than 100 PDFs at once",
+			})
+		}
+
+		add, err := tagNames(req.Add)
+		var remove []string
+		if err == nil {
+			remove, err = tagNames(req.Remove)
+		}
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_tag",
+				"message": err.Error(),
+			})
+		}
+
+		if len(add) == 0 && len(remove) == 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Provide tags to add or remove",
+			})
+		}
+
+		if err := tagService.Update(utils.CurrentWorkspaceID(c), req.PDFIDs, add, remove); err != nil {
+			return tagError(c, err, "Failed to update tags")
+		}
+
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionPDFBulkTag,
+			TargetType: audit.TargetPDF,
+			After:      audit.Snapshot(fiber.Map{"pdf_ids": req.PDFIDs, "add": add, "remove": remove}),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(dto.BulkTagResponse{
+			Message:      "Tags updated",
+			UpdatedCount: len(req.PDFIDs),
+		})
+	})
+
+	// pdfTagsHandler attaches or detaches the request's tags on one PDF and responds with the PDF
+	pdfTagsHandler := func(attach bool) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_id",
+					"message": "PDF ID must be a number",
+				})
+			}
+
+			var req dto.PDFTagsRequest
+			if err := c.BodyParser(&req); err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_request",
+					"message": "Invalid request body",
+					"details": err.Error(),
+				})
+			}
+
+			names, err := tagNames(req.Tags)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_tag",
+					"message": err.Error(),
+				})
+			}
+			if len(names) == 0 {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_request",
+					"message": "No tags provided",
+				})
+			}
+
+			workspaceID := utils.CurrentWorkspaceID(c)
+			if attach {
+				_, err = tagService.Attach(workspaceID, []uint{uint(id)}, names)
+			} else {
+				err = tagService.Detach(workspaceID, []uint{uint(id)}, names)
+			}
+			if err != nil {
+				return tagError(c, err, "Failed to update tags")
+			}
+
+			var pdf models.PDF
+			if err := scoped(c).Preload("Summaries").Preload("Tags", tags.ByName).First(&pdf, id).Error; err != nil {
+				return tagError(c, err, "Failed to load PDF")
+			}
+
+			action := audit.ActionPDFTag
+			if !attach {
+				action = audit.ActionPDFUntag
+			}
+			if err := recordAudit(c, models.AuditEvent{
+				Action:     action,
+				TargetType: audit.TargetPDF,
+				TargetID:   fmt.Sprint(pdf.ID),
+				After:      audit.Snapshot(fiber.Map{"tags": names}),
+			}); err != nil {
+				return err
+			}
+
+			return c.Status(200).JSON(utils.ConvertPDFToResponse(pdf))
+		}
+	}
+
+	app.Post("/pdf/:id/tags", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), pdfTagsHandler(true))
+	app.Delete("/pdf/:id/tags", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), pdfTagsHandler(false))
+
+	// collectionError responds to errors from the collection service
+	collectionError := func(c *fiber.Ctx, err error, message string) error {
+		switch err {
+		case collections.ErrNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Collection not found",
+			})
+		case collections.ErrParentNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Parent collection not found",
+			})
+		case collections.ErrPDFNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "PDF not found",
+			})
+		case collections.ErrExists:
+			return c.Status(409).JSON(fiber.Map{
+				"error":   "collection_exists",
+				"message": "A collection with that name already exists here",
+			})
+		case collections.ErrCycle:
+			return c.Status(409).JSON(fiber.Map{
+				"error":   "invalid_move",
+				"message": "A collection cannot be moved into itself or one of its subcollections",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "database_error",
+			"message": message,
+			"details": err.Error(),
+		})
+	}
+
+	// collectionResponse adds a collection's counts to its response
+	collectionResponse := func(count collections.Count) dto.CollectionResponse {
+		response := utils.ConvertCollectionToResponse(count.Collection)
+		response.ChildCount = count.ChildCount
+		response.PDFCount = count.PDFCount
+		return response
+	}
+
+	// Lists the top level collections, or those inside parent_id
+	app.Get("/collections", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		var parentID *uint
+		if c.Query("parent_id") != "" {
+			id, err := strconv.ParseUint(c.Query("parent_id"), 10, 64)
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_id",
+					"message": "parent_id must be a number",
+				})
+			}
+			parent := uint(id)
+			parentID = &parent
+		}
+
+		children, err := collectionService.Children(utils.CurrentWorkspaceID(c), parentID)
+		if err != nil {
+			return collectionError(c, err, "Failed to list collections")
+		}
+
+		data := make([]dto.CollectionResponse, len(children))
+		for i, child := range children {
+			data[i] = collectionResponse(child)
+		}
+
+		return c.Status(200).JSON(dto.CollectionListResponse{Data: data})
+	})
+
+	app.Post("/collections", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		var req dto.CollectionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+
+		if err := utils.ValidateCollectionName(req.Name); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_name",
+				"message": err.Error(),
+			})
+		}
+
+		collection, err := collectionService.Create(utils.CurrentWorkspaceID(c), req.Name, req.ParentID)
+		if err != nil {
+			return collectionError(c, err, "Failed to create collection")
+		}
+
+		response := utils.ConvertCollectionToResponse(*collection)
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionCollectionCreate,
+			TargetType: audit.TargetCollection,
+			TargetID:   fmt.Sprint(collection.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(201).JSON(response)
+	})
+
+	app.Get("/collections/:id", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Collection ID must be a number",
+			})
+		}
+
+		workspaceID := utils.CurrentWorkspaceID(c)
+		collection, err := collectionService.Get(workspaceID, uint(id))
+		if err != nil {
+			return collectionError(c, err, "Failed to get collection")
+		}
+
+		crumbs, err := collectionService.Breadcrumbs(workspaceID, uint(id))
+		if err != nil {
+			return collectionError(c, err, "Failed to load breadcrumbs")
+		}
+
+		parentID := uint(id)
+		children, err := collectionService.Children(workspaceID, &parentID)
+		if err != nil {
+			return collectionError(c, err, "Failed to list subcollections")
+		}
+
+		response := dto.CollectionDetailResponse{
+			CollectionResponse: collectionResponse(*collection),
+			Breadcrumbs:        utils.ConvertBreadcrumbsToResponse(crumbs),
+			Children:           make([]dto.CollectionResponse, len(children)),
+		}
+		for i, child := range children {
+			response.Children[i] = collectionResponse(child)
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Patch("/collections/:id", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Collection ID must be a number",
+			})
+		}
+
+		var req dto.CollectionRenameRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+
+		if err := utils.ValidateCollectionName(req.Name); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_name",
+				"message": err.Error(),
+			})
+		}
+
+		collection, err := collectionService.Rename(utils.CurrentWorkspaceID(c), uint(id), req.Name)
+		if err != nil {
+			return collectionError(c, err, "Failed to rename collection")
+		}
+
+		response := utils.ConvertCollectionToResponse(*collection)
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionCollectionRename,
+			TargetType: audit.TargetCollection,
+			TargetID:   fmt.Sprint(collection.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	// Moves a collection with everything in it under another collection, or to the top level
+	app.Post("/collections/:id/move", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Collection ID must be a number",
+			})
+		}
+
+		var req dto.CollectionMoveRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+
+		collection, err := collectionService.Move(utils.CurrentWorkspaceID(c), uint(id), req.ParentID)
+		if err != nil {
+			return collectionError(c, err, "Failed to move collection")
+		}
+
+		response := utils.ConvertCollectionToResponse(*collection)
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionCollectionMove,
+			TargetType: audit.TargetCollection,
+			TargetID:   fmt.Sprint(collection.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	// mode=reparent (default) moves the contents up into the parent, mode=recursive
+	// deletes subcollections too and moves every PDF inside to the trash
+	app.Delete("/collections/:id", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Collection ID must be a number",
+			})
+		}
+
+		mode := c.Query("mode", "reparent")
+		if mode != "reparent" && mode != "recursive" {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "mode must be one of: reparent, recursive",
+			})
+		}
+
+		count, err := collectionService.Delete(utils.CurrentWorkspaceID(c), uint(id), mode == "recursive")
+		if err != nil {
+			return collectionError(c, err, "Failed to delete collection")
+		}
+
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionCollectionDelete,
+			TargetType: audit.TargetCollection,
+			TargetID:   fmt.Sprint(id),
+			After:      audit.Snapshot(fiber.Map{"mode": mode, "pdf_count": count}),
+		}); err != nil {
+			return err
+		}
+
+		message := "Collection deleted, its contents moved to the parent collection"
+		if mode == "recursive" {
+			message = "Collection deleted, its PDFs moved to trash"
+		}
+
+		return c.Status(200).JSON(dto.CollectionDeleteResponse{
+			Message:  message,
+			Mode:     mode,
+			PDFCount: count,
+		})
+	})
+
+	// Lists the PDFs in a collection like GET /pdf. recursive=true includes subcollections.
+	app.Get("/collections/:id/pdfs", utils.RequireScope(auth.ScopePDFRead), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Collection ID must be a number",
+			})
+		}
+
+		collection, err := collectionService.Get(utils.CurrentWorkspaceID(c), uint(id))
+		if err != nil {
+			return collectionError(c, err, "Failed to get collection")
+		}
+
+		query := scoped(c).Model(&models.PDF{}).
+			Scopes(collections.Contains(collection.Collection, c.QueryBool("recursive", false)))
+
+		return listPDFs(c, query)
+	})
+
+	// Files several PDFs in a collection, or takes them out of their collection when collection_id is null
+	app.Post("/pdf/move", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		var req dto.MovePDFsRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+
+		if len(req.PDFIDs) == 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "No PDF IDs provided",
+			})
+		}
+
+		if len(req.PDFIDs) > 100 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Cannot move more than 100 PDFs at once",
+			})
+		}
+
+		if err := collectionService.MovePDFs(utils.CurrentWorkspaceID(c), req.PDFIDs, req.CollectionID); err != nil {
+			return collectionError(c, err, "Failed to move PDFs")
+		}
+
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionPDFMove,
+			TargetType: audit.TargetPDF,
+			After:      audit.Snapshot(fiber.Map{"pdf_ids": req.PDFIDs, "collection_id": req.CollectionID}),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(dto.MovePDFsResponse{
+			Message:    "PDFs moved",
+			MovedCount: len(req.PDFIDs),
+		})
+	})
+
+	app.Get("/trash", utils.RequireScope(auth.ScopePDFRead, auth.ScopeSummaryRead), func(c *fiber.Ctx) error {
+		page, itemsPerPage := utils.ValidatePaginationParams(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 10))
+
+		itemType := c.Query("type", "")
+		if itemType != "" && itemType != trash.TypePDF && itemType != trash.TypeSummary {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Type must be one of: pdf, summary",
+			})
+		}
+
+		items, total, err := trashService.List(utils.CurrentWorkspaceID(c), itemType, itemsPerPage, (page-1)*itemsPerPage)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to list trash",
+				"details": err.Error(),
+			})
+		}
+
+		data := make([]dto.TrashItemResponse, len(items))
+		for i, item := range items {
+			data[i] = dto.TrashItemResponse{
+				Type:      item.Type,
+				ID:        item.ID,
+				PDFID:     item.PDFID,
+				Title:     item.Title,
+				Style:     item.Style,
+				Language:  item.Language,
+				DeletedAt: item.DeletedAt,
+				PurgeAt:   item.DeletedAt.Add(trashService.Retention()),
+			}
+		}
+
+		return c.Status(200).JSON(dto.TrashResponse{
+			Data:         data,
+			Page:         page,
+			ItemsPerPage: itemsPerPage,
+			TotalPages:   int((total + int64(itemsPerPage) - 1) / int64(itemsPerPage)),
+			TotalItems:   total,
+		})
+	})
+
+	app.Post("/pdf/:id/restore", utils.RequireScope(auth.ScopePDFWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "PDF ID must be a number",
+			})
+		}
+
+		pdf, err := trashService.RestorePDF(utils.CurrentWorkspaceID(c), uint(id))
+		if err != nil {
+			if err == trash.ErrNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "PDF not found in trash",
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to restore PDF",
+				"details": err.Error(),
+			})
+		}
+
+		response := utils.ConvertPDFToResponse(*pdf)
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionPDFRestore,
+			TargetType: audit.TargetPDF,
+			TargetID:   fmt.Sprint(pdf.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Post("/summaries/:id/restore", utils.RequireScope(auth.ScopeSummaryWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Summary ID must be a number",
+			})
+		}
+
+		summary, err := trashService.RestoreSummary(utils.CurrentWorkspaceID(c), uint(id))
+		if err != nil {
+			switch err {
+			case trash.ErrNotFound:
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "Summary not found in trash",
+				})
+			case trash.ErrPDFInTrash:
+				return c.Status(409).JSON(fiber.Map{
+					"error":   "pdf_in_trash",
+					"message": err.Error(),
+				})
+			}
 			return c.Status(500).JSON(fiber.Map{
-				"error":   "parse_error",
-				"message": "Failed to parse response",
+				"error":   "database_error",
+				"message": "Failed to restore summary",
 				"details": err.Error(),
 			})
 		}
 
-		// Save summary to database
-		summary := models.Summaries{
-			Style:       pythonResponse.Style,
-			Content:     pythonResponse.Summary.MainSummary,
-			PDFID:       pdf.ID,
-			Language:    pythonResponse.Language,
-			SummaryTime: pythonResponse.ProcessInfo.ProcessingTimeSeconds,
+		response := utils.ConvertSummaryToResponse(*summary)
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionSummaryRestore,
+			TargetType: audit.TargetSummary,
+			TargetID:   fmt.Sprint(summary.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
 		}
 
-		if err := db.Create(&summary).Error; err != nil {
-			fmt.Printf("Failed to save summary: %v\n", err)
-			// Don't return error here as the summary was generated successfully
-		}
-
-		return c.Status(200).JSON(pythonResponse)
+		return c.Status(200).JSON(response)
 	})
 
-	app.Get("/summaries", func(c *fiber.Ctx) error {
+	app.Get("/summaries", utils.RequireScope(auth.ScopeSummaryRead), func(c *fiber.Ctx) error {
 		var summaries []models.Summaries
 
 		// Pagination parameters
@@ -524,18 +3626,32 @@ func main() {
 		// Other query parameters
 		sortBy := c.Query("sort", "created_at")
 		order := c.Query("order", "desc")
-		search := c.Query("search", "")
+		searchText := c.Query("search", "")
 		pdfId := c.QueryInt("pdf", 0)
 		style := c.Query("style", "")
 		language := c.Query("language", "")
+		minWords := c.QueryInt("min_words", 0)
+		maxWords := c.QueryInt("max_words", 0)
+		chunkingUsed := c.Query("chunking_used", "")
+		tagNames := tags.ParseNames(c.Query("tags", ""))
+		tagMode := c.Query("tag_mode", tags.ModeAll)
+
+		if tagMode != tags.ModeAll && tagMode != tags.ModeAny {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "tag_mode must be one of: all, any",
+			})
+		}
 
 		// Validate sort parameters
 		validSortFields := map[string]bool{
-			"created_at":   true,
-			"updated_at":   true,
-			"style":        true,
-			"language":     true,
-			"summary_time": true,
+			"created_at":       true,
+			"updated_at":       true,
+			"style":            true,
+			"language":         true,
+			"summary_time":     true,
+			"word_count":       true,
+			"chunks_processed": true,
 		}
 		if !validSortFields[sortBy] {
 			sortBy = "created_at"
@@ -544,11 +3660,11 @@ func main() {
 			order = "desc"
 		}
 
-		query := db.Model(&models.Summaries{})
+		query := scoped(c).Model(&models.Summaries{})
 
 		// Apply filters
-		if search != "" {
-			query = query.Where("content ILIKE ? OR style ILIKE ?", "%"+search+"%", "%"+search+"%")
+		if searchText != "" {
+			query = query.Where(search.SummaryCondition("summaries"), map[string]interface{}{"q": searchText})
 		}
 
 		if pdfId != 0 {
@@ -563,6 +3679,23 @@ func main() {
 			query = query.Where("language ILIKE ?", "%"+language+"%")
 		}
 
+		if minWords > 0 {
+			query = query.Where("word_count >= ?", minWords)
+		}
+
+		if maxWords > 0 {
+			query = query.Where("word_count <= ?", maxWords)
+		}
+
+		if chunkingUsed == "true" || chunkingUsed == "false" {
+			query = query.Where("chunking_used = ?", chunkingUsed == "true")
+		}
+
+		// Summaries are tagged through their PDF
+		if len(tagNames) > 0 {
+			query = query.Scopes(tags.Filter("summaries.pdf_id", tagNames, tagMode))
+		}
+
 		// Get total count for pagination
 		var totalCount int64
 		if err := query.Count(&totalCount).Error; err != nil {
@@ -596,10 +3729,10 @@ func main() {
 		return c.Status(200).JSON(response)
 	})
 
-	app.Get("/summaries/count", func(c *fiber.Ctx) error {
+	app.Get("/summaries/count", utils.RequireScope(auth.ScopeSummaryRead), func(c *fiber.Ctx) error {
 		var count int64
 
-		if err := db.Model(&models.Summaries{}).Count(&count).Error; err != nil {
+		if err := scoped(c).Model(&models.Summaries{}).Count(&count).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"message": "Failed to count summaries: " + err.Error(),
 			})
@@ -612,53 +3745,8 @@ func main() {
 		return c.Status(200).JSON(response)
 	})
 
-	app.Get("/summaries/:id", func(c *fiber.Ctx) error {
-		var summary models.Summaries
-
-		if err := db.Preload("PDF").First(&summary, c.Params("id")).Error; err != nil {
-			return c.Status(404).JSON(fiber.Map{
-				"message": "Summary not found",
-			})
-		}
-
-		response := utils.ConvertSummaryToResponse(summary)
-		return c.Status(200).JSON(response)
-	})
-
-	app.Delete("/summaries/:id", func(c *fiber.Ctx) error {
-		id := c.Params("id")
-
-		var summary models.Summaries
-
-		if err := db.First(&summary, id).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return c.Status(404).JSON(fiber.Map{
-					"error":   "not_found",
-					"message": "Summary not found",
-				})
-			}
-			return c.Status(500).JSON(fiber.Map{
-				"error":   "database_error",
-				"message": "Failed to find summary",
-				"details": err.Error(),
-			})
-		}
-
-		if err := db.Unscoped().Delete(&summary).Error; err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error":   "database_error",
-				"message": "Failed to delete summary",
-				"details": err.Error(),
-			})
-		}
-
-		return c.Status(200).JSON(fiber.Map{
-			"message": "Summary deleted successfully",
-		})
-	})
-
-	// Bulk delete summaries
-	app.Delete("/summaries/bulk", func(c *fiber.Ctx) error {
+	// Bulk delete summaries. Registered before "/summaries/:id" so "bulk" isn't taken as an ID.
+	app.Delete("/summaries/bulk", utils.RequireScope(auth.ScopeSummaryWrite), utils.RequireRole(models.RoleOwner), func(c *fiber.Ctx) error {
 		var req struct {
 			IDs []uint `json:"ids" binding:"required"`
 		}
@@ -685,23 +3773,65 @@ func main() {
 			})
 		}
 
-		result := db.Where("id IN ?", req.IDs).Delete(&models.Summaries{})
-		if result.Error != nil {
+		permanent := c.QueryBool("permanent", false)
+		query := scoped(c)
+		if permanent {
+			query = query.Unscoped()
+		}
+
+		var summaries []models.Summaries
+		if err := query.Where("id IN ?", req.IDs).Find(&summaries).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
-				"message": "Failed to delete summaries",
-				"details": result.Error.Error(),
+				"message": "Failed to find summaries",
+				"details": err.Error(),
+			})
+		}
+
+		// Go through the trash service like single deletes so both clean up the same way
+		var deleteErr error
+		removed := make([]dto.SummaryResponse, 0, len(summaries))
+		for i := range summaries {
+			before := utils.ConvertSummaryToResponse(summaries[i])
+			if permanent {
+				deleteErr = trashService.PurgeSummary(&summaries[i])
+			} else {
+				deleteErr = trashService.TrashSummary(&summaries[i])
+			}
+			if deleteErr != nil {
+				break
+			}
+			removed = append(removed, before)
+		}
+		deleted := len(removed)
+
+		// Summaries deleted before a failure are recorded as well
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionSummaryBulkDelete,
+			TargetType: audit.TargetSummary,
+			Before:     audit.Snapshot(fiber.Map{"summaries": removed}),
+			After:      audit.Snapshot(fiber.Map{"permanent": permanent, "deleted_count": deleted}),
+		}); err != nil {
+			return err
+		}
+
+		if deleteErr != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":         "database_error",
+				"message":       "Failed to delete summaries",
+				"details":       deleteErr.Error(),
+				"deleted_count": deleted,
 			})
 		}
 
 		return c.Status(200).JSON(fiber.Map{
-			"message":       fmt.Sprintf("Successfully deleted %d summaries", result.RowsAffected),
-			"deleted_count": result.RowsAffected,
+			"message":       fmt.Sprintf("Successfully deleted %d summaries", deleted),
+			"deleted_count": deleted,
 		})
 	})
 
 	// Get summary statistics
-	app.Get("/summaries/stats", func(c *fiber.Ctx) error {
+	app.Get("/summaries/stats", utils.RequireScope(auth.ScopeSummaryRead), func(c *fiber.Ctx) error {
 		var stats struct {
 			TotalSummaries int64            `json:"total_summaries"`
 			ByStyle        map[string]int64 `json:"by_style"`
@@ -711,7 +3841,7 @@ func main() {
 		}
 
 		// Get total summaries
-		if err := db.Model(&models.Summaries{}).Count(&stats.TotalSummaries).Error; err != nil {
+		if err := scoped(c).Model(&models.Summaries{}).Count(&stats.TotalSummaries).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
 				"message": "Failed to get summary statistics",
@@ -724,7 +3854,7 @@ func main() {
 			Style string `json:"style"`
 			Count int64  `json:"count"`
 		}
-		if err := db.Model(&models.Summaries{}).Select("style, COUNT(*) as count").Group("style").Find(&styleStats).Error; err != nil {
+		if err := scoped(c).Model(&models.Summaries{}).Select("style, COUNT(*) as count").Group("style").Find(&styleStats).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
 				"message": "Failed to get style statistics",
@@ -742,7 +3872,7 @@ func main() {
 			Language string `json:"language"`
 			Count    int64  `json:"count"`
 		}
-		if err := db.Model(&models.Summaries{}).Select("language, COUNT(*) as count").Group("language").Find(&languageStats).Error; err != nil {
+		if err := scoped(c).Model(&models.Summaries{}).Select("language, COUNT(*) as count").Group("language").Find(&languageStats).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
 				"message": "Failed to get language statistics",
@@ -757,7 +3887,7 @@ func main() {
 
 		// Get average summary time
 		var avgTime sql.NullFloat64
-		if err := db.Model(&models.Summaries{}).Select("AVG(summary_time)").Scan(&avgTime).Error; err != nil {
+		if err := scoped(c).Model(&models.Summaries{}).Select("AVG(summary_time)").Scan(&avgTime).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
 				"message": "Failed to get average summary time",
@@ -769,7 +3899,7 @@ func main() {
 		}
 
 		// Get total PDFs
-		if err := db.Model(&models.PDF{}).Count(&stats.TotalPDFs).Error; err != nil {
+		if err := scoped(c).Model(&models.PDF{}).Count(&stats.TotalPDFs).Error; err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "database_error",
 				"message": "Failed to get PDF count",
@@ -780,5 +3910,364 @@ func main() {
 		return c.Status(200).JSON(stats)
 	})
 
-	app.Listen("0.0.0.0:8080")
+	app.Get("/summaries/:id", utils.RequireScope(auth.ScopeSummaryRead), func(c *fiber.Ctx) error {
+		var summary models.Summaries
+
+		if err := scoped(c).Preload("PDF").First(&summary, c.Params("id")).Error; err != nil {
+			return c.Status(404).JSON(fiber.Map{
+				"message": "Summary not found",
+			})
+		}
+
+		response := utils.ConvertSummaryToResponse(summary)
+		return c.Status(200).JSON(response)
+	})
+
+	// summaryRevisionError responds to errors from the revision service
+	summaryRevisionError := func(c *fiber.Ctx, err error, message string) error {
+		switch err {
+		case revisions.ErrNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Summary not found",
+			})
+		case revisions.ErrRevisionNotFound:
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "revision_not_found",
+				"message": "Revision not found",
+			})
+		case revisions.ErrUnchanged:
+			return c.Status(409).JSON(fiber.Map{
+				"error":   "unchanged",
+				"message": "The content is the same as the current content",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "database_error",
+			"message": message,
+			"details": err.Error(),
+		})
+	}
+
+	app.Patch("/summaries/:id", utils.RequireScope(auth.ScopeSummaryWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Summary ID must be a number",
+			})
+		}
+
+		var req dto.SummaryUpdateRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+
+		if err := utils.ValidateSummaryContent(req.Content); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_content",
+				"message": err.Error(),
+			})
+		}
+
+		summary, revision, err := revisionService.Edit(utils.CurrentWorkspaceID(c), uint(id), utils.CurrentUserID(c), req.Content, req.Note)
+		if err != nil {
+			return summaryRevisionError(c, err, "Failed to update summary")
+		}
+
+		// The previous content stays in the summary's revisions
+		response := dto.SummaryEditResponse{
+			Summary:  utils.ConvertSummaryToResponse(*summary),
+			Revision: utils.ConvertSummaryRevisionToResponse(*revision),
+		}
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionSummaryUpdate,
+			TargetType: audit.TargetSummary,
+			TargetID:   fmt.Sprint(summary.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Get("/summaries/:id/revisions", utils.RequireScope(auth.ScopeSummaryRead), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Summary ID must be a number",
+			})
+		}
+
+		page, itemsPerPage := utils.ValidatePaginationParams(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 10))
+
+		history, total, err := revisionService.List(utils.CurrentWorkspaceID(c), uint(id), itemsPerPage, (page-1)*itemsPerPage)
+		if err != nil {
+			return summaryRevisionError(c, err, "Failed to list revisions")
+		}
+
+		return c.Status(200).JSON(dto.SummaryRevisionListResponse{
+			Data:         utils.ConvertSummaryRevisionsToResponse(history),
+			Page:         page,
+			ItemsPerPage: itemsPerPage,
+			TotalPages:   int((total + int64(itemsPerPage) - 1) / int64(itemsPerPage)),
+			TotalItems:   total,
+		})
+	})
+
+	app.Post("/summaries/:id/revisions/:revision/revert", utils.RequireScope(auth.ScopeSummaryWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_id",
+				"message": "Summary ID must be a number",
+			})
+		}
+
+		number, err := strconv.Atoi(c.Params("revision"))
+		if err != nil || number < 1 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_revision",
+				"message": "Revision must be a positive number",
+			})
+		}
+
+		summary, revision, err := revisionService.Revert(utils.CurrentWorkspaceID(c), uint(id), number, utils.CurrentUserID(c))
+		if err != nil {
+			return summaryRevisionError(c, err, "Failed to revert summary")
+		}
+
+		// The previous content stays in the summary's revisions
+		response := dto.SummaryEditResponse{
+			Summary:  utils.ConvertSummaryToResponse(*summary),
+			Revision: utils.ConvertSummaryRevisionToResponse(*revision),
+		}
+		if err := recordAudit(c, models.AuditEvent{
+			Action:     audit.ActionSummaryRevert,
+			TargetType: audit.TargetSummary,
+			TargetID:   fmt.Sprint(summary.ID),
+			After:      audit.Snapshot(response),
+		}); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(response)
+	})
+
+	app.Delete("/summaries/:id", utils.RequireScope(auth.ScopeSummaryWrite), utils.RequireRole(models.RoleEditor), func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		permanent := c.QueryBool("permanent", false)
+
+		var summary models.Summaries
+
+		// Permanent deletes also reach summaries already in the trash
+		query := scoped(c)
+		if permanent {
+			query = query.Unscoped()
+		}
+
+		if err := query.First(&summary, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return c.Status(404).JSON(fiber.Map{
+					"error":   "not_found",
+					"message": "Summary not found",
+				})
+			}
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to find summary",
+				"details": err.Error(),
+			})
+		}
+
+		event := models.AuditEvent{
+			Action:     audit.ActionSummaryDelete,
+			TargetType: audit.TargetSummary,
+			TargetID:   fmt.Sprint(summary.ID),
+			Before:     audit.Snapshot(utils.ConvertSummaryToResponse(summary)),
+			After:      models.JSONMap{"permanent": permanent},
+		}
+
+		if permanent {
+			if err := trashService.PurgeSummary(&summary); err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "database_error",
+					"message": "Failed to delete summary",
+					"details": err.Error(),
+				})
+			}
+
+			if err := recordAudit(c, event); err != nil {
+				return err
+			}
+
+			return c.Status(200).JSON(fiber.Map{
+				"message": "Summary deleted permanently",
+			})
+		}
+
+		if err := trashService.TrashSummary(&summary); err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to delete summary",
+				"details": err.Error(),
+			})
+		}
+		if err := recordAudit(c, event); err != nil {
+			return err
+		}
+
+		return c.Status(200).JSON(fiber.Map{
+			"message":  "Summary moved to trash",
+			"purge_at": summary.DeletedAt.Time.Add(trashService.Retention()),
+		})
+	})
+
+	// auditFilter reads the actor_id, action, target_type, target_id, from and to query parameters
+	auditFilter := func(c *fiber.Ctx) (audit.Filter, error) {
+		filter := audit.Filter{
+			TargetType: c.Query("target_type"),
+			TargetID:   c.Query("target_id"),
+		}
+		if raw := c.Query("actor_id"); raw != "" {
+			id, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				return filter, errors.New("actor_id must be a number")
+			}
+			filter.ActorID = uint(id)
+		}
+		if raw := c.Query("action"); raw != "" {
+			for _, action := range strings.Split(raw, ",") {
+				if action = strings.TrimSpace(action); action != "" {
+					filter.Actions = append(filter.Actions, action)
+				}
+			}
+		}
+		for _, bound := range []struct {
+			name string
+			dest *time.Time
+		}{{"from", &filter.From}, {"to", &filter.To}} {
+			if raw := c.Query(bound.name); raw != "" {
+				t, err := time.Parse(time.RFC3339, raw)
+				if err != nil {
+					return filter, fmt.Errorf("%s must be an RFC 3339 time, e.g. 2024-01-31T15:04:05Z", bound.name)
+				}
+				*bound.dest = t
+			}
+		}
+		return filter, nil
+	}
+
+	// listAudit responds with one page of a workspace's events, or of the caller's account events for workspace 0
+	listAudit := func(c *fiber.Ctx, workspaceID uint, filter audit.Filter) error {
+		page, itemsPerPage := utils.ValidatePaginationParams(c.QueryInt("page", 1), c.QueryInt("itemsperpage", 10))
+
+		events, total, err := auditService.List(workspaceID, filter, page, itemsPerPage)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to fetch audit events",
+				"details": err.Error(),
+			})
+		}
+
+		data := make([]dto.AuditEventResponse, len(events))
+		for i, event := range events {
+			data[i] = utils.ConvertAuditEventToResponse(event)
+		}
+
+		return c.Status(200).JSON(dto.AuditEventListResponse{
+			Data:         data,
+			Page:         page,
+			ItemsPerPage: itemsPerPage,
+			TotalPages:   int((total + int64(itemsPerPage) - 1) / int64(itemsPerPage)),
+			TotalItems:   total,
+		})
+	}
+
+	app.Get("/audit", utils.RequireScope(auth.ScopeAdmin), utils.RequireRole(models.RoleOwner), func(c *fiber.Ctx) error {
+		filter, err := auditFilter(c)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+
+		return listAudit(c, utils.CurrentWorkspaceID(c), filter)
+	})
+
+	// Streams every matching event, oldest first, as JSON Lines
+	app.Get("/audit/export", utils.RequireScope(auth.ScopeAdmin), utils.RequireRole(models.RoleOwner), func(c *fiber.Ctx) error {
+		filter, err := auditFilter(c)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+
+		workspaceID := utils.CurrentWorkspaceID(c)
+		filename := fmt.Sprintf("audit-workspace-%d-%s.jsonl", workspaceID, time.Now().UTC().Format("20060102-150405"))
+		c.Set("Content-Type", "application/x-ndjson")
+		c.Set("Content-Disposition", download.ContentDisposition("attachment", filename))
+
+		// The status is sent before the first event is read, so a failure part way
+		// through can only be logged and leaves the file cut short
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			encoder := json.NewEncoder(w)
+			err := auditService.Export(workspaceID, filter, func(event models.AuditEvent) error {
+				return encoder.Encode(utils.ConvertAuditEventToResponse(event))
+			})
+			if err == nil {
+				err = w.Flush()
+			}
+			if err != nil {
+				fmt.Printf("Failed to export audit events of workspace %d: %v\n", workspaceID, err)
+			}
+		})
+		return nil
+	})
+
+	// The caller's own account events, such as API key changes, which belong to no workspace
+	app.Get("/auth/me/audit", utils.RequireScope(auth.ScopeAdmin), func(c *fiber.Ctx) error {
+		filter, err := auditFilter(c)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": err.Error(),
+			})
+		}
+
+		filter.ActorID = utils.CurrentUserID(c)
+		return listAudit(c, 0, filter)
+	})
+
+	// Stop taking requests on a signal and give those in flight time to finish
+	go func() {
+		<-ctx.Done()
+		fmt.Println("Shutting down...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), utils.GetEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second))
+		defer cancel()
+		if err := app.ShutdownWithContext(shutdownCtx); err != nil {
+			fmt.Printf("Failed to finish in-flight requests: %v\n", err)
+		}
+	}()
+
+	if err := app.Listen("0.0.0.0:8080"); err != nil {
+		fmt.Printf("Server stopped: %v\n", err)
+	}
+
+	// Jobs cut short go back to the queue for the next start
+	stop()
+	summaryJobs.Wait()
+	extractor.Wait()
+	trashService.Wait()
+	uploadService.Wait()
 }
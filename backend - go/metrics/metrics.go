@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Collectors register themselves with the default registry, which also
+// carries the Go runtime and process metrics
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route template and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	UploadBytes = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pdf_upload_bytes_total",
+		Help: "Bytes of PDF files accepted through uploads and file replacements.",
+	})
+
+	PageCount = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "pdf_page_count",
+		Help:    "Page counts of uploaded PDFs.",
+		Buckets: []float64{1, 2, 5, 10, 20, 50, 100, 200, 500, 1000},
+	})
+
+	SummarizerRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "summarizer_requests_total",
+		Help: "Summarizer provider calls by provider, style, language and outcome.",
+	}, []string{"provider", "style", "language", "outcome"})
+
+	SummarizerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "summarizer_request_duration_seconds",
+		Help:    "Latency of summarizer provider calls by provider, style, language and outcome.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{"provider", "style", "language", "outcome"})
+
+	RateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limit_rejections_total",
+		Help: "Requests refused with 429 by the limit that ran out: ip, user, key or route.",
+	}, []string{"limit"})
+)
+
+// Summarizer call outcomes
+const (
+	OutcomeSuccess     = "success"
+	OutcomeError       = "error"
+	OutcomeTimeout     = "timeout"
+	OutcomeCircuitOpen = "circuit_open"
+	OutcomeCanceled    = "canceled" // by the caller, e.g. on shutdown
+)
+
+// ObserveUpload counts an accepted PDF file
+func ObserveUpload(size int64, pages int) {
+	UploadBytes.Add(float64(size))
+	PageCount.Observe(float64(pages))
+}
+
+// RegisterQueue exposes the depth of a job queue as job_queue_depth{queue=name}.
+// depth is called on every scrape.
+func RegisterQueue(name string, depth func() (int64, error)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "job_queue_depth",
+		Help:        "Jobs waiting to be picked up by a worker.",
+		ConstLabels: prometheus.Labels{"queue": name},
+	}, func() float64 {
+		n, err := depth()
+		if err != nil {
+			fmt.Printf("Failed to read depth of %s queue: %v\n", name, err)
+			return math.NaN()
+		}
+		return float64(n)
+	})
+}
+
+// RegisterDB exposes the connection pool stats of db as go_sql_* metrics
+func RegisterDB(db *sql.DB, name string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Middleware counts and times every request by its route template, such as
+// /pdf/:id, so IDs don't each become a series. Requests answered by
+// middleware before reaching a route, such as 401s and 429s, are labelled
+// with that middleware's path, usually "/". Register it first.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		err := c.Next()
+
+		// Errors are only turned into responses after the middleware chain returns
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var e *fiber.Error
+			if errors.As(err, &e) {
+				status = e.Code
+			}
+		}
+
+		labels := []string{c.Method(), c.Route().Path, strconv.Itoa(status)}
+		HTTPRequests.WithLabelValues(labels...).Inc()
+		HTTPDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// Handler serves the metrics in the Prometheus text format. With a token,
+// scrapers must send it as "Authorization: Bearer <token>". Without one the
+// metrics are only served when public is set, and answer 404 otherwise.
+func Handler(token string, public bool) fiber.Handler {
+	serve := adaptor.HTTPHandler(promhttp.Handler())
+
+	if token == "" && !public {
+		return func(c *fiber.Ctx) error {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "not_found",
+				"message": "Metrics are disabled, set METRICS_TOKEN to enable them",
+			})
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		if token != "" {
+			given, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				return c.Status(401).JSON(fiber.Map{
+					"error":   "unauthorized",
+					"message": "A valid metrics token is required",
+				})
+			}
+		}
+		return serve(c)
+	}
+}
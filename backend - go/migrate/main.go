@@ -0,0 +1,106 @@
+package main
+
+import (
+	"backend-go/migrations"
+	"fmt"
+	"os"
+	"strconv"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const usage = `Usage: migrate [command]
+
+Commands:
+  up            Apply all pending migrations (default)
+  down          Roll back the most recently applied migration
+  status        List migrations and whether they are applied
+  to <version>  Migrate up or down to the given version (0 rolls back everything)
+`
+
+func main() {
+	command := "up"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	var target int64
+	switch command {
+	case "up", "down", "status":
+		if len(os.Args) > 2 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+	case "to":
+		if len(os.Args) != 3 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		version, err := strconv.ParseInt(os.Args[2], 10, 64)
+		if err != nil || version < 0 {
+			fmt.Fprintf(os.Stderr, "Invalid version %q\n\n%s", os.Args[2], usage)
+			os.Exit(2)
+		}
+		target = version
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		dsn = "host=localhost user=postgres password=postgres dbname=ai_pdf_management port=5432 sslmode=disable TimeZone=Asia/Shanghai"
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		panic("Failed to connect to database: " + err.Error())
+	}
+
+	println("Connected to database successfully!")
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		panic("Failed to load migrations: " + err.Error())
+	}
+
+	switch command {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down()
+	case "to":
+		err = migrator.To(target)
+	case "status":
+		err = printStatus(migrator)
+	}
+	if err != nil {
+		panic("Migration failed: " + err.Error())
+	}
+
+	if command != "status" {
+		println("Migration completed successfully!")
+	}
+}
+
+func printStatus(migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-8s %-32s %s\n", "VERSION", "NAME", "APPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		if status.Missing {
+			appliedAt += " (no migration file)"
+		}
+		fmt.Printf("%04d     %-32s %s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return nil
+}
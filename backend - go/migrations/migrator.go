@@ -0,0 +1,264 @@
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockKey identifies the advisory lock held while migrating, so replicas
+// starting at the same time apply each migration exactly once
+const lockKey = 7283946150
+
+// Status reports whether a migration has been applied
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Missing is set for versions recorded in the database that have no file
+	Missing bool
+}
+
+// appliedMigration is a row of schema_migrations
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies and rolls back the embedded migrations
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New loads the embedded migrations
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the highest known version, or 0 when there are no migrations
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down() error {
+	return m.locked(func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		var current int64
+		for version := range applied {
+			if version > current {
+				current = version
+			}
+		}
+		if current == 0 {
+			fmt.Println("No migrations to roll back")
+			return nil
+		}
+
+		migration, ok := m.find(current)
+		if !ok {
+			return fmt.Errorf("applied migration %d has no file", current)
+		}
+		return m.rollback(conn, migration)
+	})
+}
+
+// To applies or rolls back migrations until version is the latest applied one.
+// Version 0 rolls back everything.
+func (m *Migrator) To(version int64) error {
+	if _, ok := m.find(version); !ok && version != 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.locked(func(conn *gorm.DB) error {
+		applied, err := m.applied(conn)
+		if err != nil {
+			return err
+		}
+
+		for v := range applied {
+			if _, ok := m.find(v); !ok && v > version {
+				return fmt.Errorf("applied migration %d has no file", v)
+			}
+		}
+
+		// Roll back newest first, then apply oldest first
+		count := 0
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := m.rollback(conn, migration); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := m.apply(conn, migration); err != nil {
+					return err
+				}
+				count++
+			}
+		}
+
+		if count == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return nil
+	})
+}
+
+// Status lists every known migration and any applied version without a file
+func (m *Migrator) Status() ([]Status, error) {
+	applied := map[int64]appliedMigration{}
+	if m.db.Migrator().HasTable(&appliedMigration{}) {
+		var err error
+		if applied, err = m.applied(m.db); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, row := range applied {
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, Status{
+			Version:   row.Version,
+			Name:      row.Name,
+			AppliedAt: &appliedAt,
+			Missing:   true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// locked runs fn on a single connection holding the migration advisory lock.
+// Session-level advisory locks belong to one connection, so the pool can't be used directly.
+func (m *Migrator) locked(fn func(conn *gorm.DB) error) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+
+		if err := m.ensureTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) ensureTable(conn *gorm.DB) error {
+	if err := conn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied(conn *gorm.DB) (map[int64]appliedMigration, error) {
+	var rows []appliedMigration
+	if err := conn.Order("version asc").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	applied := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// apply runs an up migration and records it in the same transaction
+func (m *Migrator) apply(conn *gorm.DB, migration Migration) error {
+	fmt.Printf("Applying %04d_%s...\n", migration.Version, migration.Name)
+
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Up).Error; err != nil {
+			return err
+		}
+		return tx.Create(&appliedMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// rollback runs a down migration and forgets it in the same transaction
+func (m *Migrator) rollback(conn *gorm.DB, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("migration %04d_%s has no down file", migration.Version, migration.Name)
+	}
+
+	fmt.Printf("Rolling back %04d_%s...\n", migration.Version, migration.Name)
+
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(migration.Down).Error; err != nil {
+			return err
+		}
+		return tx.Where("version = ?", migration.Version).Delete(&appliedMigration{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("rollback of %04d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
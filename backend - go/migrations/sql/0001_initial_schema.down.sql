@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS summaries;
+DROP TABLE IF EXISTS pdfs;
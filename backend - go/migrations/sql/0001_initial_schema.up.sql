@@ -0,0 +1,38 @@
+-- The first migrations use IF NOT EXISTS so databases created by the old
+-- AutoMigrate tool are adopted without changes.
+
+CREATE TABLE IF NOT EXISTS pdfs (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    filename text NOT NULL,
+    file_size bigint NOT NULL,
+    title text NOT NULL,
+    page_count bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_pdfs_deleted_at ON pdfs (deleted_at);
+
+CREATE TABLE IF NOT EXISTS summaries (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    style text NOT NULL,
+    content text NOT NULL,
+    pdf_id bigint NOT NULL,
+    language text NOT NULL,
+    summary_time decimal NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_summaries_deleted_at ON summaries (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_summaries_pdf_id ON summaries (pdf_id);
+
+-- AutoMigrate named this constraint fk_pdfs_summaries
+ALTER TABLE summaries DROP CONSTRAINT IF EXISTS fk_pdfs_summaries;
+ALTER TABLE summaries DROP CONSTRAINT IF EXISTS fk_summaries_pdf;
+ALTER TABLE summaries
+    ADD CONSTRAINT fk_summaries_pdf
+    FOREIGN KEY (pdf_id) REFERENCES pdfs (id)
+    ON UPDATE CASCADE ON DELETE CASCADE;
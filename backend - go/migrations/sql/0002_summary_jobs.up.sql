@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS summary_jobs (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    pdf_id bigint NOT NULL,
+    style text NOT NULL,
+    language text NOT NULL,
+    status text NOT NULL DEFAULT 'queued',
+    attempts bigint NOT NULL DEFAULT 0,
+    max_attempts bigint NOT NULL DEFAULT 3,
+    force boolean NOT NULL DEFAULT false,
+    cached boolean NOT NULL DEFAULT false,
+    error text,
+    summary_id bigint,
+    started_at timestamptz,
+    finished_at timestamptz,
+    CONSTRAINT fk_summary_jobs_pdf FOREIGN KEY (pdf_id) REFERENCES pdfs (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_summary_jobs_summary FOREIGN KEY (summary_id) REFERENCES summaries (id)
+        ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_summary_jobs_deleted_at ON summary_jobs (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_summary_jobs_pdf_id ON summary_jobs (pdf_id);
+CREATE INDEX IF NOT EXISTS idx_summary_jobs_status ON summary_jobs (status);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS blobs (
+    hash varchar(64) PRIMARY KEY,
+    size bigint NOT NULL,
+    ref_count bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+ALTER TABLE pdfs ADD COLUMN IF NOT EXISTS content_hash varchar(64);
+
+CREATE INDEX IF NOT EXISTS idx_pdfs_content_hash ON pdfs (content_hash);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_summary_cache;
+ALTER TABLE summaries DROP COLUMN IF EXISTS prompt_version;
+ALTER TABLE summaries DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,5 @@
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS content_hash varchar(64);
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS prompt_version text;
+
+CREATE INDEX IF NOT EXISTS idx_summary_cache
+    ON summaries (content_hash, style, language, prompt_version);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_summaries_chunking_used;
+DROP INDEX IF EXISTS idx_summaries_word_count;
+
+ALTER TABLE summaries DROP COLUMN IF EXISTS text_statistics;
+ALTER TABLE summaries DROP COLUMN IF EXISTS source_file_size;
+ALTER TABLE summaries DROP COLUMN IF EXISTS original_filename;
+ALTER TABLE summaries DROP COLUMN IF EXISTS chunking_used;
+ALTER TABLE summaries DROP COLUMN IF EXISTS chunks_processed;
+ALTER TABLE summaries DROP COLUMN IF EXISTS reading_time;
+ALTER TABLE summaries DROP COLUMN IF EXISTS word_count;
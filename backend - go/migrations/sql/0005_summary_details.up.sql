@@ -0,0 +1,10 @@
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS word_count bigint NOT NULL DEFAULT 0;
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS reading_time text;
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS chunks_processed bigint NOT NULL DEFAULT 0;
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS chunking_used boolean NOT NULL DEFAULT false;
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS original_filename text;
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS source_file_size bigint NOT NULL DEFAULT 0;
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS text_statistics jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_summaries_word_count ON summaries (word_count);
+CREATE INDEX IF NOT EXISTS idx_summaries_chunking_used ON summaries (chunking_used);
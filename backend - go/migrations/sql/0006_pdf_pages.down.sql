@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS pdf_pages;
+
+DROP INDEX IF EXISTS idx_pdfs_text_status;
+ALTER TABLE pdfs DROP COLUMN IF EXISTS text_status;
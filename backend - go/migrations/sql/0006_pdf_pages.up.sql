@@ -0,0 +1,16 @@
+ALTER TABLE pdfs ADD COLUMN IF NOT EXISTS text_status text NOT NULL DEFAULT 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_pdfs_text_status ON pdfs (text_status);
+
+CREATE TABLE IF NOT EXISTS pdf_pages (
+    id bigserial PRIMARY KEY,
+    pdf_id bigint NOT NULL,
+    page_number bigint NOT NULL,
+    text text NOT NULL,
+    char_count bigint NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_pdf_pages_pdf FOREIGN KEY (pdf_id) REFERENCES pdfs (id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pdf_pages_pdf_page ON pdf_pages (pdf_id, page_number);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_summaries_search_vector;
+ALTER TABLE summaries DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_pdf_pages_search_vector;
+ALTER TABLE pdf_pages DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_pdfs_search_vector;
+ALTER TABLE pdfs DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,27 @@
+-- Search vectors are generated by PostgreSQL and indexed with GIN.
+-- Summary configurations follow the summary language; keep in sync with the search package.
+
+ALTER TABLE pdfs
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', coalesce(title, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_pdfs_search_vector ON pdfs USING GIN (search_vector);
+
+ALTER TABLE pdf_pages
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', coalesce(text, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_pdf_pages_search_vector ON pdf_pages USING GIN (search_vector);
+
+ALTER TABLE summaries
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector(
+        CASE language
+            WHEN 'english' THEN 'english'::regconfig
+            WHEN 'indonesian' THEN 'indonesian'::regconfig
+            ELSE 'simple'::regconfig
+        END,
+        coalesce(content, '')
+    )) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_summaries_search_vector ON summaries USING GIN (search_vector);
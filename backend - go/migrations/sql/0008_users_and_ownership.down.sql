@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_summaries_owner_id;
+ALTER TABLE summaries DROP CONSTRAINT IF EXISTS fk_summaries_owner;
+ALTER TABLE summaries DROP COLUMN IF EXISTS owner_id;
+
+DROP INDEX IF EXISTS idx_pdfs_owner_id;
+ALTER TABLE pdfs DROP CONSTRAINT IF EXISTS fk_pdfs_owner;
+ALTER TABLE pdfs DROP COLUMN IF EXISTS owner_id;
+
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
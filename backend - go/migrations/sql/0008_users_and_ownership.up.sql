@@ -0,0 +1,41 @@
+CREATE TABLE users (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    email text NOT NULL,
+    name text,
+    password_hash text NOT NULL
+);
+
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+CREATE UNIQUE INDEX idx_users_email ON users (email);
+
+CREATE TABLE refresh_tokens (
+    id bigserial PRIMARY KEY,
+    token_id varchar(64) NOT NULL,
+    user_id bigint NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    created_at timestamptz,
+    CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_refresh_tokens_token_id ON refresh_tokens (token_id);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+
+-- Existing documents have no owner until the first registered user adopts them
+ALTER TABLE pdfs ADD COLUMN owner_id bigint;
+ALTER TABLE pdfs
+    ADD CONSTRAINT fk_pdfs_owner
+    FOREIGN KEY (owner_id) REFERENCES users (id)
+    ON UPDATE CASCADE;
+CREATE INDEX idx_pdfs_owner_id ON pdfs (owner_id);
+
+ALTER TABLE summaries ADD COLUMN owner_id bigint;
+ALTER TABLE summaries
+    ADD CONSTRAINT fk_summaries_owner
+    FOREIGN KEY (owner_id) REFERENCES users (id)
+    ON UPDATE CASCADE;
+CREATE INDEX idx_summaries_owner_id ON summaries (owner_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_summaries_workspace_id;
+ALTER TABLE summaries DROP CONSTRAINT IF EXISTS fk_summaries_workspace;
+ALTER TABLE summaries DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_pdfs_workspace_id;
+ALTER TABLE pdfs DROP CONSTRAINT IF EXISTS fk_pdfs_workspace;
+ALTER TABLE pdfs DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS workspaces;
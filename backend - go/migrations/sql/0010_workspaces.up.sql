@@ -0,0 +1,63 @@
+CREATE TABLE workspaces (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name text NOT NULL,
+    created_by_id bigint,
+    CONSTRAINT fk_workspaces_created_by FOREIGN KEY (created_by_id) REFERENCES users (id)
+        ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+CREATE INDEX idx_workspaces_deleted_at ON workspaces (deleted_at);
+CREATE INDEX idx_workspaces_created_by_id ON workspaces (created_by_id);
+
+CREATE TABLE memberships (
+    id bigserial PRIMARY KEY,
+    workspace_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    role varchar(16) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_memberships_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_memberships_user FOREIGN KEY (user_id) REFERENCES users (id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_memberships_workspace_user ON memberships (workspace_id, user_id);
+CREATE INDEX idx_memberships_user_id ON memberships (user_id);
+
+ALTER TABLE pdfs ADD COLUMN workspace_id bigint;
+ALTER TABLE pdfs
+    ADD CONSTRAINT fk_pdfs_workspace
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id)
+    ON UPDATE CASCADE ON DELETE CASCADE;
+CREATE INDEX idx_pdfs_workspace_id ON pdfs (workspace_id);
+
+ALTER TABLE summaries ADD COLUMN workspace_id bigint;
+ALTER TABLE summaries
+    ADD CONSTRAINT fk_summaries_workspace
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id)
+    ON UPDATE CASCADE ON DELETE CASCADE;
+CREATE INDEX idx_summaries_workspace_id ON summaries (workspace_id);
+
+-- Every existing user gets a personal workspace holding the documents they own
+WITH created AS (
+    INSERT INTO workspaces (created_at, updated_at, name, created_by_id)
+    SELECT now(), now(), COALESCE(NULLIF(name, ''), email) || '''s workspace', id
+    FROM users
+    WHERE deleted_at IS NULL
+    RETURNING id, created_by_id
+)
+INSERT INTO memberships (workspace_id, user_id, role, created_at, updated_at)
+SELECT id, created_by_id, 'owner', now(), now()
+FROM created;
+
+UPDATE pdfs SET workspace_id = w.id
+FROM workspaces w
+WHERE w.created_by_id = pdfs.owner_id;
+
+UPDATE summaries SET workspace_id = w.id
+FROM workspaces w
+WHERE w.created_by_id = summaries.owner_id;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS summary_revisions;
+
+ALTER TABLE summaries DROP COLUMN IF EXISTS edited_at;
+
+ALTER TABLE pdfs DROP COLUMN IF EXISTS custom_fields;
+ALTER TABLE pdfs DROP COLUMN IF EXISTS description;
@@ -0,0 +1,22 @@
+ALTER TABLE pdfs ADD COLUMN description text NOT NULL DEFAULT '';
+ALTER TABLE pdfs ADD COLUMN custom_fields jsonb;
+
+ALTER TABLE summaries ADD COLUMN edited_at timestamptz;
+
+CREATE TABLE summary_revisions (
+    id bigserial PRIMARY KEY,
+    summary_id bigint NOT NULL,
+    revision bigint NOT NULL,
+    content text NOT NULL,
+    word_count bigint NOT NULL DEFAULT 0,
+    note text,
+    edited_by_id bigint,
+    created_at timestamptz,
+    CONSTRAINT fk_summary_revisions_summary FOREIGN KEY (summary_id) REFERENCES summaries (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_summary_revisions_edited_by FOREIGN KEY (edited_by_id) REFERENCES users (id)
+        ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_summary_revisions_summary_revision ON summary_revisions (summary_id, revision);
+CREATE INDEX idx_summary_revisions_edited_by_id ON summary_revisions (edited_by_id);
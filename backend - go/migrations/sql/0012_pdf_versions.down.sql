@@ -0,0 +1,16 @@
+-- Drop the references held by older versions. Their files are left in storage.
+UPDATE blobs b
+SET ref_count = b.ref_count - old.refs
+FROM (
+    SELECT v.content_hash, COUNT(*) AS refs
+    FROM pdf_versions v
+    JOIN pdfs p ON p.id = v.pdf_id
+    WHERE v.version <> p.version AND v.content_hash IS NOT NULL AND v.content_hash <> ''
+    GROUP BY v.content_hash
+) old
+WHERE b.hash = old.content_hash;
+
+ALTER TABLE summaries DROP COLUMN IF EXISTS pdf_version;
+ALTER TABLE pdfs DROP COLUMN IF EXISTS version;
+
+DROP TABLE IF EXISTS pdf_versions;
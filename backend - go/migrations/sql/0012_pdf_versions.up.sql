@@ -0,0 +1,29 @@
+CREATE TABLE pdf_versions (
+    id bigserial PRIMARY KEY,
+    pdf_id bigint NOT NULL,
+    version bigint NOT NULL,
+    filename text NOT NULL,
+    original_filename text,
+    file_size bigint NOT NULL,
+    page_count bigint NOT NULL,
+    content_hash varchar(64),
+    uploaded_by_id bigint,
+    created_at timestamptz,
+    CONSTRAINT fk_pdf_versions_pdf FOREIGN KEY (pdf_id) REFERENCES pdfs (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_pdf_versions_uploaded_by FOREIGN KEY (uploaded_by_id) REFERENCES users (id)
+        ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_pdf_versions_pdf_version ON pdf_versions (pdf_id, version);
+CREATE INDEX idx_pdf_versions_content_hash ON pdf_versions (content_hash);
+CREATE INDEX idx_pdf_versions_uploaded_by_id ON pdf_versions (uploaded_by_id);
+
+ALTER TABLE pdfs ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE summaries ADD COLUMN pdf_version bigint NOT NULL DEFAULT 1;
+
+-- Every existing file becomes version 1. Blob references move from the PDF to its version,
+-- so reference counts are unchanged.
+INSERT INTO pdf_versions (pdf_id, version, filename, file_size, page_count, content_hash, uploaded_by_id, created_at)
+SELECT id, 1, filename, file_size, page_count, content_hash, owner_id, created_at
+FROM pdfs;
@@ -0,0 +1,23 @@
+CREATE TABLE tags (
+    id bigserial PRIMARY KEY,
+    workspace_id bigint NOT NULL,
+    name varchar(64) NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_tags_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_tags_workspace_name ON tags (workspace_id, lower(name));
+
+CREATE TABLE pdf_tags (
+    pdf_id bigint NOT NULL,
+    tag_id bigint NOT NULL,
+    PRIMARY KEY (pdf_id, tag_id),
+    CONSTRAINT fk_pdf_tags_pdf FOREIGN KEY (pdf_id) REFERENCES pdfs (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_pdf_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_pdf_tags_tag_id ON pdf_tags (tag_id);
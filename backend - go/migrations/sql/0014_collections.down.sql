@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_pdfs_collection_id;
+ALTER TABLE pdfs DROP CONSTRAINT IF EXISTS fk_pdfs_collection;
+ALTER TABLE pdfs DROP COLUMN IF EXISTS collection_id;
+
+DROP TABLE IF EXISTS collections;
@@ -0,0 +1,27 @@
+CREATE TABLE collections (
+    id bigserial PRIMARY KEY,
+    workspace_id bigint NOT NULL,
+    parent_id bigint,
+    name text NOT NULL,
+    path text NOT NULL,
+    depth bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_collections_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_collections_parent FOREIGN KEY (parent_id) REFERENCES collections (id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_collections_workspace_id ON collections (workspace_id);
+CREATE INDEX idx_collections_parent_id ON collections (parent_id);
+-- text_pattern_ops lets subtree lookups (path LIKE '/1/4/%') use the index
+CREATE INDEX idx_collections_path ON collections (path text_pattern_ops);
+CREATE UNIQUE INDEX idx_collections_sibling_name ON collections (workspace_id, COALESCE(parent_id, 0), lower(name));
+
+ALTER TABLE pdfs ADD COLUMN collection_id bigint;
+ALTER TABLE pdfs
+    ADD CONSTRAINT fk_pdfs_collection
+    FOREIGN KEY (collection_id) REFERENCES collections (id)
+    ON UPDATE CASCADE ON DELETE SET NULL;
+CREATE INDEX idx_pdfs_collection_id ON pdfs (collection_id);
@@ -0,0 +1,21 @@
+CREATE TABLE uploads (
+    id varchar(32) PRIMARY KEY,
+    workspace_id bigint NOT NULL,
+    owner_id bigint NOT NULL,
+    filename text NOT NULL,
+    title text,
+    size bigint NOT NULL,
+    received bigint NOT NULL DEFAULT 0,
+    chunks bigint NOT NULL DEFAULT 0,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_uploads_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_uploads_owner FOREIGN KEY (owner_id) REFERENCES users (id)
+        ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX idx_uploads_workspace_id ON uploads (workspace_id);
+CREATE INDEX idx_uploads_owner_id ON uploads (owner_id);
+CREATE INDEX idx_uploads_expires_at ON uploads (expires_at);
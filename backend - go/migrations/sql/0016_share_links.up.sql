@@ -0,0 +1,30 @@
+CREATE TABLE share_links (
+    id bigserial PRIMARY KEY,
+    workspace_id bigint NOT NULL,
+    created_by_id bigint NOT NULL,
+    pdf_id bigint,
+    summary_id bigint,
+    password_hash text,
+    expires_at timestamptz NOT NULL,
+    max_downloads bigint,
+    download_count bigint NOT NULL DEFAULT 0,
+    last_downloaded_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT fk_share_links_workspace FOREIGN KEY (workspace_id) REFERENCES workspaces (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_share_links_created_by FOREIGN KEY (created_by_id) REFERENCES users (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_share_links_pdf FOREIGN KEY (pdf_id) REFERENCES pdfs (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    CONSTRAINT fk_share_links_summary FOREIGN KEY (summary_id) REFERENCES summaries (id)
+        ON UPDATE CASCADE ON DELETE CASCADE,
+    -- A link shares either a PDF or a summary
+    CONSTRAINT chk_share_links_target CHECK ((pdf_id IS NULL) <> (summary_id IS NULL))
+);
+
+CREATE INDEX idx_share_links_workspace_id ON share_links (workspace_id);
+CREATE INDEX idx_share_links_created_by_id ON share_links (created_by_id);
+CREATE INDEX idx_share_links_pdf_id ON share_links (pdf_id);
+CREATE INDEX idx_share_links_summary_id ON share_links (summary_id);
@@ -0,0 +1,31 @@
+CREATE TABLE audit_events (
+    id bigserial PRIMARY KEY,
+    created_at timestamptz NOT NULL,
+    workspace_id bigint,
+    actor_id bigint,
+    api_key_id bigint,
+    ip varchar(64),
+    request_id varchar(64),
+    action varchar(64) NOT NULL,
+    target_type varchar(32) NOT NULL,
+    target_id varchar(64),
+    before jsonb,
+    after jsonb
+);
+
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at);
+CREATE INDEX idx_audit_events_workspace_id ON audit_events (workspace_id, created_at);
+CREATE INDEX idx_audit_events_actor_id ON audit_events (actor_id);
+CREATE INDEX idx_audit_events_action ON audit_events (action);
+CREATE INDEX idx_audit_events_target ON audit_events (target_type, target_id);
+
+-- The log is append-only: rows can be added but never changed or removed
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
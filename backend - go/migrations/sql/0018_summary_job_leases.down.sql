@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_summary_jobs_heartbeat_at;
+ALTER TABLE summary_jobs DROP COLUMN IF EXISTS heartbeat_at;
+ALTER TABLE summary_jobs DROP COLUMN IF EXISTS locked_by;
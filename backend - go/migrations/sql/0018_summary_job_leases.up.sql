@@ -0,0 +1,6 @@
+-- Running jobs are leased to one server, which renews heartbeat_at while it works.
+-- Only jobs whose lease has run out are taken back, so replicas don't steal live work.
+ALTER TABLE summary_jobs ADD COLUMN IF NOT EXISTS locked_by varchar(64);
+ALTER TABLE summary_jobs ADD COLUMN IF NOT EXISTS heartbeat_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_summary_jobs_heartbeat_at ON summary_jobs (heartbeat_at) WHERE status = 'running';
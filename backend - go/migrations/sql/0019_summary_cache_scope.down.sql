@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_summary_cache;
+CREATE INDEX IF NOT EXISTS idx_summary_cache
+    ON summaries (content_hash, style, language, prompt_version);
+
+ALTER TABLE summaries DROP COLUMN IF EXISTS provider;
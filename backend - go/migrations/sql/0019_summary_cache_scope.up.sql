@@ -0,0 +1,7 @@
+-- Cached summaries are only reused inside the workspace that generated them
+-- and for the provider the request is routed to now.
+ALTER TABLE summaries ADD COLUMN IF NOT EXISTS provider varchar(32) NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS idx_summary_cache;
+CREATE INDEX IF NOT EXISTS idx_summary_cache
+    ON summaries (content_hash, style, language, prompt_version, workspace_id, provider);
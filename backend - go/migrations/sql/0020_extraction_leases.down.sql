@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pdfs_text_heartbeat_at;
+ALTER TABLE pdfs DROP COLUMN IF EXISTS text_heartbeat_at;
+ALTER TABLE pdfs DROP COLUMN IF EXISTS text_locked_by;
@@ -0,0 +1,6 @@
+-- PDFs being extracted are leased to one server, which renews text_heartbeat_at while it works.
+-- Only extractions whose lease has run out go back to pending, so replicas don't steal live work.
+ALTER TABLE pdfs ADD COLUMN IF NOT EXISTS text_locked_by varchar(64);
+ALTER TABLE pdfs ADD COLUMN IF NOT EXISTS text_heartbeat_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_pdfs_text_heartbeat_at ON pdfs (text_heartbeat_at) WHERE text_status = 'extracting';
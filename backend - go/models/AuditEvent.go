@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditEvent records one change and who made it. Events are never updated or
+// deleted and have no foreign keys, so they outlive the users, workspaces and
+// items they mention.
+type AuditEvent struct {
+	ID          uint      `gorm:"primaryKey"`
+	CreatedAt   time.Time `gorm:"not null;index"`
+	WorkspaceID *uint     `gorm:"index"` // nil for account events such as API keys
+	ActorID     *uint     `gorm:"index"` // nil when nobody was signed in
+	APIKeyID    *uint     // set when the actor used an API key
+	IP          string    `gorm:"size:64"`
+	RequestID   string    `gorm:"size:64"`
+	Action      string    `gorm:"size:64;not null;index"`
+	TargetType  string    `gorm:"size:32;not null"`
+	TargetID    string    `gorm:"size:64"` // empty for bulk actions, whose IDs are in the snapshots
+	Before      JSONMap   `gorm:"type:jsonb"`
+	After       JSONMap   `gorm:"type:jsonb"`
+}
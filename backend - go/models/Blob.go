@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// Blob is a stored file addressed by the SHA-256 of its content.
+// RefCount tracks how many PDF versions point at it.
+type Blob struct {
+	Hash      string `gorm:"primaryKey;size:64"`
+	Size      int64  `gorm:"not null"`
+	RefCount  int    `gorm:"not null;default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// Collection is a folder of PDFs in a workspace. Path holds the IDs from the
+// root down to the collection, e.g. "/1/4/9/", so a subtree is a prefix match.
+// Sibling names are unique, ignoring case.
+type Collection struct {
+	ID          uint   `gorm:"primaryKey"`
+	WorkspaceID uint   `gorm:"not null;index"`
+	ParentID    *uint  `gorm:"index"`
+	Name        string `gorm:"not null"`
+	Path        string `gorm:"not null;index"`
+	Depth       int    `gorm:"not null;default:0"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Workspace   Workspace   `gorm:"foreignKey:WorkspaceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Parent      *Collection `gorm:"foreignKey:ParentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -1,14 +1,37 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Text extraction states
+const (
+	TextStatusPending    = "pending"
+	TextStatusExtracting = "extracting"
+	TextStatusExtracted  = "extracted"
+	TextStatusFailed     = "failed"
+)
+
 type PDF struct {
 	gorm.Model
-	Filename  string      `gorm:"not null"`
-	FileSize  int64       `gorm:"not null"`
-	Title     string      `gorm:"not null"`
-	PageCount int         `gorm:"not null"`
-	Summaries []Summaries `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Filename     string      `gorm:"not null"`
+	FileSize     int64       `gorm:"not null"`
+	Title        string      `gorm:"not null"`
+	Description  string      `gorm:"not null;default:''"`
+	CustomFields JSONMap     `gorm:"type:jsonb"` // string, number and boolean values
+	PageCount    int         `gorm:"not null"`
+	ContentHash  string      `gorm:"size:64;index"`
+	TextStatus   string      `gorm:"not null;default:pending;index"`
+	Version      int         `gorm:"not null;default:1"` // latest PDFVersion
+	OwnerID      *uint       `gorm:"index"`
+	WorkspaceID  *uint       `gorm:"index"`
+	CollectionID *uint       `gorm:"index"`
+	Tags         []Tag       `gorm:"many2many:pdf_tags;"`
+	Summaries    []Summaries `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	// Lease on a running extraction, held by one server and renewed while it works
+	TextLockedBy    string `gorm:"size:64"`
+	TextHeartbeatAt *time.Time
 }
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// PDFPage holds the extracted plain text of one page of a PDF
+type PDFPage struct {
+	ID         uint   `gorm:"primaryKey"`
+	PDFID      uint   `gorm:"not null;uniqueIndex:idx_pdf_pages_pdf_page"`
+	PageNumber int    `gorm:"not null;uniqueIndex:idx_pdf_pages_pdf_page"`
+	Text       string `gorm:"not null"`
+	CharCount  int    `gorm:"not null"`
+	CreatedAt  time.Time
+	PDF        PDF `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// PDFVersion is one uploaded file of a PDF. The PDF row mirrors its latest
+// version, older versions keep their files so they can still be downloaded.
+type PDFVersion struct {
+	ID               uint   `gorm:"primaryKey"`
+	PDFID            uint   `gorm:"not null;uniqueIndex:idx_pdf_versions_pdf_version"`
+	Version          int    `gorm:"not null;uniqueIndex:idx_pdf_versions_pdf_version"`
+	Filename         string `gorm:"not null"`
+	OriginalFilename string
+	FileSize         int64  `gorm:"not null"`
+	PageCount        int    `gorm:"not null"`
+	ContentHash      string `gorm:"size:64;index"`
+	UploadedByID     *uint  `gorm:"index"`
+	CreatedAt        time.Time
+	PDF              PDF   `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UploadedBy       *User `gorm:"foreignKey:UploadedByID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
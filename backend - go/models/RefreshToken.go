@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken records an issued refresh token by its JWT ID so it can be rotated and revoked
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenID   string    `gorm:"size:64;not null;uniqueIndex"`
+	UserID    uint      `gorm:"not null;index"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+	User      User `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// ShareLink lets someone without an account open one PDF or summary. The link
+// carries an HMAC-signed token; the row holds the limits it is checked against.
+type ShareLink struct {
+	ID               uint      `gorm:"primaryKey"`
+	WorkspaceID      uint      `gorm:"not null;index"`
+	CreatedByID      uint      `gorm:"not null;index"`
+	PDFID            *uint     `gorm:"index"` // exactly one of PDFID and SummaryID is set
+	SummaryID        *uint     `gorm:"index"`
+	PasswordHash     string    // bcrypt, empty when no password is needed
+	ExpiresAt        time.Time `gorm:"not null"`
+	MaxDownloads     *int      // nil for no limit
+	DownloadCount    int       `gorm:"not null;default:0"`
+	LastDownloadedAt *time.Time
+	RevokedAt        *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Workspace        Workspace  `gorm:"foreignKey:WorkspaceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CreatedBy        User       `gorm:"foreignKey:CreatedByID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PDF              *PDF       `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Summary          *Summaries `gorm:"foreignKey:SummaryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
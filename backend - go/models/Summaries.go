@@ -1,15 +1,35 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type Summaries struct {
 	gorm.Model
-	Style       string  `gorm:"not null"`
-	Content     string  `gorm:"not null"`
-	PDFID       uint    `gorm:"not null;index"`
-	Language    string  `gorm:"not null"`
-	SummaryTime float64 `gorm:"not null"`
-	PDF         PDF     `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Style         string  `gorm:"not null;index:idx_summary_cache,priority:2"`
+	Content       string  `gorm:"not null"`
+	PDFID         uint    `gorm:"not null;index"`
+	PDFVersion    int     `gorm:"not null;default:1"` // version of the PDF the summary was generated from
+	Language      string  `gorm:"not null;index:idx_summary_cache,priority:3"`
+	SummaryTime   float64 `gorm:"not null"`
+	ContentHash   string  `gorm:"size:64;index:idx_summary_cache,priority:1"`
+	PromptVersion string  `gorm:"index:idx_summary_cache,priority:4"`
+	Provider      string  `gorm:"size:32;not null;default:'';index:idx_summary_cache,priority:6"` // summarizer that generated the content
+	OwnerID       *uint   `gorm:"index"`
+	WorkspaceID   *uint   `gorm:"index;index:idx_summary_cache,priority:5"`
+	// EditedAt is set once the generated content has been changed by hand
+	EditedAt *time.Time
+
+	// Details reported by the summarizer alongside the summary text
+	WordCount        int `gorm:"not null;default:0;index"`
+	ReadingTime      string
+	ChunksProcessed  int  `gorm:"not null;default:0"`
+	ChunkingUsed     bool `gorm:"not null;default:false;index"`
+	OriginalFilename string
+	SourceFileSize   int64   `gorm:"not null;default:0"`
+	TextStatistics   JSONMap `gorm:"type:jsonb"`
+
+	PDF PDF `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 }
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Summary job lifecycle states
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+type SummaryJob struct {
+	gorm.Model
+	PDFID       uint   `gorm:"not null;index"`
+	Style       string `gorm:"not null"`
+	Language    string `gorm:"not null"`
+	Status      string `gorm:"not null;default:queued;index"`
+	Attempts    int    `gorm:"not null;default:0"`
+	MaxAttempts int    `gorm:"not null;default:3"`
+	Force       bool   `gorm:"not null;default:false"`
+	Cached      bool   `gorm:"not null;default:false"`
+	Error       string
+	SummaryID   *uint
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	LockedBy    string     `gorm:"size:64"` // server running the job
+	HeartbeatAt *time.Time // renewed while running, the job is taken back once it is too old
+	PDF         PDF        `gorm:"foreignKey:PDFID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Summary     *Summaries `gorm:"foreignKey:SummaryID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
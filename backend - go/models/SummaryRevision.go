@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// SummaryRevision is one version of a summary's content. Revision 1 is the
+// generated text, later revisions are manual edits and reverts.
+type SummaryRevision struct {
+	ID         uint   `gorm:"primaryKey"`
+	SummaryID  uint   `gorm:"not null;uniqueIndex:idx_summary_revisions_summary_revision"`
+	Revision   int    `gorm:"not null;uniqueIndex:idx_summary_revisions_summary_revision"`
+	Content    string `gorm:"not null"`
+	WordCount  int    `gorm:"not null;default:0"`
+	Note       string
+	EditedByID *uint `gorm:"index"`
+	CreatedAt  time.Time
+	Summary    Summaries `gorm:"foreignKey:SummaryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	EditedBy   *User     `gorm:"foreignKey:EditedByID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
+}
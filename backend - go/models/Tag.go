@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag labels PDFs in a workspace. Names are unique per workspace, ignoring case.
+type Tag struct {
+	ID          uint   `gorm:"primaryKey"`
+	WorkspaceID uint   `gorm:"not null;index"`
+	Name        string `gorm:"size:64;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Workspace   Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	PDFs        []PDF     `gorm:"many2many:pdf_tags;"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Upload is a resumable upload in progress. Each received chunk is stored as
+// its own blob until the upload is finished and assembled into a PDF.
+type Upload struct {
+	ID          string `gorm:"primaryKey;size:32"`
+	WorkspaceID uint   `gorm:"not null;index"`
+	OwnerID     uint   `gorm:"not null;index"`
+	Filename    string `gorm:"not null"`
+	Title       string
+	Size        int64     `gorm:"not null"`           // total length declared when the upload was created
+	Received    int64     `gorm:"not null;default:0"` // bytes stored so far, the tus offset
+	Chunks      int       `gorm:"not null;default:0"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Workspace   Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Owner       User      `gorm:"foreignKey:OwnerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type User struct {
+	gorm.Model
+	Email        string `gorm:"not null;uniqueIndex"`
+	Name         string
+	PasswordHash string `gorm:"not null"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Workspace roles, from most to least privileged
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// ValidRole reports whether role is a known workspace role
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows reports whether role grants at least the permissions of required
+func RoleAllows(role, required string) bool {
+	return ValidRole(role) && roleRanks[role] >= roleRanks[required]
+}
+
+// Workspace groups the PDFs and summaries shared by a team
+type Workspace struct {
+	gorm.Model
+	Name        string `gorm:"not null"`
+	CreatedByID *uint  `gorm:"index"`
+	Memberships []Membership
+}
+
+// Membership gives a user a role in a workspace
+type Membership struct {
+	ID          uint   `gorm:"primaryKey"`
+	WorkspaceID uint   `gorm:"not null;uniqueIndex:idx_memberships_workspace_user"`
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_memberships_workspace_user;index"`
+	Role        string `gorm:"size:16;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Workspace   Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	User        User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
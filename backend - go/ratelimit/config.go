@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"backend-go/utils"
+	"context"
+	"fmt"
+)
+
+// Limiter applies rate limits to requests. Buckets are keyed by client IP,
+// user or API key, and each Rule adds a separate budget per caller.
+type Limiter struct {
+	store Store
+	// IP limits each client address before authentication
+	IP Limit
+	// User limits each signed-in user
+	User Limit
+	// APIKey limits API keys that don't have their own rate limit
+	APIKey Limit
+	Rules  []Rule
+}
+
+// NewLimiter creates a limiter without any limits set
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+// FromEnv creates a limiter configured by environment variables:
+//
+//	RATE_LIMIT_STORE            memory (default) or redis, which shares limits between replicas
+//	REDIS_URL                   server for the redis store (default "redis://localhost:6379/0")
+//	RATE_LIMIT_IP               per client IP, e.g. "1200/1m" (the default)
+//	RATE_LIMIT_USER             per signed-in user (default "600/1m")
+//	API_KEY_DEFAULT_RATE_LIMIT  requests per minute for API keys without their own limit (default 60)
+//	RATE_LIMIT_ROUTES           comma separated per-caller route budgets, e.g. "POST /pdf/:id/summarize=10/1m"
+//
+// A limit of "0/1m" disables it. The IP limit runs before authentication and
+// is shared by everyone behind one address, so it should stay above the
+// user limit or signed-in users never reach their own.
+func FromEnv(ctx context.Context) (*Limiter, error) {
+	var store Store
+	switch driver := utils.GetEnv("RATE_LIMIT_STORE", "memory"); driver {
+	case "memory":
+		store = NewMemoryStore()
+	case "redis":
+		redisStore, err := NewRedisStore(ctx, utils.GetEnv("REDIS_URL", "redis://localhost:6379/0"))
+		if err != nil {
+			return nil, err
+		}
+		store = redisStore
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", driver)
+	}
+
+	limiter := NewLimiter(store)
+
+	var err error
+	if limiter.IP, err = ParseLimit(utils.GetEnv("RATE_LIMIT_IP", "1200/1m")); err != nil {
+		return nil, err
+	}
+	if limiter.User, err = ParseLimit(utils.GetEnv("RATE_LIMIT_USER", "600/1m")); err != nil {
+		return nil, err
+	}
+	if limiter.IP.Enabled() && limiter.User.Enabled() && limiter.IP.interval() > limiter.User.interval() {
+		fmt.Printf("Warning: RATE_LIMIT_IP (%s) is lower than RATE_LIMIT_USER (%s), users will hit the IP limit first\n", limiter.IP, limiter.User)
+	}
+	limiter.APIKey = PerMinute(utils.GetEnvInt("API_KEY_DEFAULT_RATE_LIMIT", 60))
+
+	routes := utils.GetEnv("RATE_LIMIT_ROUTES", "POST /pdf/:id/summarize=10/1m,POST /pdf/upload=30/1m,PUT /pdf/:id/file=30/1m,POST /pdf/upload/batch=5/1m")
+	if limiter.Rules, err = ParseRules(routes); err != nil {
+		return nil, err
+	}
+
+	return limiter, nil
+}
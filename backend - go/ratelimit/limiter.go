@@ -0,0 +1,198 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket: Requests tokens are refilled evenly over Period
+// and up to Burst can be saved up. A zero Limit disables limiting.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// PerMinute allows n requests per minute with a burst of n
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Period: time.Minute, Burst: n}
+}
+
+// Enabled reports whether the limit restricts anything
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// interval is the time it takes to refill one token
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// String formats the limit as a RateLimit-Policy value, e.g. "10;w=60"
+func (l Limit) String() string {
+	policy := fmt.Sprintf("%d;w=%d", l.Requests, int(l.Period.Seconds()))
+	if l.burst() != l.Requests {
+		policy += fmt.Sprintf(";burst=%d", l.burst())
+	}
+	return policy
+}
+
+// ParseLimit parses "<requests>/<period>" such as "100/1m" or "10/30s"
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <requests>/<period>", s)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("invalid request count in rate limit %q", s)
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid period in rate limit %q", s)
+	}
+
+	return Limit{Requests: n, Period: d, Burst: n}, nil
+}
+
+// Result is the state of a bucket after taking a token
+type Result struct {
+	Allowed   bool
+	Limit     Limit
+	Remaining int
+	// RetryAfter is how long until a token is available when not allowed
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps token buckets. Implementations must be safe for concurrent use
+// and take tokens atomically, so one store can be shared by several replicas.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket is the token bucket arithmetic shared by the stores
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket up to now and tries to remove one token
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	burst := float64(limit.burst())
+	perToken := limit.interval()
+
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(burst, b.tokens+float64(elapsed)/float64(perToken))
+	}
+	b.last = now
+
+	return resultFor(limit, &b.tokens)
+}
+
+// resultFor removes a token when one is available and describes the bucket
+func resultFor(limit Limit, tokens *float64) Result {
+	perToken := float64(limit.interval())
+	result := Result{Limit: limit}
+
+	if *tokens >= 1 {
+		*tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - *tokens) * perToken)
+	}
+
+	result.Remaining = int(*tokens)
+	result.Reset = time.Duration((float64(limit.burst()) - *tokens) * perToken)
+	return result
+}
+
+// Rule gives requests matching Method and Path their own budget per caller,
+// on top of the caller's general limit
+type Rule struct {
+	// Method is an HTTP method, or "*" for any
+	Method string
+	// Path is a route pattern where ":name" matches one segment and a trailing "*" matches the rest
+	Path  string
+	Limit Limit
+}
+
+// Matches reports whether the rule applies to a request
+func (r Rule) Matches(method, path string) bool {
+	if r.Method != "*" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+
+	pattern := strings.Split(strings.Trim(r.Path, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, p := range pattern {
+		if p == "*" && i == len(pattern)-1 {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if strings.HasPrefix(p, ":") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if p != segments[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(segments)
+}
+
+// String formats the rule as accepted by ParseRules
+func (r Rule) String() string {
+	return fmt.Sprintf("%s %s=%d/%s", r.Method, r.Path, r.Limit.Requests, r.Limit.Period)
+}
+
+// ParseRules parses comma separated "<METHOD> <path>=<requests>/<period>" rules,
+// e.g. "POST /pdf/:id/summarize=10/1m,* /search=60/1m"
+func ParseRules(s string) ([]Rule, error) {
+	var rules []Rule
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, limit, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit rule %q, expected <METHOD> <path>=<requests>/<period>", entry)
+		}
+
+		method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+		path = strings.TrimSpace(path)
+		if !ok || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("invalid route in rate limit rule %q", entry)
+		}
+
+		parsed, err := ParseLimit(limit)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, Rule{Method: strings.ToUpper(method), Path: path, Limit: parsed})
+	}
+	return rules, nil
+}
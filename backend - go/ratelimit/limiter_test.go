@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{"100/1m", Limit{Requests: 100, Period: time.Minute, Burst: 100}, false},
+		{" 10/30s ", Limit{Requests: 10, Period: 30 * time.Second, Burst: 10}, false},
+		{"0/1m", Limit{Period: time.Minute}, false},
+		{"100", Limit{}, true},
+		{"-1/1m", Limit{}, true},
+		{"ten/1m", Limit{}, true},
+		{"10/0s", Limit{}, true},
+		{"10/soon", Limit{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBucketTake(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	limit := Limit{Requests: 60, Period: time.Minute, Burst: 3} // one token per second
+
+	tests := []struct {
+		name          string
+		after         time.Duration // since start
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+		wantReset     time.Duration
+	}{
+		{"new bucket starts full", 0, true, 2, 0, time.Second},
+		{"burst", 0, true, 1, 0, 2 * time.Second},
+		{"last of the burst", 0, true, 0, 0, 3 * time.Second},
+		{"empty", 0, false, 0, time.Second, 3 * time.Second},
+		{"half a token refilled", 500 * time.Millisecond, false, 0, 500 * time.Millisecond, 2500 * time.Millisecond},
+		{"one token refilled", time.Second, true, 0, 0, 3 * time.Second},
+		{"refill stops at burst", time.Hour, true, 2, 0, time.Second},
+		{"clock going backwards refills nothing", time.Hour - time.Minute, true, 1, 0, 2 * time.Second},
+	}
+
+	var b bucket
+	for _, tt := range tests {
+		got := b.take(limit, start.Add(tt.after))
+		if got.Allowed != tt.wantAllowed || got.Remaining != tt.wantRemaining || got.RetryAfter != tt.wantRetry || got.Reset != tt.wantReset {
+			t.Errorf("%s: got allowed=%v remaining=%d retry=%s reset=%s, want allowed=%v remaining=%d retry=%s reset=%s",
+				tt.name, got.Allowed, got.Remaining, got.RetryAfter, got.Reset,
+				tt.wantAllowed, tt.wantRemaining, tt.wantRetry, tt.wantReset)
+		}
+	}
+}
+
+func TestLimitBurstDefaultsToRequests(t *testing.T) {
+	limit := Limit{Requests: 5, Period: time.Minute}
+	if limit.burst() != 5 {
+		t.Errorf("burst() = %d, want 5", limit.burst())
+	}
+	if got := limit.String(); got != "5;w=60" {
+		t.Errorf("String() = %q", got)
+	}
+
+	limit.Burst = 10
+	if got := limit.String(); got != "5;w=60;burst=10" {
+		t.Errorf("String() with burst = %q", got)
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	tests := []struct {
+		rule         Rule
+		method, path string
+		want         bool
+	}{
+		{Rule{Method: "POST", Path: "/pdf/:id/summarize"}, "POST", "/pdf/12/summarize", true},
+		{Rule{Method: "POST", Path: "/pdf/:id/summarize"}, "post", "/pdf/12/summarize/", true},
+		{Rule{Method: "POST", Path: "/pdf/:id/summarize"}, "GET", "/pdf/12/summarize", false},
+		{Rule{Method: "POST", Path: "/pdf/:id/summarize"}, "POST", "/pdf//summarize", false},
+		{Rule{Method: "POST", Path: "/pdf/:id/summarize"}, "POST", "/pdf/12", false},
+		{Rule{Method: "POST", Path: "/pdf/upload"}, "POST", "/pdf/upload/batch", false},
+		{Rule{Method: "*", Path: "/search"}, "GET", "/search", true},
+		{Rule{Method: "*", Path: "/uploads/*"}, "PATCH", "/uploads/abc/def", true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rule.Matches(tt.method, tt.path); got != tt.want {
+			t.Errorf("%s matching %s %s = %v, want %v", tt.rule, tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("post /pdf/:id/summarize=10/1m, * /search=60/1m,")
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	if rules[0].Method != "POST" || rules[0].Path != "/pdf/:id/summarize" || rules[0].Limit.Requests != 10 {
+		t.Errorf("rules[0] = %+v", rules[0])
+	}
+
+	for _, invalid := range []string{"POST /pdf", "POST pdf=1/1m", "/pdf=1/1m", "POST /pdf=1"} {
+		if _, err := ParseRules(invalid); err == nil {
+			t.Errorf("ParseRules(%q) accepted an invalid rule", invalid)
+		}
+	}
+}
+
+func TestDefaultIPLimitAboveUserLimit(t *testing.T) {
+	t.Setenv("RATE_LIMIT_STORE", "memory")
+	t.Setenv("RATE_LIMIT_IP", "")
+	t.Setenv("RATE_LIMIT_USER", "")
+
+	limiter, err := FromEnv(context.Background())
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	if limiter.IP.interval() > limiter.User.interval() {
+		t.Errorf("default IP limit %s is below the user limit %s", limiter.IP, limiter.User)
+	}
+}
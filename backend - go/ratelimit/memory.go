@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	memoryShards = 64
+	// sweepInterval is how often a shard drops buckets that have refilled
+	sweepInterval = time.Minute
+)
+
+// MemoryStore keeps buckets in process memory, split into shards so
+// concurrent requests rarely wait on the same lock. Limits are per replica.
+type MemoryStore struct {
+	shards [memoryShards]memoryShard
+	now    func() time.Time
+}
+
+type memoryShard struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	nextSweep time.Time
+}
+
+type memoryBucket struct {
+	bucket
+	// full is when the bucket will have refilled completely, after which it
+	// is indistinguishable from a new one and can be dropped
+	full time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{now: time.Now}
+	for i := range s.shards {
+		s.shards[i].buckets = make(map[string]*memoryBucket)
+	}
+	return s
+}
+
+// Take removes a token from key's bucket
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := s.now()
+	shard := s.shard(key)
+
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	if now.After(shard.nextSweep) {
+		shard.sweep(now)
+	}
+
+	b, ok := shard.buckets[key]
+	if !ok {
+		b = &memoryBucket{}
+		shard.buckets[key] = b
+	}
+
+	result := b.take(limit, now)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// Len returns the number of buckets currently held
+func (s *MemoryStore) Len() int {
+	n := 0
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+		n += len(s.shards[i].buckets)
+		s.shards[i].mu.Unlock()
+	}
+	return n
+}
+
+func (s *MemoryStore) shard(key string) *memoryShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &s.shards[h.Sum32()%memoryShards]
+}
+
+// sweep drops full buckets so idle callers don't hold memory forever
+func (s *memoryShard) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	s.nextSweep = now.Add(sweepInterval)
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeClock drives a MemoryStore's notion of now
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestMemoryStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func TestMemoryStoreBurstAndRefill(t *testing.T) {
+	store, clock := newTestMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 2, Period: time.Second, Burst: 4}
+
+	for i := 0; i < 4; i++ {
+		result, err := store.Take(ctx, "user:1", limit)
+		if err != nil || !result.Allowed {
+			t.Fatalf("take %d of the burst = %+v, %v", i+1, result, err)
+		}
+	}
+
+	result, _ := store.Take(ctx, "user:1", limit)
+	if result.Allowed {
+		t.Fatal("allowed past the burst")
+	}
+	if result.RetryAfter != 500*time.Millisecond {
+		t.Errorf("RetryAfter = %s, want 500ms", result.RetryAfter)
+	}
+
+	// Other keys have their own bucket
+	if result, _ := store.Take(ctx, "user:2", limit); !result.Allowed {
+		t.Error("a different key was limited")
+	}
+
+	clock.Advance(500 * time.Millisecond)
+	if result, _ := store.Take(ctx, "user:1", limit); !result.Allowed {
+		t.Error("not allowed after a token refilled")
+	}
+	if result, _ := store.Take(ctx, "user:1", limit); result.Allowed {
+		t.Error("allowed a second request with a single token refilled")
+	}
+}
+
+func TestMemoryStoreSweepsRefilledBuckets(t *testing.T) {
+	store, clock := newTestMemoryStore()
+	ctx := context.Background()
+	limit := Limit{Requests: 10, Period: time.Second, Burst: 10}
+
+	for i := 0; i < 200; i++ {
+		store.Take(ctx, fmt.Sprintf("ip:10.0.0.%d", i), limit)
+	}
+	if n := store.Len(); n != 200 {
+		t.Fatalf("Len() = %d, want 200", n)
+	}
+
+	// Buckets refill within a second, but shards only sweep every sweepInterval
+	clock.Advance(2 * time.Second)
+	store.Take(ctx, "ip:10.0.0.0", limit)
+	if n := store.Len(); n != 200 {
+		t.Fatalf("Len() before the sweep interval = %d, want 200", n)
+	}
+
+	// Touching every shard after the interval sweeps the idle buckets away,
+	// keeping only the ones just used
+	clock.Advance(sweepInterval)
+	touched := map[*memoryShard]bool{}
+	for i := 0; len(touched) < memoryShards; i++ {
+		key := fmt.Sprintf("ip:192.168.0.%d", i)
+		shard := store.shard(key)
+		if touched[shard] {
+			continue
+		}
+		touched[shard] = true
+		store.Take(ctx, key, limit)
+	}
+	if n := store.Len(); n != memoryShards {
+		t.Errorf("Len() after sweeping = %d, want %d", n, memoryShards)
+	}
+}
+
+func TestMemoryStoreKeepsBucketsStillRefilling(t *testing.T) {
+	store, clock := newTestMemoryStore()
+	ctx := context.Background()
+	slow := Limit{Requests: 1, Period: time.Hour, Burst: 1}
+
+	store.Take(ctx, "user:1", slow)
+
+	// The bucket needs an hour to refill, so a sweep must not forget it was used
+	clock.Advance(2 * sweepInterval)
+	if result, _ := store.Take(ctx, "user:1", slow); result.Allowed {
+		t.Error("bucket was swept before it refilled, resetting the limit")
+	}
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills and takes from a bucket atomically. It uses the server
+// clock so replicas with skewed clocks share consistent buckets.
+//
+//	KEYS[1]  bucket key
+//	ARGV[1]  microseconds per token
+//	ARGV[2]  burst
+//
+// Returns {allowed, tokens left as a string}.
+var takeScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = burst
+elseif now > ts then
+  tokens = math.min(burst, tokens + (now - ts) / interval)
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.max(1, math.ceil((burst - tokens) * interval / 1000)))
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore keeps buckets in Redis, or anything speaking its protocol, so
+// every replica shares the same limits. It needs scripting and TIME inside
+// scripts, available since Redis 5.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore connects to the server at url, e.g. "redis://localhost:6379/0"
+func NewRedisStore(ctx context.Context, url string) (*RedisStore, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return NewRedisStoreWithClient(client), nil
+}
+
+// NewRedisStoreWithClient uses an existing client
+func NewRedisStoreWithClient(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client, prefix: "ratelimit:"}
+}
+
+// Take removes a token from key's bucket
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	interval := limit.interval().Microseconds()
+	if interval < 1 {
+		interval = 1
+	}
+
+	values, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, interval, limit.burst()).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit script failed: %w", err)
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script reply %v", values)
+	}
+
+	allowed, _ := values[0].(int64)
+	left, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(left, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected token count %q: %w", left, err)
+	}
+
+	// The script already took the token, resultFor only describes the bucket
+	if allowed == 1 {
+		tokens++
+	}
+	return resultFor(limit, &tokens), nil
+}
+
+// Close disconnects from the server
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var redisEpoch = time.Unix(1700000000, 0)
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	server.SetTime(redisEpoch)
+
+	store := NewRedisStoreWithClient(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	t.Cleanup(func() { store.Close() })
+	return store, server
+}
+
+func TestRedisStoreTake(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	ctx := context.Background()
+	limit := Limit{Requests: 2, Period: time.Second, Burst: 3} // one token per 500ms
+
+	tests := []struct {
+		name          string
+		advance       time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{"new bucket starts full", 0, true, 2, 0},
+		{"burst", 0, true, 1, 0},
+		{"last of the burst", 0, true, 0, 0},
+		{"empty", 0, false, 0, 500 * time.Millisecond},
+		{"half a token refilled", 250 * time.Millisecond, false, 0, 250 * time.Millisecond},
+		{"one token refilled", 250 * time.Millisecond, true, 0, 0},
+		{"refill stops at burst", time.Hour, true, 2, 0},
+	}
+
+	now := redisEpoch
+	for _, tt := range tests {
+		now = now.Add(tt.advance)
+		server.SetTime(now)
+
+		got, err := store.Take(ctx, "user:1", limit)
+		if err != nil {
+			t.Fatalf("%s: Take: %v", tt.name, err)
+		}
+		if got.Allowed != tt.wantAllowed || got.Remaining != tt.wantRemaining || got.RetryAfter != tt.wantRetry {
+			t.Errorf("%s: got allowed=%v remaining=%d retry=%s, want allowed=%v remaining=%d retry=%s",
+				tt.name, got.Allowed, got.Remaining, got.RetryAfter, tt.wantAllowed, tt.wantRemaining, tt.wantRetry)
+		}
+	}
+}
+
+func TestRedisStoreExpiresRefilledBuckets(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	ctx := context.Background()
+	limit := Limit{Requests: 1, Period: time.Second, Burst: 5}
+
+	store.Take(ctx, "ip:10.0.0.1", limit)
+	store.Take(ctx, "ip:10.0.0.1", limit)
+
+	if !server.Exists("ratelimit:ip:10.0.0.1") {
+		t.Fatal("bucket was not stored under the ratelimit: prefix")
+	}
+
+	// Two tokens are missing, so the bucket is full again in two seconds
+	if ttl := server.TTL("ratelimit:ip:10.0.0.1"); ttl != 2*time.Second {
+		t.Errorf("TTL = %s, want 2s", ttl)
+	}
+
+	server.FastForward(2 * time.Second)
+	if server.Exists("ratelimit:ip:10.0.0.1") {
+		t.Error("bucket outlived its refill time")
+	}
+}
+
+func TestRedisStoreReportsFailures(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	server.Close()
+
+	if _, err := store.Take(context.Background(), "user:1", PerMinute(10)); err == nil {
+		t.Fatal("expected an error when the server is unreachable")
+	}
+}
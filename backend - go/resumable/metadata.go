@@ -0,0 +1,39 @@
+package resumable
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Version is the tus protocol version the upload routes follow
+const Version = "1.0.0"
+
+// ParseMetadata decodes an Upload-Metadata header, comma separated pairs of
+// a key and a base64 encoded value such as "filename cmVwb3J0LnBkZg==".
+// A key may appear without a value.
+func ParseMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, fmt.Errorf("invalid metadata pair %q", strings.TrimSpace(pair))
+		}
+
+		value := ""
+		if len(parts) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("metadata value for %q is not valid base64", parts[0])
+			}
+			value = string(decoded)
+		}
+		metadata[parts[0]] = value
+	}
+
+	return metadata, nil
+}
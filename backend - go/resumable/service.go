@@ -0,0 +1,352 @@
+package resumable
+
+import (
+	"backend-go/models"
+	"backend-go/storage"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotFound       = errors.New("upload not found")
+	ErrOffsetMismatch = errors.New("upload offset does not match the bytes received")
+	ErrTooLong        = errors.New("chunk goes past the upload length")
+	ErrIncomplete     = errors.New("upload is not complete")
+)
+
+// Service runs resumable uploads in the style of the tus protocol: an upload
+// is created with its total length, chunks are appended at the current
+// offset, and a finished upload is assembled into a staged file. Chunks live
+// in the blob store so any replica can continue an upload. Uploads that stop
+// receiving chunks expire and are purged with their chunks.
+type Service struct {
+	db            *gorm.DB
+	blobs         storage.BlobStore
+	content       *storage.ContentStore
+	expiry        time.Duration
+	purgeInterval time.Duration
+	wg            sync.WaitGroup
+}
+
+// NewService creates an upload service. Nothing is purged until Start is called.
+func NewService(db *gorm.DB, blobs storage.BlobStore, content *storage.ContentStore, expiry, purgeInterval time.Duration) *Service {
+	if purgeInterval <= 0 {
+		purgeInterval = time.Hour
+	}
+
+	return &Service{
+		db:            db,
+		blobs:         blobs,
+		content:       content,
+		expiry:        expiry,
+		purgeInterval: purgeInterval,
+	}
+}
+
+// Create starts an upload of size bytes
+func (s *Service) Create(workspaceID, ownerID uint, filename, title string, size int64) (*models.Upload, error) {
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+
+	upload := models.Upload{
+		ID:          id,
+		WorkspaceID: workspaceID,
+		OwnerID:     ownerID,
+		Filename:    filename,
+		Title:       title,
+		Size:        size,
+		ExpiresAt:   time.Now().Add(s.expiry),
+	}
+	if err := s.db.Create(&upload).Error; err != nil {
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+	return &upload, nil
+}
+
+// Get returns one of the owner's uploads
+func (s *Service) Get(workspaceID, ownerID uint, id string) (*models.Upload, error) {
+	var upload models.Upload
+	if err := find(s.db, workspaceID, ownerID, id, &upload); err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// Append stores the size bytes read from chunk at offset, which must equal the
+// bytes received so far, and pushes back the upload's expiry. Nothing is read
+// from chunk unless it fits the upload.
+func (s *Service) Append(ctx context.Context, workspaceID, ownerID uint, id string, offset int64, chunk io.Reader, size int64) (*models.Upload, error) {
+	var upload models.Upload
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Locked so concurrent PATCHes for the same offset can't both be accepted
+		if err := find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), workspaceID, ownerID, id, &upload); err != nil {
+			return err
+		}
+
+		if offset != upload.Received {
+			return ErrOffsetMismatch
+		}
+		if offset+size > upload.Size {
+			return ErrTooLong
+		}
+		if size == 0 {
+			return nil
+		}
+
+		if err := s.blobs.Put(ctx, partKey(upload.ID, upload.Chunks), &exactReader{r: chunk, n: size}, size); err != nil {
+			return fmt.Errorf("failed to store chunk: %w", err)
+		}
+
+		upload.Received += size
+		upload.Chunks++
+		upload.ExpiresAt = time.Now().Add(s.expiry)
+		return tx.Model(&upload).Updates(map[string]interface{}{
+			"received":   upload.Received,
+			"chunks":     upload.Chunks,
+			"expires_at": upload.ExpiresAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// Finish assembles a complete upload into a staged file, stores it and hands
+// it to create, which must save through tx. The upload is deleted in the same
+// transaction, so an upload turns into exactly one record: when create fails
+// the upload is kept for the client to retry or cancel, and a concurrent
+// finish or cancel finds it gone.
+func (s *Service) Finish(ctx context.Context, workspaceID, ownerID uint, id string, create func(tx *gorm.DB, upload models.Upload, staged *storage.Staged) error) error {
+	var upload models.Upload
+	if err := find(s.db, workspaceID, ownerID, id, &upload); err != nil {
+		return err
+	}
+	if upload.Received != upload.Size {
+		return ErrIncomplete
+	}
+
+	parts := &partsReader{ctx: ctx, blobs: s.blobs, upload: upload}
+	staged, err := s.content.Stage(parts)
+	parts.Close()
+	if err != nil {
+		return err
+	}
+	defer s.content.Discard(staged)
+
+	if staged.Size != upload.Size {
+		return fmt.Errorf("assembled %d bytes, expected %d", staged.Size, upload.Size)
+	}
+
+	if err := s.content.Upload(ctx, staged); err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), workspaceID, ownerID, id, &upload); err != nil {
+			return err
+		}
+		if err := create(tx, upload, staged); err != nil {
+			return err
+		}
+		return tx.Delete(&upload).Error
+	})
+	if err != nil {
+		s.content.Abandon(ctx, staged)
+		return err
+	}
+
+	s.deleteParts(ctx, upload)
+	return nil
+}
+
+// Delete cancels an upload and removes its chunks
+func (s *Service) Delete(ctx context.Context, workspaceID, ownerID uint, id string) error {
+	var upload models.Upload
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), workspaceID, ownerID, id, &upload); err != nil {
+			return err
+		}
+		return tx.Delete(&upload).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.deleteParts(ctx, upload)
+	return nil
+}
+
+// Start purges expired uploads now and then every purge interval until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.purgeInterval)
+		defer ticker.Stop()
+
+		for {
+			s.Purge(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the purger has exited after ctx is cancelled
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
+
+// Purge deletes uploads that have not received a chunk within the expiry period
+func (s *Service) Purge(ctx context.Context) {
+	purged := 0
+	for ctx.Err() == nil {
+		var expired []models.Upload
+		if err := s.db.Where("expires_at < ?", time.Now()).Order("expires_at asc").Limit(100).Find(&expired).Error; err != nil {
+			fmt.Printf("Failed to find expired uploads: %v\n", err)
+			return
+		}
+		if len(expired) == 0 {
+			break
+		}
+
+		for _, upload := range expired {
+			if err := s.purgeExpired(ctx, upload); err != nil {
+				fmt.Printf("Failed to purge upload %s: %v\n", upload.ID, err)
+				return
+			}
+			purged++
+		}
+	}
+
+	if purged > 0 {
+		fmt.Printf("Purged %d expired uploads\n", purged)
+	}
+}
+
+// purgeExpired deletes an upload unless a chunk arrived since it was found
+func (s *Service) purgeExpired(ctx context.Context, upload models.Upload) error {
+	result := s.db.Where("id = ? AND expires_at < ?", upload.ID, time.Now()).Delete(&models.Upload{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		s.deleteParts(ctx, upload)
+	}
+	return nil
+}
+
+// deleteParts removes an upload's chunks. Failures only leave stray blobs behind, so they are logged.
+func (s *Service) deleteParts(ctx context.Context, upload models.Upload) {
+	for i := 0; i < upload.Chunks; i++ {
+		if err := s.blobs.Delete(ctx, partKey(upload.ID, i)); err != nil {
+			fmt.Printf("Failed to delete chunk %d of upload %s: %v\n", i, upload.ID, err)
+		}
+	}
+}
+
+func find(tx *gorm.DB, workspaceID, ownerID uint, id string, upload *models.Upload) error {
+	err := tx.Where("workspace_id = ? AND owner_id = ?", workspaceID, ownerID).First(upload, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// partKey is the blob key of an upload's nth chunk
+func partKey(id string, n int) string {
+	return fmt.Sprintf("upload-%s-%06d.part", id, n)
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// exactReader reads n bytes from r and fails with io.ErrUnexpectedEOF when r
+// ends early, so a dropped request never stores a short chunk
+type exactReader struct {
+	r io.Reader
+	n int64
+}
+
+func (e *exactReader) Read(p []byte) (int, error) {
+	if e.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > e.n {
+		p = p[:e.n]
+	}
+	n, err := e.r.Read(p)
+	e.n -= int64(n)
+	if err == io.EOF && e.n > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// partsReader reads an upload's chunks back to back, opening one at a time
+type partsReader struct {
+	ctx     context.Context
+	blobs   storage.BlobStore
+	upload  models.Upload
+	next    int
+	current storage.Object
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if r.next == r.upload.Chunks {
+				return 0, io.EOF
+			}
+			object, err := r.blobs.Get(r.ctx, partKey(r.upload.ID, r.next))
+			if err != nil {
+				return 0, fmt.Errorf("failed to open chunk %d: %w", r.next, err)
+			}
+			r.current = object
+			r.next++
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			return n, nil
+		}
+		return n, err
+	}
+}
+
+// Close releases the chunk being read when reading stopped early
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
@@ -0,0 +1,223 @@
+package revisions
+
+import (
+	"backend-go/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotFound         = errors.New("summary not found")
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrUnchanged        = errors.New("content is unchanged")
+)
+
+// Service edits summaries by hand and keeps every version of their content.
+// The generated text is recorded as revision 1 the first time a summary is
+// edited, so summaries that were never touched have no stored revisions.
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a revision service
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Edit replaces a summary's content and records it as a new revision
+func (s *Service) Edit(workspaceID, summaryID, userID uint, content, note string) (*models.Summaries, *models.SummaryRevision, error) {
+	var summary models.Summaries
+	var revision models.SummaryRevision
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSummary(tx, workspaceID, summaryID, &summary); err != nil {
+			return err
+		}
+
+		return record(tx, &summary, &revision, strings.TrimSpace(content), strings.TrimSpace(note), userID)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &summary, &revision, nil
+}
+
+// Revert restores the content of an earlier revision. The history is kept and
+// the restored content is recorded as a new revision.
+func (s *Service) Revert(workspaceID, summaryID uint, number int, userID uint) (*models.Summaries, *models.SummaryRevision, error) {
+	var summary models.Summaries
+	var revision models.SummaryRevision
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSummary(tx, workspaceID, summaryID, &summary); err != nil {
+			return err
+		}
+
+		var target models.SummaryRevision
+		err := tx.Where("summary_id = ? AND revision = ?", summary.ID, number).First(&target).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Revision 1 of a summary that was never edited is its current content
+			if number == 1 && summary.EditedAt == nil {
+				return ErrUnchanged
+			}
+			return ErrRevisionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		return record(tx, &summary, &revision, target.Content, fmt.Sprintf("Reverted to revision %d", number), userID)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &summary, &revision, nil
+}
+
+// List returns a summary's revisions, newest first. A summary that was never
+// edited has a single revision holding its generated content.
+func (s *Service) List(workspaceID, summaryID uint, limit, offset int) ([]models.SummaryRevision, int64, error) {
+	var summary models.Summaries
+	err := s.db.Where("workspace_id = ?", workspaceID).First(&summary, summaryID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, 0, ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := s.db.Model(&models.SummaryRevision{}).Where("summary_id = ?", summary.ID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count revisions: %w", err)
+	}
+
+	if total == 0 {
+		revisions := []models.SummaryRevision{}
+		if offset == 0 {
+			revisions = append(revisions, baseline(summary))
+		}
+		return revisions, 1, nil
+	}
+
+	revisions := []models.SummaryRevision{}
+	if err := s.db.Preload("EditedBy").
+		Where("summary_id = ?", summary.ID).
+		Order("revision desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&revisions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list revisions: %w", err)
+	}
+
+	return revisions, total, nil
+}
+
+func lockSummary(tx *gorm.DB, workspaceID, summaryID uint, summary *models.Summaries) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("workspace_id = ?", workspaceID).
+		First(summary, summaryID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// record saves content as the summary's next revision and makes it current.
+// The summary must be locked by tx.
+func record(tx *gorm.DB, summary *models.Summaries, revision *models.SummaryRevision, content, note string, userID uint) error {
+	if content == summary.Content {
+		return ErrUnchanged
+	}
+
+	var latest int
+	if err := tx.Model(&models.SummaryRevision{}).
+		Where("summary_id = ?", summary.ID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	// Keep the generated text before it is overwritten for the first time
+	if latest == 0 {
+		original := baseline(*summary)
+		if err := tx.Create(&original).Error; err != nil {
+			return fmt.Errorf("failed to save original content: %w", err)
+		}
+		latest = original.Revision
+	}
+
+	words := len(strings.Fields(content))
+	*revision = models.SummaryRevision{
+		SummaryID:  summary.ID,
+		Revision:   latest + 1,
+		Content:    content,
+		WordCount:  words,
+		Note:       note,
+		EditedByID: &userID,
+	}
+	if err := tx.Create(revision).Error; err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"content":      content,
+		"word_count":   words,
+		"reading_time": readingTime(words),
+		"edited_at":    now,
+	}
+	if err := tx.Model(summary).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update summary: %w", err)
+	}
+
+	summary.Content = content
+	summary.WordCount = words
+	summary.ReadingTime = readingTime(words)
+	summary.EditedAt = &now
+	return nil
+}
+
+// baseline is the first revision of a summary, its generated content
+func baseline(summary models.Summaries) models.SummaryRevision {
+	return models.SummaryRevision{
+		SummaryID: summary.ID,
+		Revision:  1,
+		Content:   summary.Content,
+		WordCount: summary.WordCount,
+		Note:      "Generated",
+		CreatedAt: summary.CreatedAt,
+	}
+}
+
+// readingTime estimates reading time at 200 words per minute, worded like the summarizer's estimate
+func readingTime(words int) string {
+	if words == 0 {
+		return "0 minutes"
+	}
+
+	minutes := words / 200
+	if minutes < 1 {
+		return "Less than 1 minute"
+	}
+	if minutes < 60 {
+		return plural(minutes, "minute")
+	}
+
+	hours, remaining := minutes/60, minutes%60
+	if remaining == 0 {
+		return plural(hours, "hour")
+	}
+	return plural(hours, "hour") + " " + plural(remaining, "minute")
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,192 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Hit types
+const (
+	TypePDF     = "pdf"
+	TypePage    = "page"
+	TypeSummary = "summary"
+)
+
+// DocumentConfig is the text search configuration of PDF titles and page text,
+// which carry no language of their own. It must match the generated search_vector columns.
+const DocumentConfig = "english"
+
+// FallbackConfig is used for summaries in a language without a dedicated configuration
+const FallbackConfig = "simple"
+
+// summaryConfigs maps Summaries.Language to a text search configuration.
+// It must match the CASE expression behind summaries.search_vector.
+var summaryConfigs = []struct{ language, config string }{
+	{"english", "english"},
+	{"indonesian", "indonesian"},
+}
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2"
+
+// Query describes a ranked search. Named parameter @q holds the search text.
+// Only documents in WorkspaceID are searched.
+type Query struct {
+	Text        string
+	WorkspaceID uint
+	PDFID       uint
+	Types       []string
+	Limit       int
+	Offset      int
+}
+
+// Hit is one ranked match with a highlighted snippet
+type Hit struct {
+	Type       string
+	PDFID      uint
+	PDFTitle   string
+	PageNumber *int
+	SummaryID  *uint
+	Language   *string
+	Rank       float64
+	Snippet    string
+}
+
+// tsquery parses @q with the given configuration. Configurations are constants, never user input.
+func tsquery(config string) string {
+	return fmt.Sprintf("websearch_to_tsquery('%s', @q)", config)
+}
+
+// SummaryCondition matches summaries in table alias against @q using each row's language.
+// Every branch compares the stored vector with a constant query so the GIN index stays usable.
+func SummaryCondition(alias string) string {
+	known := make([]string, 0, len(summaryConfigs))
+	branches := make([]string, 0, len(summaryConfigs)+1)
+	for _, c := range summaryConfigs {
+		known = append(known, "'"+c.language+"'")
+		branches = append(branches, fmt.Sprintf("(%s.language = '%s' AND %s.search_vector @@ %s)", alias, c.language, alias, tsquery(c.config)))
+	}
+	branches = append(branches, fmt.Sprintf("(%s.language NOT IN (%s) AND %s.search_vector @@ %s)", alias, strings.Join(known, ", "), alias, tsquery(FallbackConfig)))
+
+	return "(" + strings.Join(branches, " OR ") + ")"
+}
+
+// PDFCondition matches PDFs in table alias whose title or extracted text matches @q
+func PDFCondition(alias string) string {
+	return fmt.Sprintf(
+		"(%s.search_vector @@ %s OR EXISTS (SELECT 1 FROM pdf_pages pg WHERE pg.pdf_id = %s.id AND pg.search_vector @@ %s))",
+		alias, tsquery(DocumentConfig), alias, tsquery(DocumentConfig),
+	)
+}
+
+// summaryConfigExpr picks the configuration for a summary row, for ranking and snippets
+func summaryConfigExpr(alias string) string {
+	cases := make([]string, 0, len(summaryConfigs))
+	for _, c := range summaryConfigs {
+		cases = append(cases, fmt.Sprintf("WHEN '%s' THEN '%s'::regconfig", c.language, c.config))
+	}
+	return fmt.Sprintf("CASE %s.language %s ELSE '%s'::regconfig END", alias, strings.Join(cases, " "), FallbackConfig)
+}
+
+// escapedHTML escapes the text in column so the only markup in a snippet is
+// the <mark> tags ts_headline adds. Entities are skipped by the text search
+// parser, so escaping doesn't change what matches.
+func escapedHTML(column string) string {
+	return fmt.Sprintf("replace(replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '\"', '&quot;')", column)
+}
+
+// hitsSQL unions the matching rows of every requested type
+func hitsSQL(q Query) string {
+	wanted := map[string]bool{}
+	for _, t := range q.Types {
+		wanted[t] = true
+	}
+	all := len(wanted) == 0
+
+	pdfFilter := " AND p.workspace_id = @workspace_id"
+	if q.PDFID != 0 {
+		pdfFilter += " AND p.id = @pdf_id"
+	}
+
+	var parts []string
+	if all || wanted[TypePDF] {
+		parts = append(parts, fmt.Sprintf(`
+			SELECT 'pdf' AS type, p.id AS pdf_id, NULL::bigint AS page_id, NULL::bigint AS summary_id,
+				ts_rank_cd(p.search_vector, %s) AS rank
+			FROM pdfs p
+			WHERE p.deleted_at IS NULL AND p.search_vector @@ %s%s`,
+			tsquery(DocumentConfig), tsquery(DocumentConfig), pdfFilter))
+	}
+	if all || wanted[TypePage] {
+		parts = append(parts, fmt.Sprintf(`
+			SELECT 'page' AS type, p.id AS pdf_id, pg.id AS page_id, NULL::bigint AS summary_id,
+				ts_rank_cd(pg.search_vector, %s) AS rank
+			FROM pdf_pages pg
+			JOIN pdfs p ON p.id = pg.pdf_id
+			WHERE p.deleted_at IS NULL AND pg.search_vector @@ %s%s`,
+			tsquery(DocumentConfig), tsquery(DocumentConfig), pdfFilter))
+	}
+	if all || wanted[TypeSummary] {
+		parts = append(parts, fmt.Sprintf(`
+			SELECT 'summary' AS type, p.id AS pdf_id, NULL::bigint AS page_id, s.id AS summary_id,
+				ts_rank_cd(s.search_vector, websearch_to_tsquery(%s, @q)) AS rank
+			FROM summaries s
+			JOIN pdfs p ON p.id = s.pdf_id
+			WHERE p.deleted_at IS NULL AND s.deleted_at IS NULL AND %s%s`,
+			summaryConfigExpr("s"), SummaryCondition("s"), pdfFilter))
+	}
+
+	return strings.Join(parts, "\n UNION ALL \n")
+}
+
+// Run returns one page of hits ordered by rank together with the total number of hits
+func Run(db *gorm.DB, q Query) ([]Hit, int64, error) {
+	hits := hitsSQL(q)
+	if hits == "" {
+		return []Hit{}, 0, nil
+	}
+
+	vars := map[string]interface{}{
+		"q":            q.Text,
+		"workspace_id": q.WorkspaceID,
+		"pdf_id":       q.PDFID,
+		"limit":        q.Limit,
+		"offset":       q.Offset,
+		"headline":     headlineOptions,
+	}
+
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM ( "+hits+" ) AS hits", vars).Scan(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	// Snippets are only built for the returned page since ts_headline re-parses the whole text
+	sql := fmt.Sprintf(`
+		SELECT h.type, h.pdf_id, p.title AS pdf_title, pg.page_number, h.summary_id, s.language, h.rank,
+			CASE h.type
+				WHEN 'pdf' THEN ts_headline('%s', %s, %s, @headline )
+				WHEN 'page' THEN ts_headline('%s', %s, %s, @headline )
+				ELSE ts_headline(%s, %s, websearch_to_tsquery(%s, @q), @headline )
+			END AS snippet
+		FROM (
+			SELECT * FROM ( %s ) AS hits
+			ORDER BY rank DESC, pdf_id ASC, page_id ASC NULLS FIRST, summary_id ASC NULLS FIRST
+			LIMIT @limit OFFSET @offset
+		) AS h
+		JOIN pdfs p ON p.id = h.pdf_id
+		LEFT JOIN pdf_pages pg ON pg.id = h.page_id
+		LEFT JOIN summaries s ON s.id = h.summary_id
+		ORDER BY h.rank DESC, h.pdf_id ASC, pg.page_number ASC NULLS FIRST, h.summary_id ASC NULLS FIRST`,
+		DocumentConfig, escapedHTML("p.title"), tsquery(DocumentConfig),
+		DocumentConfig, escapedHTML("pg.text"), tsquery(DocumentConfig),
+		summaryConfigExpr("s"), escapedHTML("s.content"), summaryConfigExpr("s"),
+		hits)
+
+	results := []Hit{}
+	if err := db.Raw(sql, vars).Scan(&results).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to search: %w", err)
+	}
+
+	return results, total, nil
+}
@@ -0,0 +1,90 @@
+package shares
+
+import (
+	"backend-go/models"
+	"html/template"
+	"io"
+	"strings"
+)
+
+var summaryPage = template.Must(template.New("summary").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 46rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.6; color: #1f2937; }
+h1 { font-size: 1.5rem; margin-bottom: 0.25rem; }
+.meta { color: #6b7280; font-size: 0.875rem; margin-bottom: 2rem; }
+p { white-space: pre-line; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">{{.Style}} summary in {{.Language}}, {{.Date}}</p>
+{{range .Paragraphs}}<p>{{.}}</p>
+{{end}}</body>
+</html>
+`))
+
+var passwordPage = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Password required</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 24rem; margin: 4rem auto; padding: 0 1rem; color: #1f2937; }
+.error { color: #ef4444; }
+</style>
+</head>
+<body>
+<h1>Password required</h1>
+{{if .Wrong}}<p class="error">That password is not correct.</p>
+{{end}}<form method="post">
+<input type="password" name="password" autofocus required>
+<button type="submit">Open</button>
+</form>
+</body>
+</html>
+`))
+
+// RenderSummary writes a shared summary as a standalone HTML page
+func RenderSummary(w io.Writer, summary models.Summaries) error {
+	lang := "en"
+	if summary.Language == "indonesian" {
+		lang = "id"
+	}
+
+	// Blank lines separate paragraphs, single line breaks such as list items are kept
+	var paragraphs []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(summary.Content, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+
+	return summaryPage.Execute(w, map[string]interface{}{
+		"Lang":       lang,
+		"Title":      summary.PDF.Title,
+		"Style":      capitalize(summary.Style),
+		"Language":   capitalize(summary.Language),
+		"Date":       summary.CreatedAt.Format("2 January 2006"),
+		"Paragraphs": paragraphs,
+	})
+}
+
+// RenderPasswordForm writes a page asking for a link's password, which it posts back to the link
+func RenderPasswordForm(w io.Writer, wrong bool) error {
+	return passwordPage.Execute(w, map[string]interface{}{"Wrong": wrong})
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,203 @@
+package shares
+
+import (
+	"backend-go/auth"
+	"backend-go/models"
+	"backend-go/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotFound         = errors.New("share link not found")
+	ErrTargetNotFound   = errors.New("shared item not found")
+	ErrInvalidToken     = errors.New("invalid share link")
+	ErrExpired          = errors.New("share link has expired")
+	ErrRevoked          = errors.New("share link has been revoked")
+	ErrExhausted        = errors.New("share link has reached its download limit")
+	ErrPasswordRequired = errors.New("share link requires a password")
+	ErrWrongPassword    = errors.New("wrong password for share link")
+)
+
+// Options are the limits of a new link
+type Options struct {
+	ExpiresAt    time.Time
+	Password     string // empty for no password
+	MaxDownloads *int   // nil for no limit
+}
+
+// Filter narrows List to the links of one PDF or summary
+type Filter struct {
+	PDFID     *uint
+	SummaryID *uint
+	// Inactive includes revoked, expired and used up links
+	Inactive bool
+}
+
+// Service creates, lists, revokes and opens share links. Tokens are signed
+// with the service's secret, so links keep working across restarts and
+// replicas only while the secret stays the same.
+type Service struct {
+	db     *gorm.DB
+	secret []byte
+}
+
+// NewService creates a share link service signing tokens with secret
+func NewService(db *gorm.DB, secret []byte) *Service {
+	return &Service{db: db, secret: secret}
+}
+
+// SharePDF creates a link to one of the workspace's PDFs
+func (s *Service) SharePDF(workspaceID, userID, pdfID uint, opts Options) (*models.ShareLink, error) {
+	if err := s.exists(workspaceID, &models.PDF{}, pdfID); err != nil {
+		return nil, err
+	}
+	return s.create(models.ShareLink{WorkspaceID: workspaceID, CreatedByID: userID, PDFID: &pdfID}, opts)
+}
+
+// ShareSummary creates a link to one of the workspace's summaries
+func (s *Service) ShareSummary(workspaceID, userID, summaryID uint, opts Options) (*models.ShareLink, error) {
+	if err := s.exists(workspaceID, &models.Summaries{}, summaryID); err != nil {
+		return nil, err
+	}
+	return s.create(models.ShareLink{WorkspaceID: workspaceID, CreatedByID: userID, SummaryID: &summaryID}, opts)
+}
+
+// List returns the workspace's links, newest first
+func (s *Service) List(workspaceID uint, filter Filter) ([]models.ShareLink, error) {
+	query := s.db.Where("workspace_id = ?", workspaceID)
+	if filter.PDFID != nil {
+		query = query.Where("pdf_id = ?", *filter.PDFID)
+	}
+	if filter.SummaryID != nil {
+		query = query.Where("summary_id = ?", *filter.SummaryID)
+	}
+	if !filter.Inactive {
+		query = query.Where("revoked_at IS NULL AND expires_at > ? AND (max_downloads IS NULL OR download_count < max_downloads)", time.Now())
+	}
+
+	var links []models.ShareLink
+	err := query.Order("created_at desc, id desc").Find(&links).Error
+	return links, err
+}
+
+// Revoke stops a link from opening. Revoking twice is not an error.
+func (s *Service) Revoke(workspaceID, id uint) (*models.ShareLink, error) {
+	var link models.ShareLink
+	if err := s.db.Where("workspace_id = ?", workspaceID).First(&link, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if link.RevokedAt == nil {
+		now := time.Now()
+		if err := s.db.Model(&link).Update("revoked_at", now).Error; err != nil {
+			return nil, err
+		}
+		link.RevokedAt = &now
+	}
+	return &link, nil
+}
+
+// Open checks a token and password and returns the link with its PDF or
+// summary loaded. Nothing is counted until Record is called.
+func (s *Service) Open(token, password string) (*models.ShareLink, error) {
+	id, expires, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var link models.ShareLink
+	err = s.db.Preload("PDF").Preload("Summary").Preload("Summary.PDF").First(&link, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	// A token only opens the link it was signed for, with the expiry it was signed with
+	if link.ExpiresAt.Unix() != expires {
+		return nil, ErrInvalidToken
+	}
+
+	switch {
+	case link.RevokedAt != nil:
+		return nil, ErrRevoked
+	case !time.Now().Before(link.ExpiresAt):
+		return nil, ErrExpired
+	case link.MaxDownloads != nil && link.DownloadCount >= *link.MaxDownloads:
+		return nil, ErrExhausted
+	}
+
+	if link.PasswordHash != "" {
+		if password == "" {
+			return nil, ErrPasswordRequired
+		}
+		if !auth.CheckPassword(link.PasswordHash, password) {
+			return nil, ErrWrongPassword
+		}
+	}
+
+	// Trashed items can't be opened, their links come back if they are restored
+	if link.PDF == nil && link.Summary == nil {
+		return nil, ErrTargetNotFound
+	}
+	return &link, nil
+}
+
+// Record counts a download of link. It fails with ErrExhausted when other
+// downloads used up the link since it was opened.
+func (s *Service) Record(link *models.ShareLink) error {
+	now := time.Now()
+	result := s.db.Model(&models.ShareLink{}).
+		Where("id = ? AND revoked_at IS NULL AND (max_downloads IS NULL OR download_count < max_downloads)", link.ID).
+		Updates(map[string]interface{}{
+			"download_count":     gorm.Expr("download_count + 1"),
+			"last_downloaded_at": now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to record download: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrExhausted
+	}
+
+	link.DownloadCount++
+	link.LastDownloadedAt = &now
+	return nil
+}
+
+func (s *Service) create(link models.ShareLink, opts Options) (*models.ShareLink, error) {
+	if opts.Password != "" {
+		hash, err := auth.HashPassword(opts.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		link.PasswordHash = hash
+	}
+	// Stored to the second so it matches the expiry signed into the token
+	link.ExpiresAt = opts.ExpiresAt.Truncate(time.Second)
+	link.MaxDownloads = opts.MaxDownloads
+
+	if err := s.db.Create(&link).Error; err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+	return &link, nil
+}
+
+// exists checks that a PDF or summary is in the workspace and not trashed
+func (s *Service) exists(workspaceID uint, model interface{}, id uint) error {
+	var count int64
+	if err := s.db.Model(model).Scopes(utils.InWorkspace(workspaceID)).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrTargetNotFound
+	}
+	return nil
+}
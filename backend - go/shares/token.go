@@ -0,0 +1,70 @@
+package shares
+
+import (
+	"backend-go/models"
+	"backend-go/utils"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+)
+
+// Tokens are the link ID and expiry, 8 bytes each, followed by the first
+// macSize bytes of their HMAC-SHA256, base64url encoded
+const (
+	payloadSize = 16
+	macSize     = 16
+)
+
+// SecretFromEnv returns the key share tokens are signed with, SHARE_LINK_SECRET
+// or else JWT_SECRET. Without either a random key is generated, which breaks
+// every link whenever the server restarts.
+func SecretFromEnv() ([]byte, error) {
+	secret := []byte(utils.GetEnv("SHARE_LINK_SECRET", utils.GetEnv("JWT_SECRET", "")))
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate share link secret: %w", err)
+		}
+		fmt.Println("Warning: SHARE_LINK_SECRET is not set, share links will stop working when the server restarts")
+	} else if len(secret) < 32 {
+		return nil, fmt.Errorf("SHARE_LINK_SECRET must be at least 32 bytes")
+	}
+	return secret, nil
+}
+
+// Token is the signed token of link. It is the same every time, so links can be listed again.
+func (s *Service) Token(link models.ShareLink) string {
+	token := make([]byte, payloadSize, payloadSize+macSize)
+	binary.BigEndian.PutUint64(token[:8], uint64(link.ID))
+	binary.BigEndian.PutUint64(token[8:], uint64(link.ExpiresAt.Unix()))
+	token = append(token, s.mac(token)...)
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// verify checks a token's signature and returns the link ID and expiry it carries
+func (s *Service) verify(token string) (uint, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != payloadSize+macSize {
+		return 0, 0, ErrInvalidToken
+	}
+
+	payload := raw[:payloadSize]
+	if !hmac.Equal(raw[payloadSize:], s.mac(payload)) {
+		return 0, 0, ErrInvalidToken
+	}
+
+	id := binary.BigEndian.Uint64(payload[:8])
+	expires := int64(binary.BigEndian.Uint64(payload[8:]))
+	return uint(id), expires, nil
+}
+
+func (s *Service) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	// Domain separation keeps these signatures apart from anything else using the same secret
+	h.Write([]byte("share-link:"))
+	h.Write(payload)
+	return h.Sum(nil)[:macSize]
+}
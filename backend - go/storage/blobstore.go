@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"backend-go/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when a blob key does not exist
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore is where PDF files live. Keys are flat file names such as "<hash>.pdf".
+type BlobStore interface {
+	// Put streams r into key, replacing any existing blob. size may be -1 when unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	// Get opens key for streaming. The caller must close the returned object.
+	Get(ctx context.Context, key string) (Object, error)
+	Stat(ctx context.Context, key string) (*BlobInfo, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
+}
+
+// Object is an open blob. Both drivers support seeking, which download ranges rely on.
+type Object interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+}
+
+// BlobInfo describes a stored blob
+type BlobInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// FromEnv creates the blob store selected by STORAGE_DRIVER:
+//
+//	local  files under STORAGE_LOCAL_DIR (default "uploads")
+//	s3     an S3-compatible bucket configured by S3_ENDPOINT, S3_ACCESS_KEY,
+//	       S3_SECRET_KEY, S3_BUCKET, S3_REGION and S3_USE_SSL
+func FromEnv(ctx context.Context) (BlobStore, error) {
+	switch driver := utils.GetEnv("STORAGE_DRIVER", "local"); driver {
+	case "local":
+		return NewLocalStore(utils.GetEnv("STORAGE_LOCAL_DIR", "uploads"))
+	case "s3":
+		return NewS3Store(ctx, S3Config{
+			Endpoint:  utils.GetEnv("S3_ENDPOINT", "localhost:9000"),
+			AccessKey: utils.GetEnv("S3_ACCESS_KEY", ""),
+			SecretKey: utils.GetEnv("S3_SECRET_KEY", ""),
+			Bucket:    utils.GetEnv("S3_BUCKET", "pdfs"),
+			Region:    utils.GetEnv("S3_REGION", ""),
+			UseSSL:    utils.GetEnv("S3_USE_SSL", "false") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"backend-go/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContentStore names PDF files by the SHA-256 of their content, so identical
+// uploads share one blob. Blob rows count the references.
+type ContentStore struct {
+	db    *gorm.DB
+	blobs BlobStore
+}
+
+// Staged is an upload written to a local temporary file and hashed, not yet committed to the store.
+// Keeping a local copy lets us count pages before anything is stored.
+type Staged struct {
+	Hash string
+	Size int64
+	Path string
+
+	uploaded bool // set by Upload when it stored a new blob
+}
+
+// NewContentStore creates a content-addressed store on top of blobs
+func NewContentStore(db *gorm.DB, blobs BlobStore) *ContentStore {
+	return &ContentStore{db: db, blobs: blobs}
+}
+
+// Filename is the stored file name for a content hash
+func Filename(hash string) string {
+	return hash + ".pdf"
+}
+
+// Stage streams src into a temporary file while hashing it
+func (s *ContentStore) Stage(src io.Reader) (*Staged, error) {
+	tmp, err := os.CreateTemp("", "upload-*.pdf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), src)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to save file: %w", err)
+	}
+
+	return &Staged{
+		Hash: hex.EncodeToString(hasher.Sum(nil)),
+		Size: size,
+		Path: tmp.Name(),
+	}, nil
+}
+
+// Discard removes the local copy of a staged upload
+func (s *ContentStore) Discard(staged *Staged) {
+	os.Remove(staged.Path)
+}
+
+// Upload stores the staged content unless an identical blob is already
+// stored. Call it before the transaction that Acquires the content, never
+// inside it, and Abandon the upload if that transaction fails.
+func (s *ContentStore) Upload(ctx context.Context, staged *Staged) error {
+	key := Filename(staged.Hash)
+	if _, err := s.blobs.Stat(ctx, key); err == nil {
+		return nil
+	} else if err != ErrNotFound {
+		return err
+	}
+
+	file, err := os.Open(staged.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open staged file: %w", err)
+	}
+	defer file.Close()
+
+	if err := s.blobs.Put(ctx, key, file, staged.Size); err != nil {
+		return err
+	}
+	staged.uploaded = true
+	return nil
+}
+
+// Abandon deletes a blob stored by Upload whose reference was never
+// committed, unless another upload of the same content references it by now
+func (s *ContentStore) Abandon(ctx context.Context, staged *Staged) {
+	if !staged.uploaded {
+		return
+	}
+	s.Remove(ctx, []string{Filename(staged.Hash)})
+	staged.uploaded = false
+}
+
+// Acquire takes a reference on uploaded content inside tx
+func (s *ContentStore) Acquire(tx *gorm.DB, staged *Staged) error {
+	blob := models.Blob{Hash: staged.Hash, Size: staged.Size, RefCount: 1}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"ref_count": gorm.Expr("blobs.ref_count + 1")}),
+	}).Create(&blob).Error; err != nil {
+		return fmt.Errorf("failed to reference blob: %w", err)
+	}
+	return nil
+}
+
+// Release drops a reference on a stored file inside tx and returns the keys
+// of files nothing uses anymore. Pass them to Remove once tx has committed,
+// so a rolled back transaction never loses a file.
+// Files without a content hash predate deduplication and are owned outright.
+func (s *ContentStore) Release(tx *gorm.DB, filename, hash string) ([]string, error) {
+	if hash == "" {
+		return []string{filename}, nil
+	}
+
+	var blob models.Blob
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&blob, "hash = ?", hash).Error
+	if err == gorm.ErrRecordNotFound {
+		return []string{filename}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find blob: %w", err)
+	}
+
+	if blob.RefCount > 1 {
+		return nil, tx.Model(&blob).Update("ref_count", gorm.Expr("ref_count - 1")).Error
+	}
+
+	if err := tx.Delete(&blob).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return []string{filename}, nil
+}
+
+// ReleasePDF releases the files of every version of pdf inside tx. Call it
+// before deleting the PDF because its versions are deleted with it.
+func (s *ContentStore) ReleasePDF(tx *gorm.DB, pdf models.PDF) ([]string, error) {
+	var versions []models.PDFVersion
+	if err := tx.Where("pdf_id = ?", pdf.ID).Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to find PDF versions: %w", err)
+	}
+
+	// Records without versions only reference their current file
+	if len(versions) == 0 {
+		return s.Release(tx, pdf.Filename, pdf.ContentHash)
+	}
+
+	var keys []string
+	for _, version := range versions {
+		released, err := s.Release(tx, version.Filename, version.ContentHash)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, released...)
+	}
+	return keys, nil
+}
+
+// Remove deletes released files, skipping any that were referenced again
+// since they were released. Failures only leave an unreferenced file behind,
+// so they are logged rather than returned.
+func (s *ContentStore) Remove(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		var referenced int64
+		if err := s.db.Model(&models.Blob{}).
+			Where("hash = ?", strings.TrimSuffix(key, ".pdf")).
+			Count(&referenced).Error; err != nil {
+			fmt.Printf("Failed to check references to %s: %v\n", key, err)
+			continue
+		}
+		if referenced > 0 {
+			continue
+		}
+
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			fmt.Printf("Failed to delete file %s: %v\n", key, err)
+		}
+	}
+}
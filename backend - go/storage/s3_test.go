@@ -0,0 +1,352 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is a minimal path-style S3 stand-in with one bucket. Keys starting
+// with "forbidden" answer AccessDenied to exercise error mapping.
+type fakeS3 struct {
+	mu            sync.Mutex
+	bucket        string
+	bucketCreated bool
+	objects       map[string][]byte
+	ranges        []string
+	missingDelete bool // answer NoSuchKey to deletes of missing keys, as some S3-compatible services do
+}
+
+func newFakeS3(t *testing.T, bucket string) (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{bucket: bucket, objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		s3Error(w, http.StatusNotFound, "NoSuchBucket", r.Method)
+		return
+	}
+
+	if key == "" {
+		switch {
+		case r.Method == http.MethodHead && !f.bucketCreated:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPut:
+			f.bucketCreated = true
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+			f.list(w, r.URL.Query().Get("prefix"))
+		default:
+			s3Error(w, http.StatusNotImplemented, "NotImplemented", r.Method)
+		}
+		return
+	}
+
+	if strings.HasPrefix(key, "forbidden") {
+		s3Error(w, http.StatusForbidden, "AccessDenied", r.Method)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		body, err := readPayload(r)
+		if err != nil {
+			s3Error(w, http.StatusBadRequest, "IncompleteBody", r.Method)
+			return
+		}
+		f.objects[key] = body
+		w.Header().Set("ETag", etagOf(body))
+		w.WriteHeader(http.StatusOK)
+
+	case http.MethodGet, http.MethodHead:
+		body, ok := f.objects[key]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchKey", r.Method)
+			return
+		}
+		if rng := r.Header.Get("Range"); rng != "" {
+			f.ranges = append(f.ranges, rng)
+		}
+		w.Header().Set("ETag", etagOf(body))
+		w.Header().Set("Content-Type", "application/pdf")
+		http.ServeContent(w, r, key, time.Unix(1700000000, 0), bytes.NewReader(body))
+
+	case http.MethodDelete:
+		if _, ok := f.objects[key]; !ok && f.missingDelete {
+			s3Error(w, http.StatusNotFound, "NoSuchKey", r.Method)
+			return
+		}
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s3Error(w, http.StatusNotImplemented, "NotImplemented", r.Method)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, prefix string) {
+	type content struct {
+		Key          string
+		LastModified string
+		Size         int64
+		ETag         string
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		MaxKeys     int
+		IsTruncated bool
+		Contents    []content
+	}{Name: f.bucket, Prefix: prefix, MaxKeys: 1000}
+
+	for key, body := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, content{
+				Key:          key,
+				LastModified: time.Unix(1700000000, 0).UTC().Format("2006-01-02T15:04:05.000Z"),
+				Size:         int64(len(body)),
+				ETag:         etagOf(body),
+			})
+		}
+	}
+	result.KeyCount = len(result.Contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+// s3Error writes an S3 error document. HEAD responses carry no body, like real S3.
+func s3Error(w http.ResponseWriter, status int, code, method string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	if method != http.MethodHead {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+	}
+}
+
+func etagOf(body []byte) string {
+	return `"` + strconv.Itoa(len(body)) + `"`
+}
+
+// readPayload returns the object bytes of a PUT, decoding the aws-chunked
+// encoding the client uses for streaming signatures over plain HTTP
+func readPayload(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var body bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return body.Bytes(), nil
+		}
+		if _, err := io.CopyN(&body, reader, size); err != nil {
+			return nil, err
+		}
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func newTestS3Store(t *testing.T, server *httptest.Server) *S3Store {
+	store, err := NewS3Store(context.Background(), S3Config{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		AccessKey: "access",
+		SecretKey: "secret",
+		Bucket:    "pdfs",
+		Region:    "us-east-1",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store: %v", err)
+	}
+	return store
+}
+
+func TestS3StoreCreatesMissingBucket(t *testing.T) {
+	fake, server := newFakeS3(t, "pdfs")
+	newTestS3Store(t, server)
+
+	if !fake.bucketCreated {
+		t.Fatal("bucket was not created")
+	}
+}
+
+func TestS3StorePutGetDelete(t *testing.T) {
+	fake, server := newFakeS3(t, "pdfs")
+	store := newTestS3Store(t, server)
+	ctx := context.Background()
+	content := []byte("%PDF-1.4 pretend this is a document")
+
+	if err := store.Put(ctx, "abc.pdf", bytes.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if !bytes.Equal(fake.objects["abc.pdf"], content) {
+		t.Fatalf("stored %q, want %q", fake.objects["abc.pdf"], content)
+	}
+
+	info, err := store.Stat(ctx, "abc.pdf")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Key != "abc.pdf" || info.Size != int64(len(content)) || info.ModTime.IsZero() {
+		t.Errorf("Stat = %+v", info)
+	}
+
+	object, err := store.Get(ctx, "abc.pdf")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(object)
+	object.Close()
+	if err != nil {
+		t.Fatalf("read object: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("Get = %q, want %q", got, content)
+	}
+
+	blobs, err := store.List(ctx, "abc")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(blobs) != 1 || blobs[0].Key != "abc.pdf" || blobs[0].Size != int64(len(content)) {
+		t.Errorf("List = %+v", blobs)
+	}
+
+	if err := store.Delete(ctx, "abc.pdf"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := fake.objects["abc.pdf"]; ok {
+		t.Error("object still stored after Delete")
+	}
+}
+
+func TestS3StoreRange(t *testing.T) {
+	fake, server := newFakeS3(t, "pdfs")
+	store := newTestS3Store(t, server)
+	ctx := context.Background()
+	content := []byte("0123456789abcdefghij")
+	fake.objects["range.pdf"] = content
+
+	object, err := store.Get(ctx, "range.pdf")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer object.Close()
+
+	// Downloads find the size by seeking to the end, then read each range through ReadAt
+	size, err := object.Seek(0, io.SeekEnd)
+	if err != nil || size != int64(len(content)) {
+		t.Fatalf("Seek(0, SeekEnd) = %d, %v", size, err)
+	}
+	if _, err := object.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek(0, SeekStart): %v", err)
+	}
+
+	for _, tt := range []struct {
+		start, length int64
+		want          string
+	}{
+		{10, 5, "abcde"},
+		{0, 3, "012"},
+		{15, 5, "fghij"},
+	} {
+		got, err := io.ReadAll(io.NewSectionReader(object, tt.start, tt.length))
+		if err != nil {
+			t.Fatalf("read %d+%d: %v", tt.start, tt.length, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("read %d+%d = %q, want %q", tt.start, tt.length, got, tt.want)
+		}
+	}
+
+	if len(fake.ranges) == 0 {
+		t.Error("expected ranged requests, the whole object was fetched")
+	}
+}
+
+func TestS3StoreErrorMapping(t *testing.T) {
+	fake, server := newFakeS3(t, "pdfs")
+	store := newTestS3Store(t, server)
+	ctx := context.Background()
+
+	if _, err := store.Stat(ctx, "missing.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat(missing) = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get(ctx, "missing.pdf"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Delete(ctx, "missing.pdf"); err != nil {
+		t.Errorf("Delete(missing) = %v, want nil", err)
+	}
+	fake.missingDelete = true
+	if err := store.Delete(ctx, "missing.pdf"); err != nil {
+		t.Errorf("Delete(missing) answered with NoSuchKey = %v, want nil", err)
+	}
+
+	// Other failures are reported, never mistaken for a missing blob
+	if _, err := store.Stat(ctx, "forbidden.pdf"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat(forbidden) = %v, want an access error", err)
+	}
+	if _, err := store.Get(ctx, "forbidden.pdf"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(forbidden) = %v, want an access error", err)
+	}
+	if err := store.Delete(ctx, "forbidden.pdf"); err == nil {
+		t.Error("Delete(forbidden) succeeded")
+	}
+	content := []byte("data")
+	if err := store.Put(ctx, "forbidden.pdf", bytes.NewReader(content), int64(len(content))); err == nil {
+		t.Error("Put(forbidden) succeeded")
+	}
+}
+
+func TestNewS3StoreReportsUnreachableEndpoint(t *testing.T) {
+	_, server := newFakeS3(t, "pdfs")
+	server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := NewS3Store(ctx, S3Config{
+		Endpoint: strings.TrimPrefix(server.URL, "http://"),
+		Bucket:   "pdfs",
+		Region:   "us-east-1",
+	})
+	if err == nil {
+		t.Fatal("expected an error for an unreachable endpoint")
+	}
+}
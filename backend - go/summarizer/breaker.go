@@ -0,0 +1,137 @@
+package summarizer
+
+import (
+	"sync"
+	"time"
+)
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// Breaker stops calling a provider after repeated failures and lets a single
+// trial request through once the cooldown has passed
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	trial     bool
+	now       func() time.Time
+}
+
+// BreakerStatus is a snapshot of a breaker for health reporting
+type BreakerStatus struct {
+	State               string `json:"state"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	RetryAfterSeconds   int    `json:"retry_after_seconds,omitempty"`
+}
+
+// NewBreaker opens after threshold consecutive failures and stays open for cooldown
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether a call may proceed, or how long to wait if not
+func (b *Breaker) Allow() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		remaining := b.cooldown - b.now().Sub(b.openedAt)
+		if remaining > 0 {
+			return remaining, false
+		}
+		b.state = BreakerHalfOpen
+		b.trial = true
+		return 0, true
+	case BreakerHalfOpen:
+		// Only one trial call at a time while half open
+		if b.trial {
+			return b.cooldown, false
+		}
+		b.trial = true
+		return 0, true
+	default:
+		return 0, true
+	}
+}
+
+// Peek reports how long callers should wait without claiming the half-open trial
+func (b *Breaker) Peek() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		if remaining := b.cooldown - b.now().Sub(b.openedAt); remaining > 0 {
+			return remaining, false
+		}
+	}
+	return 0, true
+}
+
+// Success closes the breaker
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trial = false
+}
+
+// Release gives up a half-open trial without recording an outcome
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// Failure records a failed call and opens the breaker once the threshold is reached
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Status returns a snapshot of the breaker
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+
+	if b.state == BreakerOpen {
+		if remaining := b.cooldown - b.now().Sub(b.openedAt); remaining > 0 {
+			status.RetryAfterSeconds = int(remaining.Seconds()) + 1
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,121 @@
+package summarizer
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock drives a Breaker's notion of now
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*Breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	breaker := NewBreaker(threshold, cooldown)
+	breaker.now = clock.Now
+	return breaker, clock
+}
+
+func TestBreakerOpensAtThreshold(t *testing.T) {
+	breaker, _ := newTestBreaker(3, time.Minute)
+
+	for i := 1; i < 3; i++ {
+		breaker.Failure()
+		if _, ok := breaker.Allow(); !ok {
+			t.Fatalf("open after %d failures, threshold is 3", i)
+		}
+	}
+
+	// A success in between starts the count again
+	breaker.Success()
+	breaker.Failure()
+	breaker.Failure()
+	if _, ok := breaker.Allow(); !ok {
+		t.Fatal("failures before a success counted towards the threshold")
+	}
+
+	breaker.Failure()
+	wait, ok := breaker.Allow()
+	if ok {
+		t.Fatal("still closed at the threshold")
+	}
+	if wait != time.Minute {
+		t.Errorf("wait = %s, want the 1m cooldown", wait)
+	}
+
+	status := breaker.Status()
+	if status.State != BreakerOpen || status.ConsecutiveFailures != 3 || status.RetryAfterSeconds != 61 {
+		t.Errorf("Status = %+v", status)
+	}
+}
+
+func TestBreakerAllowsOneHalfOpenTrial(t *testing.T) {
+	breaker, clock := newTestBreaker(1, time.Minute)
+	breaker.Failure()
+
+	clock.Advance(30 * time.Second)
+	if wait, ok := breaker.Allow(); ok || wait != 30*time.Second {
+		t.Fatalf("Allow during the cooldown = %s, %v", wait, ok)
+	}
+	if wait, ok := breaker.Peek(); ok || wait != 30*time.Second {
+		t.Fatalf("Peek during the cooldown = %s, %v", wait, ok)
+	}
+
+	clock.Advance(30 * time.Second)
+	// Peek never claims the trial
+	if _, ok := breaker.Peek(); !ok {
+		t.Fatal("Peek refused after the cooldown")
+	}
+	if _, ok := breaker.Allow(); !ok {
+		t.Fatal("trial refused after the cooldown")
+	}
+	if breaker.Status().State != BreakerHalfOpen {
+		t.Errorf("state = %s, want half open", breaker.Status().State)
+	}
+	for i := 0; i < 3; i++ {
+		if _, ok := breaker.Allow(); ok {
+			t.Fatal("a second call was allowed during the half-open trial")
+		}
+	}
+
+	// A failed trial opens the breaker for another cooldown
+	breaker.Failure()
+	if _, ok := breaker.Allow(); ok {
+		t.Fatal("allowed right after a failed trial")
+	}
+
+	clock.Advance(time.Minute)
+	if _, ok := breaker.Allow(); !ok {
+		t.Fatal("trial refused after the second cooldown")
+	}
+	breaker.Success()
+	if status := breaker.Status(); status.State != BreakerClosed || status.ConsecutiveFailures != 0 {
+		t.Errorf("Status after a successful trial = %+v", status)
+	}
+	for i := 0; i < 3; i++ {
+		if _, ok := breaker.Allow(); !ok {
+			t.Fatal("closed breaker refused a call")
+		}
+	}
+}
+
+func TestBreakerReleaseGivesBackTheTrial(t *testing.T) {
+	breaker, clock := newTestBreaker(1, time.Minute)
+	breaker.Failure()
+	clock.Advance(time.Minute)
+
+	if _, ok := breaker.Allow(); !ok {
+		t.Fatal("trial refused after the cooldown")
+	}
+	breaker.Release()
+
+	if status := breaker.Status(); status.State != BreakerHalfOpen || status.ConsecutiveFailures != 1 {
+		t.Errorf("Status after Release = %+v, want half open with the failure unchanged", status)
+	}
+	if _, ok := breaker.Allow(); !ok {
+		t.Fatal("the trial was not given back")
+	}
+}
@@ -0,0 +1,95 @@
+package summarizer
+
+import (
+	"backend-go/utils"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// FromEnv builds a Router from environment variables:
+//
+//	PYTHON_API_URL      Python service address (always registered as "python")
+//	OPENAI_API_URL      OpenAI-compatible base URL, registers "openai" when it or OPENAI_API_KEY is set
+//	OPENAI_API_KEY      bearer token for the OpenAI-compatible API
+//	OPENAI_MODEL        chat model name
+//	OLLAMA_API_URL      Ollama base URL, registers "ollama" when set
+//	OLLAMA_MODEL        Ollama model name
+//	SUMMARIZER_DEFAULT  provider used when no route matches (default "python")
+//	SUMMARIZER_ROUTES   comma separated style/language=provider rules, e.g. "detailed/*=openai,*/indonesian=ollama"
+//
+// Every provider is wrapped with retries and a circuit breaker:
+//
+//	SUMMARIZER_TIMEOUT            per-attempt timeout (default 5m)
+//	SUMMARIZER_MAX_RETRIES        retries after the first attempt (default 0)
+//	SUMMARIZER_RETRY_BASE_DELAY   first backoff delay, doubled per retry (default 1s)
+//	SUMMARIZER_RETRY_MAX_DELAY    backoff ceiling (default 30s)
+//	SUMMARIZER_BREAKER_THRESHOLD  consecutive failures before opening (default 5)
+//	SUMMARIZER_BREAKER_COOLDOWN   time the breaker stays open (default 30s)
+//
+// Summaries run inside summary jobs, which are already retried up to
+// SUMMARY_MAX_ATTEMPTS times, and every job attempt makes up to
+// SUMMARIZER_MAX_RETRIES+1 provider calls. Raise one of the two, not both.
+func FromEnv() (*Router, error) {
+	providers := []Summarizer{
+		NewPython(utils.GetEnv("PYTHON_API_URL", "127.0.0.1:8000")),
+	}
+
+	if os.Getenv("OPENAI_API_URL") != "" || os.Getenv("OPENAI_API_KEY") != "" {
+		providers = append(providers, NewOpenAI(
+			utils.GetEnv("OPENAI_API_URL", "https://api.openai.com/v1"),
+			os.Getenv("OPENAI_API_KEY"),
+			utils.GetEnv("OPENAI_MODEL", "gpt-4o-mini"),
+		))
+	}
+
+	if url := os.Getenv("OLLAMA_API_URL"); url != "" {
+		providers = append(providers, NewOllama(url, utils.GetEnv("OLLAMA_MODEL", "llama3.1")))
+	}
+
+	policy := RetryPolicy{
+		Timeout:    utils.GetEnvDuration("SUMMARIZER_TIMEOUT", 5*time.Minute),
+		MaxRetries: utils.GetEnvInt("SUMMARIZER_MAX_RETRIES", 0),
+		BaseDelay:  utils.GetEnvDuration("SUMMARIZER_RETRY_BASE_DELAY", time.Second),
+		MaxDelay:   utils.GetEnvDuration("SUMMARIZER_RETRY_MAX_DELAY", 30*time.Second),
+	}
+	threshold := utils.GetEnvInt("SUMMARIZER_BREAKER_THRESHOLD", 5)
+	cooldown := utils.GetEnvDuration("SUMMARIZER_BREAKER_COOLDOWN", 30*time.Second)
+
+	for i, provider := range providers {
+		providers[i] = NewResilient(provider, policy, NewBreaker(threshold, cooldown))
+	}
+
+	defaultName := utils.GetEnv("SUMMARIZER_DEFAULT", "python")
+
+	var router *Router
+	for _, provider := range providers {
+		if provider.Name() == defaultName {
+			router = NewRouter(provider)
+		}
+	}
+	if router == nil {
+		return nil, fmt.Errorf("default summarizer %q is not configured", defaultName)
+	}
+
+	for _, provider := range providers {
+		router.Register(provider)
+	}
+
+	for _, rule := range strings.Split(os.Getenv("SUMMARIZER_ROUTES"), ",") {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+
+		pattern, provider, ok := strings.Cut(rule, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid summarizer route %q, expected style/language=provider", rule)
+		}
+		if err := router.Route(pattern, strings.TrimSpace(provider)); err != nil {
+			return nil, err
+		}
+	}
+
+	return router, nil
+}
@@ -0,0 +1,64 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// StatusError is returned when a provider answers with a non-200 status
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s error (%d): %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// newStatusError captures the status, body and any Retry-After hint of a failed response
+func newStatusError(provider string, resp *http.Response, body []byte) *StatusError {
+	err := &StatusError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+	}
+
+	if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+		err.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return err
+}
+
+// CircuitOpenError is returned without calling the provider while its breaker is open
+type CircuitOpenError struct {
+	Provider   string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s summarizer is unavailable, retry after %s", e.Provider, e.RetryAfter.Round(time.Second))
+}
+
+// isRetryable reports whether err is a transient failure worth another attempt:
+// connection errors, per-attempt timeouts, 429 and 5xx responses
+func isRetryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
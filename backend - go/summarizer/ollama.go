@@ -0,0 +1,92 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Ollama talks to a local Ollama-style /api/generate endpoint
+type Ollama struct {
+	BaseURL string
+	Model   string
+	Client  *http.Client
+}
+
+// NewOllama creates an Ollama summarizer, e.g. NewOllama("http://localhost:11434", "llama3.1")
+func NewOllama(baseURL, model string) *Ollama {
+	return &Ollama{
+		BaseURL: normalizeBaseURL(baseURL),
+		Model:   model,
+		Client:  &http.Client{},
+	}
+}
+
+func (o *Ollama) Name() string {
+	return "ollama"
+}
+
+type ollamaGenerateRequest struct {
+	Model   string                 `json:"model"`
+	Prompt  string                 `json:"prompt"`
+	Stream  bool                   `json:"stream"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+	Error    string `json:"error"`
+}
+
+func (o *Ollama) Summarize(ctx context.Context, doc Document, opts Options) (*Result, error) {
+	result, err := summarizeText(ctx, doc, opts, o.complete)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Provider = o.Name()
+	result.Model = o.Model
+	return result, nil
+}
+
+func (o *Ollama) complete(ctx context.Context, prompt string) (string, error) {
+	payload, err := json.Marshal(ollamaGenerateRequest{
+		Model:   o.Model,
+		Prompt:  prompt,
+		Stream:  false,
+		Options: map[string]interface{}{"temperature": 0.5},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/api/generate", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to build generate request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.Client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to Ollama: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", newStatusError(o.Name(), resp, bodyBytes)
+	}
+
+	var generated ollamaGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&generated); err != nil {
+		return "", fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+	if generated.Error != "" {
+		return "", fmt.Errorf("Ollama error: %s", generated.Error)
+	}
+
+	return generated.Response, nil
+}
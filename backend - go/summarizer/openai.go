@@ -0,0 +1,105 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// OpenAI talks to any OpenAI-compatible chat completions API
+// (OpenAI, Azure-style gateways, vLLM, LM Studio, ...)
+type OpenAI struct {
+	BaseURL string
+	APIKey  string
+	Model   string
+	Client  *http.Client
+}
+
+// NewOpenAI creates an OpenAI-compatible summarizer. baseURL should include the API version, e.g. https://api.openai.com/v1
+func NewOpenAI(baseURL, apiKey, model string) *OpenAI {
+	return &OpenAI{
+		BaseURL: normalizeBaseURL(baseURL),
+		APIKey:  apiKey,
+		Model:   model,
+		Client:  &http.Client{},
+	}
+}
+
+func (o *OpenAI) Name() string {
+	return "openai"
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (o *OpenAI) Summarize(ctx context.Context, doc Document, opts Options) (*Result, error) {
+	result, err := summarizeText(ctx, doc, opts, o.complete)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Provider = o.Name()
+	result.Model = o.Model
+	return result, nil
+}
+
+func (o *OpenAI) complete(ctx context.Context, prompt string) (string, error) {
+	payload, err := json.Marshal(chatCompletionRequest{
+		Model: o.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: "You summarize PDF documents accurately and concisely."},
+			{Role: "user", Content: prompt},
+		},
+		Temperature: 0.5,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.BaseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to build chat completion request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := o.Client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to OpenAI-compatible API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", newStatusError(o.Name(), resp, bodyBytes)
+	}
+
+	var completion chatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return "", fmt.Errorf("failed to parse chat completion: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("chat completion returned no choices")
+	}
+
+	return completion.Choices[0].Message.Content, nil
+}
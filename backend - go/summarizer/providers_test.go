@@ -0,0 +1,150 @@
+package summarizer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOpenAIComplete(t *testing.T) {
+	var got chatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"A summary"}}]}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAI(server.URL+"/v1/", "secret", "test-model")
+	reply, err := provider.complete(context.Background(), "Summarize this")
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if reply != "A summary" {
+		t.Errorf("reply = %q", reply)
+	}
+	if got.Model != "test-model" || len(got.Messages) != 2 || got.Messages[1].Content != "Summarize this" {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+func TestOpenAICompleteNoChoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[]}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewOpenAI(server.URL, "", "m").complete(context.Background(), "x"); err == nil {
+		t.Fatal("expected an error for a completion without choices")
+	}
+}
+
+func TestOllamaComplete(t *testing.T) {
+	var got ollamaGenerateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"response":"Ringkasan"}`))
+	}))
+	defer server.Close()
+
+	reply, err := NewOllama(server.URL, "llama3.1").complete(context.Background(), "Ringkas ini")
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if reply != "Ringkasan" {
+		t.Errorf("reply = %q", reply)
+	}
+	if got.Model != "llama3.1" || got.Prompt != "Ringkas ini" || got.Stream {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+func TestOllamaCompleteReportsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"model not found"}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewOllama(server.URL, "missing").complete(context.Background(), "x"); err == nil {
+		t.Fatal("expected the error in the response body to be returned")
+	}
+}
+
+func TestProviderStatusErrors(t *testing.T) {
+	tests := []struct {
+		status     int
+		retryAfter string
+		retryable  bool
+		wait       time.Duration
+	}{
+		{http.StatusTooManyRequests, "7", true, 7 * time.Second},
+		{http.StatusServiceUnavailable, "", true, 0},
+		{http.StatusBadRequest, "", false, 0},
+		{http.StatusUnauthorized, "", false, 0},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tt.retryAfter != "" {
+				w.Header().Set("Retry-After", tt.retryAfter)
+			}
+			w.WriteHeader(tt.status)
+			w.Write([]byte("upstream says no"))
+		}))
+
+		providers := map[string]completeFunc{
+			"openai": NewOpenAI(server.URL, "", "m").complete,
+			"ollama": NewOllama(server.URL, "m").complete,
+		}
+		for name, complete := range providers {
+			_, err := complete(context.Background(), "x")
+
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) {
+				t.Errorf("%s %d: error %v is not a StatusError", name, tt.status, err)
+				continue
+			}
+			if statusErr.Provider != name || statusErr.StatusCode != tt.status || statusErr.Body != "upstream says no" {
+				t.Errorf("%s %d: got %+v", name, tt.status, statusErr)
+			}
+			if statusErr.RetryAfter != tt.wait {
+				t.Errorf("%s %d: RetryAfter = %s, want %s", name, tt.status, statusErr.RetryAfter, tt.wait)
+			}
+			if isRetryable(err) != tt.retryable {
+				t.Errorf("%s %d: isRetryable = %v, want %v", name, tt.status, !tt.retryable, tt.retryable)
+			}
+		}
+		server.Close()
+	}
+}
+
+func TestProviderTimeoutIsRetryable(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := NewOpenAI(server.URL, "", "m").complete(ctx, "x")
+	if err == nil || !isRetryable(err) {
+		t.Fatalf("expected a retryable timeout, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package summarizer
+
+import (
+	"backend-go/dto"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// Python calls the FastAPI /summarize service, which extracts text and talks to Gemini itself
+type Python struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewPython creates a Python summarizer. A bare host:port address is treated as http.
+func NewPython(address string) *Python {
+	return &Python{
+		BaseURL: normalizeBaseURL(address),
+		Client:  &http.Client{},
+	}
+}
+
+func (p *Python) Name() string {
+	return "python"
+}
+
+func (p *Python) Summarize(ctx context.Context, doc Document, opts Options) (*Result, error) {
+	file, err := doc.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF file: %w", err)
+	}
+
+	// The form is written while it is sent, so the PDF is never held in memory
+	body, pipe := io.Pipe()
+	writer := multipart.NewWriter(pipe)
+	written := make(chan error, 1)
+	go func() {
+		err := writeForm(writer, file, doc.Filename, opts)
+		file.Close()
+		written <- err
+		pipe.CloseWithError(err)
+	}()
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.BaseURL+"/summarize", body)
+	if err != nil {
+		body.Close()
+		return nil, fmt.Errorf("failed to build summarize request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := p.Client.Do(httpReq)
+	if err != nil {
+		// A PDF that can't be read is not a connection problem worth retrying.
+		// The form stops with ErrClosedPipe when the request failed first.
+		if formErr := <-written; formErr != nil && !errors.Is(formErr, io.ErrClosedPipe) {
+			return nil, formErr
+		}
+		return nil, fmt.Errorf("failed to connect to Python backend: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, newStatusError(p.Name(), resp, bodyBytes)
+	}
+
+	var pythonResponse dto.PythonSummaryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pythonResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &Result{
+		Title:            pythonResponse.Title,
+		Summary:          pythonResponse.Summary.MainSummary,
+		Style:            pythonResponse.Style,
+		Language:         pythonResponse.Language,
+		WordCount:        pythonResponse.Summary.WordCount,
+		ReadingTime:      pythonResponse.Summary.ReadingTime,
+		TextStats:        pythonResponse.TextStats,
+		OriginalFilename: pythonResponse.FileInfo.OriginalFilename,
+		FileSize:         int64(pythonResponse.FileInfo.FileSize),
+		ChunksProcessed:  pythonResponse.ProcessInfo.ChunksProcessed,
+		ChunkingUsed:     pythonResponse.ProcessInfo.ChunkingUsed,
+		ProcessingTime:   pythonResponse.ProcessInfo.ProcessingTimeSeconds,
+		Provider:         p.Name(),
+		Model:            "gemini",
+	}, nil
+}
+
+// writeForm writes the form the service expects: the PDF and the summary options
+func writeForm(writer *multipart.Writer, file io.Reader, filename string, opts Options) error {
+	filePart, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := io.Copy(filePart, file); err != nil {
+		return fmt.Errorf("failed to read PDF file: %w", err)
+	}
+
+	if err := writer.WriteField("style", opts.Style); err != nil {
+		return err
+	}
+	if err := writer.WriteField("language", opts.Language); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// normalizeBaseURL accepts either a full URL or the host:port form used by PYTHON_API_URL
+func normalizeBaseURL(address string) string {
+	address = strings.TrimRight(address, "/")
+	if !strings.HasPrefix(address, "http://") && !strings.HasPrefix(address, "https://") {
+		address = "http://" + address
+	}
+	return address
+}
@@ -0,0 +1,192 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testDocument(content []byte) Document {
+	return Document{
+		Filename: "report.pdf",
+		Size:     int64(len(content)),
+		Open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(content)), nil
+		},
+	}
+}
+
+func TestPythonSummarize(t *testing.T) {
+	content := bytes.Repeat([]byte("%PDF-1.4 "), 1000)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/summarize" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		// Streamed, so the length isn't known up front
+		if r.ContentLength != -1 {
+			t.Errorf("ContentLength = %d, want a streamed body", r.ContentLength)
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile: %v", err)
+			http.Error(w, "no file", http.StatusUnprocessableEntity)
+			return
+		}
+		defer file.Close()
+		got, _ := io.ReadAll(file)
+		if header.Filename != "report.pdf" || !bytes.Equal(got, content) {
+			t.Errorf("file %q with %d bytes, want report.pdf with %d", header.Filename, len(got), len(content))
+		}
+		if style, language := r.FormValue("style"), r.FormValue("language"); style != "detailed" || language != "indonesian" {
+			t.Errorf("style = %q, language = %q", style, language)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"title": "Report",
+			"summary": {"main_summary": "Ringkasan", "word_count": 120, "reading_time": "1 min"},
+			"language": "indonesian",
+			"style": "detailed",
+			"file_info": {"original_filename": "report.pdf", "file_size": 9000},
+			"text_statistics": {"pages": 3},
+			"processing_info": {"chunks_processed": 2, "chunking_used": true, "processing_time_seconds": 1.5},
+			"status": "success"
+		}`))
+	}))
+	defer server.Close()
+
+	result, err := NewPython(server.URL).Summarize(context.Background(), testDocument(content), Options{Style: "detailed", Language: "indonesian"})
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+
+	want := Result{
+		Title:            "Report",
+		Summary:          "Ringkasan",
+		Style:            "detailed",
+		Language:         "indonesian",
+		WordCount:        120,
+		ReadingTime:      "1 min",
+		OriginalFilename: "report.pdf",
+		FileSize:         9000,
+		ChunksProcessed:  2,
+		ChunkingUsed:     true,
+		ProcessingTime:   1.5,
+		Provider:         "python",
+		Model:            "gemini",
+		TextStats:        map[string]interface{}{"pages": float64(3)},
+	}
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("result = %+v, want %+v", *result, want)
+	}
+}
+
+func TestPythonSummarizeStatusErrors(t *testing.T) {
+	tests := []struct {
+		status     int
+		retryAfter string
+		retryable  bool
+		wantRetry  time.Duration
+	}{
+		{http.StatusServiceUnavailable, "7", true, 7 * time.Second},
+		{http.StatusTooManyRequests, "", true, 0},
+		{http.StatusInternalServerError, "", true, 0},
+		{http.StatusUnprocessableEntity, "", false, 0},
+		{http.StatusBadRequest, "", false, 0},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			if tt.retryAfter != "" {
+				w.Header().Set("Retry-After", tt.retryAfter)
+			}
+			w.WriteHeader(tt.status)
+			w.Write([]byte(`{"detail":"nope"}`))
+		}))
+
+		_, err := NewPython(server.URL).Summarize(context.Background(), testDocument([]byte("%PDF-1.4")), Options{})
+		server.Close()
+
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) {
+			t.Errorf("%d: error = %v, want a StatusError", tt.status, err)
+			continue
+		}
+		if statusErr.Provider != "python" || statusErr.StatusCode != tt.status || statusErr.Body != `{"detail":"nope"}` || statusErr.RetryAfter != tt.wantRetry {
+			t.Errorf("%d: StatusError = %+v", tt.status, statusErr)
+		}
+		if isRetryable(err) != tt.retryable {
+			t.Errorf("%d: isRetryable = %v, want %v", tt.status, !tt.retryable, tt.retryable)
+		}
+	}
+}
+
+func TestPythonSummarizeInvalidResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>not json</html>"))
+	}))
+	defer server.Close()
+
+	_, err := NewPython(server.URL).Summarize(context.Background(), testDocument([]byte("%PDF-1.4")), Options{})
+	if err == nil || !strings.Contains(err.Error(), "failed to parse response") {
+		t.Errorf("error = %v, want a parse error", err)
+	}
+}
+
+// failingReader returns some bytes, then fails like a broken disk or blob store
+type failingReader struct{ sent bool }
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.sent {
+		return 0, errors.New("blob store went away")
+	}
+	r.sent = true
+	return copy(p, "%PDF-1.4"), nil
+}
+
+func (r *failingReader) Close() error { return nil }
+
+func TestPythonSummarizeUnreadablePDF(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	doc := Document{
+		Filename: "report.pdf",
+		Open:     func() (io.ReadCloser, error) { return &failingReader{}, nil },
+	}
+	_, err := NewPython(server.URL).Summarize(context.Background(), doc, Options{})
+	if err == nil || !strings.Contains(err.Error(), "failed to read PDF file") {
+		t.Fatalf("error = %v, want the read error", err)
+	}
+	if isRetryable(err) {
+		t.Error("a PDF that can't be read was reported as retryable")
+	}
+
+	doc.Open = func() (io.ReadCloser, error) { return nil, errors.New("missing") }
+	if _, err := NewPython(server.URL).Summarize(context.Background(), doc, Options{}); err == nil || !strings.Contains(err.Error(), "failed to open PDF file") {
+		t.Errorf("error = %v, want the open error", err)
+	}
+}
+
+func TestPythonSummarizeUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	_, err := NewPython(server.URL).Summarize(context.Background(), testDocument([]byte("%PDF-1.4")), Options{})
+	if err == nil || !isRetryable(err) {
+		t.Errorf("error = %v, want a retryable connection error", err)
+	}
+}
@@ -0,0 +1,145 @@
+package summarizer
+
+import (
+	"backend-go/metrics"
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls per-attempt timeouts and backoff between attempts
+type RetryPolicy struct {
+	Timeout    time.Duration
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// Resilient wraps a provider with per-attempt timeouts, retries with
+// exponential backoff and jitter, and a circuit breaker
+type Resilient struct {
+	inner   Summarizer
+	policy  RetryPolicy
+	breaker *Breaker
+}
+
+// NewResilient wraps s with the given retry policy and breaker
+func NewResilient(s Summarizer, policy RetryPolicy, breaker *Breaker) *Resilient {
+	return &Resilient{
+		inner:   s,
+		policy:  policy,
+		breaker: breaker,
+	}
+}
+
+func (r *Resilient) Name() string {
+	return r.inner.Name()
+}
+
+// Breaker exposes the provider's circuit breaker
+func (r *Resilient) Breaker() *Breaker {
+	return r.breaker
+}
+
+func (r *Resilient) Summarize(ctx context.Context, doc Document, opts Options) (*Result, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= r.policy.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, r.backoff(attempt, lastErr)); err != nil {
+				return nil, lastErr
+			}
+		}
+
+		if wait, ok := r.breaker.Allow(); !ok {
+			metrics.SummarizerRequests.WithLabelValues(r.Name(), opts.Style, opts.Language, metrics.OutcomeCircuitOpen).Inc()
+			return nil, &CircuitOpenError{Provider: r.Name(), RetryAfter: wait}
+		}
+
+		result, err := r.attempt(ctx, doc, opts)
+		if err == nil {
+			r.breaker.Success()
+			return result, nil
+		}
+
+		// Cancelled by the caller (e.g. shutdown) says nothing about provider health
+		if ctx.Err() != nil {
+			r.breaker.Release()
+			return nil, err
+		}
+
+		if !isRetryable(err) {
+			// The provider answered, so it is healthy even if the request was bad
+			r.breaker.Success()
+			return nil, err
+		}
+
+		r.breaker.Failure()
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+func (r *Resilient) attempt(ctx context.Context, doc Document, opts Options) (*Result, error) {
+	parent := ctx
+	if r.policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.policy.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result, err := r.inner.Summarize(ctx, doc, opts)
+
+	outcome := metrics.OutcomeSuccess
+	switch {
+	case err == nil:
+	case parent.Err() != nil:
+		outcome = metrics.OutcomeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		outcome = metrics.OutcomeTimeout
+	default:
+		outcome = metrics.OutcomeError
+	}
+	labels := []string{r.Name(), opts.Style, opts.Language, outcome}
+	metrics.SummarizerRequests.WithLabelValues(labels...).Inc()
+	metrics.SummarizerDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+	return result, err
+}
+
+// backoff doubles the delay per attempt up to MaxDelay and adds jitter,
+// honouring a longer Retry-After from the provider
+func (r *Resilient) backoff(attempt int, lastErr error) time.Duration {
+	delay := r.policy.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > r.policy.MaxDelay {
+		delay = r.policy.MaxDelay
+	}
+
+	// Equal jitter: half fixed, half random
+	half := delay / 2
+	if half > 0 {
+		delay = half + time.Duration(rand.Int63n(int64(half)))
+	}
+
+	var statusErr *StatusError
+	if errors.As(lastErr, &statusErr) && statusErr.RetryAfter > delay {
+		delay = statusErr.RetryAfter
+	}
+
+	return delay
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
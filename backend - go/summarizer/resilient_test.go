@@ -0,0 +1,186 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedSummarizer fails with errs in turn, then succeeds
+type scriptedSummarizer struct {
+	mu    sync.Mutex
+	errs  []error
+	calls int
+	// before runs at the start of every call, e.g. to cancel the caller
+	before func()
+}
+
+func (s *scriptedSummarizer) Name() string { return "scripted" }
+
+func (s *scriptedSummarizer) Summarize(ctx context.Context, doc Document, opts Options) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.before != nil {
+		s.before()
+	}
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return nil, s.errs[s.calls-1]
+	}
+	return &Result{Provider: "scripted"}, nil
+}
+
+var testPolicy = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond}
+
+func TestResilientRetriesTransientErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{"429", &StatusError{StatusCode: 429}, 2},
+		{"500", &StatusError{StatusCode: 500}, 2},
+		{"503", &StatusError{StatusCode: 503}, 2},
+		{"network error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, 2},
+		{"attempt timeout", context.DeadlineExceeded, 2},
+		{"400", &StatusError{StatusCode: 400}, 1},
+		{"401", &StatusError{StatusCode: 401}, 1},
+		{"404", &StatusError{StatusCode: 404}, 1},
+		{"other error", errors.New("malformed response"), 1},
+	}
+
+	for _, tt := range tests {
+		inner := &scriptedSummarizer{errs: []error{tt.err}}
+		breaker := NewBreaker(5, time.Minute)
+		r := NewResilient(inner, testPolicy, breaker)
+
+		_, err := r.Summarize(context.Background(), Document{}, Options{})
+		if inner.calls != tt.wantCalls {
+			t.Errorf("%s: %d calls, want %d", tt.name, inner.calls, tt.wantCalls)
+		}
+		if tt.wantCalls == 1 && err != tt.err {
+			t.Errorf("%s: error = %v, want it returned without retrying", tt.name, err)
+		}
+		if tt.wantCalls > 1 && err != nil {
+			t.Errorf("%s: error after a successful retry = %v", tt.name, err)
+		}
+		// Answers, even bad ones, show the provider is up
+		if status := breaker.Status(); status.State != BreakerClosed || status.ConsecutiveFailures != 0 {
+			t.Errorf("%s: breaker = %+v after the call settled", tt.name, status)
+		}
+	}
+}
+
+func TestResilientGivesUpAfterMaxRetries(t *testing.T) {
+	unavailable := &StatusError{StatusCode: 503}
+	inner := &scriptedSummarizer{errs: []error{unavailable, unavailable, unavailable, unavailable, unavailable}}
+	breaker := NewBreaker(10, time.Minute)
+	r := NewResilient(inner, testPolicy, breaker)
+
+	if _, err := r.Summarize(context.Background(), Document{}, Options{}); err != unavailable {
+		t.Errorf("error = %v, want the last provider error", err)
+	}
+	if inner.calls != testPolicy.MaxRetries+1 {
+		t.Errorf("%d calls, want %d", inner.calls, testPolicy.MaxRetries+1)
+	}
+	if failures := breaker.Status().ConsecutiveFailures; failures != inner.calls {
+		t.Errorf("breaker counted %d failures, want %d", failures, inner.calls)
+	}
+}
+
+func TestResilientStopsWhenBreakerOpens(t *testing.T) {
+	unavailable := &StatusError{StatusCode: 503}
+	inner := &scriptedSummarizer{errs: []error{unavailable, unavailable, unavailable, unavailable}}
+	r := NewResilient(inner, testPolicy, NewBreaker(2, time.Minute))
+
+	_, err := r.Summarize(context.Background(), Document{}, Options{})
+	var open *CircuitOpenError
+	if !errors.As(err, &open) {
+		t.Fatalf("error = %v, want CircuitOpenError", err)
+	}
+	if open.RetryAfter <= 0 || open.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %s", open.RetryAfter)
+	}
+	if inner.calls != 2 {
+		t.Errorf("%d calls, want 2 before the breaker opened", inner.calls)
+	}
+}
+
+func TestResilientReleasesTrialOnCancellation(t *testing.T) {
+	breaker, clock := newTestBreaker(1, time.Minute)
+	breaker.Failure()
+	clock.Advance(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	inner := &scriptedSummarizer{errs: []error{context.Canceled}, before: cancel}
+	r := NewResilient(inner, testPolicy, breaker)
+
+	if _, err := r.Summarize(ctx, Document{}, Options{}); err != context.Canceled {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	if inner.calls != 1 {
+		t.Errorf("%d calls after cancellation, want 1", inner.calls)
+	}
+
+	// The cancelled trial says nothing about the provider, so the next caller gets it
+	if status := breaker.Status(); status.State != BreakerHalfOpen || status.ConsecutiveFailures != 1 {
+		t.Errorf("breaker = %+v, want half open with the failure unchanged", status)
+	}
+	if _, ok := breaker.Allow(); !ok {
+		t.Error("the half-open trial was not released")
+	}
+}
+
+func TestResilientWaitsForRetryAfter(t *testing.T) {
+	inner := &scriptedSummarizer{errs: []error{&StatusError{StatusCode: 429, RetryAfter: time.Hour}}}
+	r := NewResilient(inner, testPolicy, NewBreaker(5, time.Minute))
+
+	// The base delay is a millisecond, so only Retry-After keeps it waiting until the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := r.Summarize(ctx, Document{}, Options{})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != 429 {
+		t.Errorf("error = %v, want the 429", err)
+	}
+	if inner.calls != 1 {
+		t.Errorf("%d calls, want no retry before Retry-After", inner.calls)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("gave up after %s, before the deadline", elapsed)
+	}
+}
+
+func TestResilientBackoff(t *testing.T) {
+	r := NewResilient(nil, RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}, NewBreaker(1, time.Minute))
+
+	tests := []struct {
+		attempt  int
+		err      error
+		min, max time.Duration
+	}{
+		{1, nil, 50 * time.Millisecond, 100 * time.Millisecond},
+		{2, nil, 100 * time.Millisecond, 200 * time.Millisecond},
+		{3, nil, 200 * time.Millisecond, 400 * time.Millisecond},
+		{5, nil, 500 * time.Millisecond, time.Second},
+		{64, nil, 500 * time.Millisecond, time.Second},
+		{1, &StatusError{StatusCode: 429, RetryAfter: 30 * time.Second}, 30 * time.Second, 30 * time.Second},
+		{1, &StatusError{StatusCode: 503, RetryAfter: time.Millisecond}, 50 * time.Millisecond, 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if got := r.backoff(tt.attempt, tt.err); got < tt.min || got > tt.max {
+				t.Errorf("backoff(%d, %v) = %s, want between %s and %s", tt.attempt, tt.err, got, tt.min, tt.max)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package summarizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Router picks a provider per summary style and language.
+// Routes are "style/language" patterns where either side may be "*".
+type Router struct {
+	providers map[string]Summarizer
+	routes    map[string]string
+	fallback  string
+}
+
+// NewRouter creates a router that uses fallback when no route matches
+func NewRouter(fallback Summarizer) *Router {
+	return &Router{
+		providers: map[string]Summarizer{fallback.Name(): fallback},
+		routes:    make(map[string]string),
+		fallback:  fallback.Name(),
+	}
+}
+
+// Register makes a provider available to routes under its Name
+func (r *Router) Register(s Summarizer) {
+	r.providers[s.Name()] = s
+}
+
+// Route sends requests matching pattern (e.g. "detailed/*" or "*/indonesian") to the named provider
+func (r *Router) Route(pattern, provider string) error {
+	style, language, ok := strings.Cut(strings.ToLower(strings.TrimSpace(pattern)), "/")
+	if !ok || style == "" || language == "" {
+		return fmt.Errorf("invalid summarizer route %q, expected style/language", pattern)
+	}
+	if _, exists := r.providers[provider]; !exists {
+		return fmt.Errorf("summarizer route %q uses unknown provider %q", pattern, provider)
+	}
+
+	r.routes[style+"/"+language] = provider
+	return nil
+}
+
+// Select returns the provider for the given options, most specific route
+// first, then a "*/*" route, then the fallback
+func (r *Router) Select(opts Options) Summarizer {
+	style := strings.ToLower(opts.Style)
+	language := strings.ToLower(opts.Language)
+
+	for _, key := range []string{
+		style + "/" + language,
+		style + "/*",
+		"*/" + language,
+		"*/*",
+	} {
+		if provider, ok := r.routes[key]; ok {
+			return r.providers[provider]
+		}
+	}
+
+	return r.providers[r.fallback]
+}
+
+// Providers returns the registered providers keyed by name
+func (r *Router) Providers() map[string]Summarizer {
+	return r.providers
+}
+
+// Check fails fast with a CircuitOpenError when the provider selected for opts is unavailable
+func (r *Router) Check(opts Options) error {
+	provider := r.Select(opts)
+
+	guarded, ok := provider.(interface{ Breaker() *Breaker })
+	if !ok {
+		return nil
+	}
+
+	if wait, ok := guarded.Breaker().Peek(); !ok {
+		return &CircuitOpenError{Provider: provider.Name(), RetryAfter: wait}
+	}
+	return nil
+}
+
+// Status reports the circuit breaker state of every guarded provider
+func (r *Router) Status() map[string]BreakerStatus {
+	status := make(map[string]BreakerStatus)
+	for name, provider := range r.providers {
+		if guarded, ok := provider.(interface{ Breaker() *Breaker }); ok {
+			status[name] = guarded.Breaker().Status()
+		}
+	}
+	return status
+}
+
+func (r *Router) Name() string {
+	return "router"
+}
+
+func (r *Router) Summarize(ctx context.Context, doc Document, opts Options) (*Result, error) {
+	return r.Select(opts).Summarize(ctx, doc, opts)
+}
@@ -0,0 +1,68 @@
+package summarizer
+
+import (
+	"context"
+	"testing"
+)
+
+type namedSummarizer string
+
+func (n namedSummarizer) Name() string { return string(n) }
+
+func (n namedSummarizer) Summarize(ctx context.Context, doc Document, opts Options) (*Result, error) {
+	return &Result{Provider: string(n)}, nil
+}
+
+func TestRouterSelect(t *testing.T) {
+	router := NewRouter(namedSummarizer("python"))
+	for _, name := range []string{"exact", "style", "language", "any"} {
+		router.Register(namedSummarizer(name))
+	}
+	for pattern, provider := range map[string]string{
+		"detailed/indonesian": "exact",
+		"detailed/*":          "style",
+		"*/indonesian":        "language",
+	} {
+		if err := router.Route(pattern, provider); err != nil {
+			t.Fatalf("Route(%q): %v", pattern, err)
+		}
+	}
+
+	tests := []struct {
+		style, language, want string
+	}{
+		{"detailed", "indonesian", "exact"},
+		{"Detailed", "INDONESIAN", "exact"},
+		{"detailed", "english", "style"},
+		{"short", "indonesian", "language"},
+		{"short", "english", "python"},
+	}
+	for _, tt := range tests {
+		if got := router.Select(Options{Style: tt.style, Language: tt.language}).Name(); got != tt.want {
+			t.Errorf("Select(%s/%s) = %s, want %s", tt.style, tt.language, got, tt.want)
+		}
+	}
+
+	// A catch-all route wins over the fallback but not over anything more specific
+	if err := router.Route("*/*", "any"); err != nil {
+		t.Fatalf("Route(*/*): %v", err)
+	}
+	tests[len(tests)-1].want = "any"
+	for _, tt := range tests {
+		if got := router.Select(Options{Style: tt.style, Language: tt.language}).Name(); got != tt.want {
+			t.Errorf("with */*, Select(%s/%s) = %s, want %s", tt.style, tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestRouterRouteRejectsInvalid(t *testing.T) {
+	router := NewRouter(namedSummarizer("python"))
+	for _, pattern := range []string{"detailed", "/english", "detailed/", ""} {
+		if err := router.Route(pattern, "python"); err == nil {
+			t.Errorf("Route(%q) accepted an invalid pattern", pattern)
+		}
+	}
+	if err := router.Route("detailed/*", "missing"); err == nil {
+		t.Error("Route accepted an unknown provider")
+	}
+}
@@ -0,0 +1,45 @@
+package summarizer
+
+import (
+	"context"
+	"io"
+)
+
+// Summarizer turns a PDF document into a summary.
+// Implementations must be safe for concurrent use by the job workers.
+type Summarizer interface {
+	// Name identifies the provider in configuration and logs
+	Name() string
+	Summarize(ctx context.Context, doc Document, opts Options) (*Result, error)
+}
+
+// Document is the PDF to summarize. Open may be called more than once.
+type Document struct {
+	Filename string
+	Size     int64
+	Open     func() (io.ReadCloser, error)
+}
+
+// Options selects the summary style and output language
+type Options struct {
+	Style    string
+	Language string
+}
+
+// Result is the provider-neutral outcome of a summarization
+type Result struct {
+	Title            string
+	Summary          string
+	Style            string
+	Language         string
+	WordCount        int
+	ReadingTime      string
+	TextStats        map[string]interface{}
+	OriginalFilename string
+	FileSize         int64
+	ChunksProcessed  int
+	ChunkingUsed     bool
+	ProcessingTime   float64
+	Provider         string
+	Model            string
+}
@@ -0,0 +1,222 @@
+package summarizer
+
+import (
+	"backend-go/extract"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Chunking mirrors the Python service so every provider sees similarly sized inputs
+const (
+	maxChunkSize = 8000
+	chunkOverlap = 200
+)
+
+var (
+	wordPattern      = regexp.MustCompile(`\w+`)
+	sentencePattern  = regexp.MustCompile(`[.!?]+`)
+	paragraphPattern = regexp.MustCompile(`\n\s*\n`)
+)
+
+// completeFunc sends a single prompt to a text model and returns its reply
+type completeFunc func(ctx context.Context, prompt string) (string, error)
+
+// summarizeText extracts the document text in Go and summarizes it with a
+// text-only model, chunking long documents the same way the Python service does
+func summarizeText(ctx context.Context, doc Document, opts Options, complete completeFunc) (*Result, error) {
+	start := time.Now()
+
+	file, err := doc.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF file: %w", err)
+	}
+	text, err := extract.Text(file)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("no extractable text in PDF")
+	}
+
+	chunks := chunkText(text)
+
+	var summary string
+	if len(chunks) == 1 {
+		summary, err = complete(ctx, summaryPrompt(chunks[0], opts))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		partials := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			partials[i], err = complete(ctx, chunkPrompt(chunk, i+1, len(chunks), opts))
+			if err != nil {
+				return nil, fmt.Errorf("failed to summarize section %d: %w", i+1, err)
+			}
+		}
+
+		summary, err = complete(ctx, combinePrompt(strings.Join(partials, "\n\n"), opts))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	stats := textStatistics(text)
+	wordCount := stats["total_words"].(int)
+
+	return &Result{
+		Title:            strings.TrimSuffix(doc.Filename, ".pdf"),
+		Summary:          strings.TrimSpace(summary),
+		Style:            opts.Style,
+		Language:         opts.Language,
+		WordCount:        wordCount,
+		ReadingTime:      readingTime(wordCount),
+		TextStats:        stats,
+		OriginalFilename: doc.Filename,
+		FileSize:         doc.Size,
+		ChunksProcessed:  len(chunks),
+		ChunkingUsed:     len(chunks) > 1,
+		ProcessingTime:   time.Since(start).Seconds(),
+	}, nil
+}
+
+func summaryPrompt(text string, opts Options) string {
+	return fmt.Sprintf(`You are an AI assistant tasked with summarizing PDF documents.
+
+Instructions:
+- Summarize the content clearly and accurately based ONLY on the provided PDF content.
+- Do NOT add information that is not present in the document.
+
+Summary styles:
+- short: very brief summary of the document.
+- general: moderate-length summary covering main points.
+- detailed: in-depth summary with key explanations and important details.
+
+Languages:
+- indonesian: respond in Bahasa Indonesia.
+- english: respond in English.
+
+Selected options:
+- Summary style: %s
+- Language: %s
+
+PDF content:
+%s`, opts.Style, opts.Language, text)
+}
+
+func chunkPrompt(text string, part, total int, opts Options) string {
+	return fmt.Sprintf(`You are summarizing part %d of %d from a PDF document.
+Summarize this section clearly and accurately based ONLY on the provided content.
+Respond in %s.
+
+Section content:
+%s`, part, total, opts.Language, text)
+}
+
+func combinePrompt(partials string, opts Options) string {
+	return fmt.Sprintf(`Combine the following section summaries of one PDF document into a single coherent summary.
+- Remove repetition between sections
+- Follow the requested style and language
+
+Summary style: %s
+Language: %s
+
+Section summaries:
+%s`, opts.Style, opts.Language, partials)
+}
+
+// chunkText splits text into overlapping chunks, preferring sentence and paragraph boundaries
+func chunkText(text string) []string {
+	runes := []rune(text)
+	if len(runes) <= maxChunkSize {
+		return []string{text}
+	}
+
+	var chunks []string
+	start := 0
+	for start < len(runes) {
+		end := start + maxChunkSize
+		if end >= len(runes) {
+			end = len(runes)
+		} else if cut := lastBoundary(runes[end-chunkOverlap : end]); cut > 0 {
+			end = end - chunkOverlap + cut
+		}
+
+		if chunk := strings.TrimSpace(string(runes[start:end])); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+
+		if end == len(runes) {
+			break
+		}
+		start = end - chunkOverlap
+	}
+
+	return chunks
+}
+
+// lastBoundary returns the position just after the last sentence or line break in window
+func lastBoundary(window []rune) int {
+	for i := len(window) - 1; i >= 0; i-- {
+		if window[i] == '.' || window[i] == '\n' {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// textStatistics matches the text_statistics object returned by the Python service
+func textStatistics(text string) map[string]interface{} {
+	words := wordPattern.FindAllString(strings.ToLower(text), -1)
+	unique := make(map[string]bool, len(words))
+	for _, word := range words {
+		unique[word] = true
+	}
+
+	paragraphs := 0
+	if trimmed := strings.TrimSpace(text); trimmed != "" {
+		paragraphs = len(paragraphPattern.Split(trimmed, -1))
+	}
+
+	return map[string]interface{}{
+		"total_words":          len(words),
+		"unique_words":         len(unique),
+		"characters":           len([]rune(text)),
+		"characters_no_spaces": len([]rune(strings.ReplaceAll(text, " ", ""))),
+		"sentences":            len(sentencePattern.FindAllString(text, -1)),
+		"paragraphs":           paragraphs,
+	}
+}
+
+// readingTime formats an estimate at 200 words per minute like the Python service
+func readingTime(wordCount int) string {
+	if wordCount == 0 {
+		return "0 minutes"
+	}
+
+	minutes := float64(wordCount) / 200
+	if minutes < 1 {
+		return "Less than 1 minute"
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%d %s", int(minutes), plural(int(minutes), "minute"))
+	}
+
+	hours := int(minutes) / 60
+	remaining := int(minutes) % 60
+	if remaining == 0 {
+		return fmt.Sprintf("%d %s", hours, plural(hours, "hour"))
+	}
+	return fmt.Sprintf("%d %s %d %s", hours, plural(hours, "hour"), remaining, plural(remaining, "minute"))
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return unit
+	}
+	return unit + "s"
+}
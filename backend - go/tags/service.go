@@ -0,0 +1,289 @@
+package tags
+
+import (
+	"backend-go/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Filter modes
+const (
+	ModeAll = "all"
+	ModeAny = "any"
+)
+
+var (
+	ErrNotFound    = errors.New("tag not found")
+	ErrExists      = errors.New("a tag with that name already exists")
+	ErrPDFNotFound = errors.New("PDF not found")
+)
+
+// Count is a tag with the number of PDFs carrying it, not counting PDFs in the trash
+type Count struct {
+	ID       uint
+	Name     string
+	PDFCount int64
+}
+
+// pdfTag is a row of the join table between PDFs and tags
+type pdfTag struct {
+	PDFID uint
+	TagID uint
+}
+
+func (pdfTag) TableName() string {
+	return "pdf_tags"
+}
+
+// Service manages a workspace's tags and which PDFs carry them
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a tag service
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// List returns a workspace's tags by name with their PDF counts
+func (s *Service) List(workspaceID uint) ([]Count, error) {
+	counts := []Count{}
+	err := s.db.Table("tags").
+		Select("tags.id, tags.name, COUNT(pdfs.id) AS pdf_count").
+		Joins("LEFT JOIN pdf_tags ON pdf_tags.tag_id = tags.id").
+		Joins("LEFT JOIN pdfs ON pdfs.id = pdf_tags.pdf_id AND pdfs.deleted_at IS NULL").
+		Where("tags.workspace_id = ?", workspaceID).
+		Group("tags.id").
+		Order("lower(tags.name) asc").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	return counts, nil
+}
+
+// Create adds a tag to a workspace
+func (s *Service) Create(workspaceID uint, name string) (*models.Tag, error) {
+	tag := models.Tag{WorkspaceID: workspaceID, Name: strings.TrimSpace(name)}
+
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrExists
+	}
+	return &tag, nil
+}
+
+// Rename changes a tag's name. Changing only the case of the name is allowed.
+func (s *Service) Rename(workspaceID, id uint, name string) (*models.Tag, error) {
+	name = strings.TrimSpace(name)
+
+	var tag models.Tag
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("workspace_id = ?", workspaceID).
+			First(&tag, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		var taken int64
+		if err := tx.Model(&models.Tag{}).
+			Where("workspace_id = ? AND lower(name) = lower(?) AND id <> ?", workspaceID, name, tag.ID).
+			Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrExists
+		}
+
+		tag.Name = name
+		return tx.Model(&tag).Update("name", name).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Delete removes a tag from every PDF and deletes it
+func (s *Service) Delete(workspaceID, id uint) error {
+	result := s.db.Where("workspace_id = ?", workspaceID).Delete(&models.Tag{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete tag: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Attach tags PDFs with names, creating tags that don't exist yet. It returns the attached tags.
+func (s *Service) Attach(workspaceID uint, pdfIDs []uint, names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkPDFs(tx, workspaceID, pdfIDs); err != nil {
+			return err
+		}
+
+		var err error
+		tags, err = attach(tx, workspaceID, pdfIDs, names)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Detach removes the named tags from PDFs. Names without a tag are ignored.
+func (s *Service) Detach(workspaceID uint, pdfIDs []uint, names []string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkPDFs(tx, workspaceID, pdfIDs); err != nil {
+			return err
+		}
+		return detach(tx, workspaceID, pdfIDs, names)
+	})
+}
+
+// Update attaches add and detaches remove on several PDFs at once. Either list may be empty.
+func (s *Service) Update(workspaceID uint, pdfIDs []uint, add, remove []string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkPDFs(tx, workspaceID, pdfIDs); err != nil {
+			return err
+		}
+		if len(add) > 0 {
+			if _, err := attach(tx, workspaceID, pdfIDs, add); err != nil {
+				return err
+			}
+		}
+		if len(remove) > 0 {
+			return detach(tx, workspaceID, pdfIDs, remove)
+		}
+		return nil
+	})
+}
+
+// Filter restricts a query to rows whose PDF, identified by column such as
+// "pdfs.id", carries all or any of the named tags
+func Filter(column string, names []string, mode string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		matching := db.Session(&gorm.Session{NewDB: true}).
+			Table("pdf_tags").
+			Select("pdf_tags.pdf_id").
+			Joins("JOIN tags ON tags.id = pdf_tags.tag_id").
+			Where("lower(tags.name) IN ?", lower(names))
+
+		if mode == ModeAll {
+			matching = matching.Group("pdf_tags.pdf_id").Having("COUNT(DISTINCT pdf_tags.tag_id) = ?", len(names))
+		}
+
+		return db.Where(column+" IN (?)", matching)
+	}
+}
+
+// ByName orders preloaded tags alphabetically, e.g. Preload("Tags", tags.ByName)
+func ByName(db *gorm.DB) *gorm.DB {
+	return db.Order("lower(tags.name) asc")
+}
+
+// ParseNames splits a comma separated list of tag names, dropping blanks and case-insensitive duplicates
+func ParseNames(s string) []string {
+	return UniqueNames(strings.Split(s, ","))
+}
+
+// UniqueNames trims names and drops blanks and case-insensitive duplicates, keeping the first spelling
+func UniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, name)
+	}
+	return unique
+}
+
+// checkPDFs makes sure every PDF is live and in the workspace
+func checkPDFs(tx *gorm.DB, workspaceID uint, pdfIDs []uint) error {
+	unique := make(map[uint]bool, len(pdfIDs))
+	for _, id := range pdfIDs {
+		unique[id] = true
+	}
+
+	var count int64
+	if err := tx.Model(&models.PDF{}).Where("workspace_id = ? AND id IN ?", workspaceID, pdfIDs).Count(&count).Error; err != nil {
+		return err
+	}
+	if count != int64(len(unique)) {
+		return ErrPDFNotFound
+	}
+	return nil
+}
+
+func attach(tx *gorm.DB, workspaceID uint, pdfIDs []uint, names []string) ([]models.Tag, error) {
+	created := make([]models.Tag, len(names))
+	for i, name := range names {
+		created[i] = models.Tag{WorkspaceID: workspaceID, Name: name}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&created).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tags: %w", err)
+	}
+
+	tags, err := find(tx, workspaceID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]pdfTag, 0, len(pdfIDs)*len(tags))
+	for _, pdfID := range pdfIDs {
+		for _, tag := range tags {
+			rows = append(rows, pdfTag{PDFID: pdfID, TagID: tag.ID})
+		}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to attach tags: %w", err)
+	}
+	return tags, nil
+}
+
+func detach(tx *gorm.DB, workspaceID uint, pdfIDs []uint, names []string) error {
+	err := tx.Where("pdf_id IN ? AND tag_id IN (?)", pdfIDs, byName(tx, workspaceID, names).Select("id")).
+		Delete(&pdfTag{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to detach tags: %w", err)
+	}
+	return nil
+}
+
+func find(tx *gorm.DB, workspaceID uint, names []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := byName(tx, workspaceID, names).Order("lower(name) asc").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to find tags: %w", err)
+	}
+	return tags, nil
+}
+
+func byName(tx *gorm.DB, workspaceID uint, names []string) *gorm.DB {
+	return tx.Model(&models.Tag{}).Where("workspace_id = ? AND lower(name) IN ?", workspaceID, lower(names))
+}
+
+func lower(names []string) []string {
+	lowered := make([]string, len(names))
+	for i, name := range names {
+		lowered[i] = strings.ToLower(name)
+	}
+	return lowered
+}
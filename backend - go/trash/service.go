@@ -0,0 +1,328 @@
+package trash
+
+import (
+	"backend-go/models"
+	"backend-go/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Item types
+const (
+	TypePDF     = "pdf"
+	TypeSummary = "summary"
+)
+
+var (
+	ErrNotFound   = errors.New("not found in trash")
+	ErrPDFInTrash = errors.New("the summary's PDF is in the trash, restore the PDF first")
+)
+
+// Item is a trashed PDF or summary
+type Item struct {
+	Type      string
+	ID        uint
+	PDFID     uint
+	Title     string
+	Style     *string
+	Language  *string
+	DeletedAt time.Time
+}
+
+// Service moves PDFs and summaries to the trash, restores them and purges
+// them for good once they have been in the trash longer than the retention period.
+// A PDF's summaries are trashed with the same timestamp so restoring the PDF
+// brings back exactly the summaries that went with it.
+type Service struct {
+	db            *gorm.DB
+	content       *storage.ContentStore
+	retention     time.Duration
+	purgeInterval time.Duration
+	wg            sync.WaitGroup
+}
+
+// NewService creates a trash service. Nothing is purged until Start is called.
+func NewService(db *gorm.DB, content *storage.ContentStore, retention, purgeInterval time.Duration) *Service {
+	if purgeInterval <= 0 {
+		purgeInterval = time.Hour
+	}
+
+	return &Service{
+		db:            db,
+		content:       content,
+		retention:     retention,
+		purgeInterval: purgeInterval,
+	}
+}
+
+// Retention is how long items stay in the trash
+func (s *Service) Retention() time.Duration {
+	return s.retention
+}
+
+// TrashPDF soft deletes a PDF and its summaries. The file is kept until the PDF is purged.
+func (s *Service) TrashPDF(pdf *models.PDF) error {
+	// Postgres keeps microseconds, so compare against the value it will store
+	now := time.Now().Truncate(time.Microsecond)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return trashPDFs(tx, []uint{pdf.ID}, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	pdf.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	return nil
+}
+
+// TrashPDFs soft deletes several PDFs and their summaries as part of tx.
+// PDFs already in the trash are left alone.
+func (s *Service) TrashPDFs(tx *gorm.DB, pdfIDs []uint) error {
+	if len(pdfIDs) == 0 {
+		return nil
+	}
+	return trashPDFs(tx, pdfIDs, time.Now().Truncate(time.Microsecond))
+}
+
+func trashPDFs(tx *gorm.DB, pdfIDs []uint, now time.Time) error {
+	if err := tx.Model(&models.Summaries{}).Where("pdf_id IN ?", pdfIDs).Update("deleted_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.PDF{}).Where("id IN ?", pdfIDs).Update("deleted_at", now).Error
+}
+
+// PurgePDF permanently deletes a PDF, live or trashed, with its summaries and releases its file
+func (s *Service) PurgePDF(ctx context.Context, pdf *models.PDF) error {
+	var released []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if released, err = s.content.ReleasePDF(tx, *pdf); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(pdf).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.content.Remove(ctx, released)
+	return nil
+}
+
+// TrashSummary soft deletes a summary
+func (s *Service) TrashSummary(summary *models.Summaries) error {
+	now := time.Now()
+	if err := s.db.Model(summary).Update("deleted_at", now).Error; err != nil {
+		return err
+	}
+
+	summary.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	return nil
+}
+
+// PurgeSummary permanently deletes a summary, live or trashed
+func (s *Service) PurgeSummary(summary *models.Summaries) error {
+	return s.db.Unscoped().Delete(summary).Error
+}
+
+// RestorePDF takes a PDF and the summaries trashed with it out of the trash
+func (s *Service) RestorePDF(workspaceID, id uint) (*models.PDF, error) {
+	var pdf models.PDF
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("workspace_id = ? AND deleted_at IS NOT NULL", workspaceID).
+			First(&pdf, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.Summaries{}).
+			Where("pdf_id = ? AND deleted_at = ?", pdf.ID, pdf.DeletedAt.Time).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+
+		pdf.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&pdf).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pdf, nil
+}
+
+// RestoreSummary takes a summary out of the trash. Its PDF must not be in the trash.
+func (s *Service) RestoreSummary(workspaceID, id uint) (*models.Summaries, error) {
+	var summary models.Summaries
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("workspace_id = ? AND deleted_at IS NOT NULL", workspaceID).
+			First(&summary, id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var pdf models.PDF
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "SHARE"}).First(&pdf, summary.PDFID).Error; err != nil {
+			return err
+		}
+		if pdf.DeletedAt.Valid {
+			return ErrPDFInTrash
+		}
+
+		summary.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&summary).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// List returns trashed items in a workspace, most recently deleted first.
+// itemType is TypePDF, TypeSummary or empty for both. Summaries trashed with
+// their PDF are left out because they come back when the PDF is restored.
+func (s *Service) List(workspaceID uint, itemType string, limit, offset int) ([]Item, int64, error) {
+	var parts []string
+	if itemType == "" || itemType == TypePDF {
+		parts = append(parts, `
+			SELECT 'pdf' AS type, p.id, p.id AS pdf_id, p.title,
+				NULL::text AS style, NULL::text AS language, p.deleted_at
+			FROM pdfs p
+			WHERE p.workspace_id = @workspace_id AND p.deleted_at IS NOT NULL`)
+	}
+	if itemType == "" || itemType == TypeSummary {
+		parts = append(parts, `
+			SELECT 'summary' AS type, s.id, s.pdf_id, p.title,
+				s.style, s.language, s.deleted_at
+			FROM summaries s
+			JOIN pdfs p ON p.id = s.pdf_id
+			WHERE s.workspace_id = @workspace_id AND s.deleted_at IS NOT NULL
+				AND (p.deleted_at IS NULL OR p.deleted_at <> s.deleted_at)`)
+	}
+
+	union := strings.Join(parts, "\nUNION ALL")
+
+	vars := map[string]interface{}{
+		"workspace_id": workspaceID,
+		"limit":        limit,
+		"offset":       offset,
+	}
+
+	var total int64
+	if err := s.db.Raw("SELECT COUNT(*) FROM ("+union+") AS items", vars).Scan(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count trash: %w", err)
+	}
+
+	items := []Item{}
+	if err := s.db.Raw("SELECT * FROM ("+union+") AS items ORDER BY deleted_at DESC, id DESC LIMIT @limit OFFSET @offset", vars).
+		Scan(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	return items, total, nil
+}
+
+// Start purges expired items now and then every purge interval until ctx is cancelled
+func (s *Service) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.purgeInterval)
+		defer ticker.Stop()
+
+		for {
+			s.Purge(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until the purger has exited after ctx is cancelled
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
+
+// Purge permanently deletes PDFs and summaries trashed longer than the retention period
+func (s *Service) Purge(ctx context.Context) {
+	cutoff := time.Now().Add(-s.retention)
+
+	pdfs := 0
+	for ctx.Err() == nil {
+		var batch []models.PDF
+		if err := s.db.Unscoped().Where("deleted_at < ?", cutoff).Order("id asc").Limit(100).Find(&batch).Error; err != nil {
+			fmt.Printf("Failed to find expired PDFs in trash: %v\n", err)
+			return
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, pdf := range batch {
+			if err := s.purgeExpiredPDF(ctx, pdf, cutoff); err != nil {
+				fmt.Printf("Failed to purge PDF %d: %v\n", pdf.ID, err)
+				return
+			}
+			pdfs++
+		}
+	}
+
+	result := s.db.Unscoped().Where("deleted_at < ?", cutoff).Delete(&models.Summaries{})
+	if result.Error != nil {
+		fmt.Printf("Failed to purge expired summaries: %v\n", result.Error)
+		return
+	}
+
+	if pdfs > 0 || result.RowsAffected > 0 {
+		fmt.Printf("Purged %d PDFs and %d summaries from the trash\n", pdfs, result.RowsAffected)
+	}
+}
+
+// purgeExpiredPDF deletes a PDF unless it was restored since it was found
+func (s *Service) purgeExpiredPDF(ctx context.Context, pdf models.PDF, cutoff time.Time) error {
+	var released []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at < ?", cutoff).
+			First(&pdf, pdf.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if released, err = s.content.ReleasePDF(tx, pdf); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&pdf).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.content.Remove(ctx, released)
+	return nil
+}
@@ -3,20 +3,82 @@ package utils
 import (
 	"backend-go/dto"
 	"backend-go/models"
+	"backend-go/search"
+	"math"
+	"strings"
+	"time"
 )
 
 // ConvertPDFToResponse converts PDF model to PDFResponse DTO
 func ConvertPDFToResponse(pdf models.PDF) dto.PDFResponse {
-	return dto.PDFResponse{
-		ID:        pdf.ID,
-		Filename:  pdf.Filename,
-		FileSize:  pdf.FileSize,
-		Title:     pdf.Title,
-		PageCount: pdf.PageCount,
-		CreatedAt: pdf.CreatedAt,
-		UpdatedAt: pdf.UpdatedAt,
-		Summaries: ConvertSummariesToResponse(pdf.Summaries),
+	response := dto.PDFResponse{
+		ID:           pdf.ID,
+		Filename:     pdf.Filename,
+		FileSize:     pdf.FileSize,
+		Title:        pdf.Title,
+		Description:  pdf.Description,
+		CustomFields: pdf.CustomFields,
+		PageCount:    pdf.PageCount,
+		ContentHash:  pdf.ContentHash,
+		TextStatus:   pdf.TextStatus,
+		Version:      pdf.Version,
+		Tags:         ConvertTagsToResponse(pdf.Tags),
+		CollectionID: pdf.CollectionID,
+		CreatedAt:    pdf.CreatedAt,
+		UpdatedAt:    pdf.UpdatedAt,
+		Summaries:    ConvertSummariesToResponse(pdf.Summaries),
 	}
+
+	for i, summary := range pdf.Summaries {
+		response.Summaries[i].Stale = SummaryStale(summary, pdf)
+	}
+
+	return response
+}
+
+// SummaryStale reports whether a summary was generated from a different file than the PDF's current one
+func SummaryStale(summary models.Summaries, pdf models.PDF) bool {
+	if summary.ContentHash != "" && pdf.ContentHash != "" {
+		return summary.ContentHash != pdf.ContentHash
+	}
+	return summary.PDFVersion != pdf.Version
+}
+
+// ConvertTagsToResponse converts Tag models to TagResponse DTOs
+func ConvertTagsToResponse(tags []models.Tag) []dto.TagResponse {
+	responses := make([]dto.TagResponse, len(tags))
+	for i, tag := range tags {
+		responses[i] = dto.TagResponse{
+			ID:   tag.ID,
+			Name: tag.Name,
+		}
+	}
+	return responses
+}
+
+// ConvertCollectionToResponse converts a Collection model to a CollectionResponse DTO without counts
+func ConvertCollectionToResponse(collection models.Collection) dto.CollectionResponse {
+	return dto.CollectionResponse{
+		ID:        collection.ID,
+		Name:      collection.Name,
+		ParentID:  collection.ParentID,
+		Path:      collection.Path,
+		Depth:     collection.Depth,
+		CreatedAt: collection.CreatedAt,
+		UpdatedAt: collection.UpdatedAt,
+	}
+}
+
+// ConvertBreadcrumbsToResponse converts the collections on a path to BreadcrumbResponse DTOs
+func ConvertBreadcrumbsToResponse(collections []models.Collection) []dto.BreadcrumbResponse {
+	responses := make([]dto.BreadcrumbResponse, len(collections))
+	for i, collection := range collections {
+		responses[i] = dto.BreadcrumbResponse{
+			ID:   collection.ID,
+			Name: collection.Name,
+		}
+	}
+	return responses
 }
 
 // ConvertPDFsToResponse converts slice of PDF models to slice of PDFResponse DTOs
@@ -28,21 +90,53 @@ func ConvertPDFsToResponse(pdfs []models.PDF) []dto.PDFResponse {
 	return responses
 }
 
+// ConvertPDFPagesToResponse converts PDFPage models to PDFPageResponse DTOs, optionally including their text
+func ConvertPDFPagesToResponse(pages []models.PDFPage, includeText bool) []dto.PDFPageResponse {
+	responses := make([]dto.PDFPageResponse, len(pages))
+	for i, page := range pages {
+		responses[i] = dto.PDFPageResponse{
+			PageNumber: page.PageNumber,
+			CharCount:  page.CharCount,
+		}
+		if includeText {
+			responses[i].Text = page.Text
+		}
+	}
+	return responses
+}
+
 // ConvertSummaryToResponse converts Summary model to SummaryResponse DTO
 func ConvertSummaryToResponse(summary models.Summaries) dto.SummaryResponse {
 	response := dto.SummaryResponse{
-		ID:          summary.ID,
-		Style:       summary.Style,
-		Content:     summary.Content,
-		PDFID:       summary.PDFID,
-		Language:    summary.Language,
-		SummaryTime: summary.SummaryTime,
-		CreatedAt:   summary.CreatedAt,
-		UpdatedAt:   summary.UpdatedAt,
+		ID:              summary.ID,
+		Style:           summary.Style,
+		Content:         summary.Content,
+		PDFID:           summary.PDFID,
+		PDFVersion:      summary.PDFVersion,
+		Language:        summary.Language,
+		SummaryTime:     summary.SummaryTime,
+		WordCount:       summary.WordCount,
+		ReadingTime:     summary.ReadingTime,
+		ChunksProcessed: summary.ChunksProcessed,
+		ChunkingUsed:    summary.ChunkingUsed,
+		TextStatistics:  summary.TextStatistics,
+		EditedAt:        summary.EditedAt,
+		CreatedAt:       summary.CreatedAt,
+		UpdatedAt:       summary.UpdatedAt,
+	}
+
+	// Summaries created before file details were recorded have none to show
+	if summary.OriginalFilename != "" || summary.SourceFileSize != 0 {
+		response.FileInfo = &dto.FileInfo{
+			OriginalFilename: summary.OriginalFilename,
+			FileSize:         int(summary.SourceFileSize),
+			FileSizeMB:       math.Round(float64(summary.SourceFileSize)/(1024*1024)*100) / 100,
+		}
 	}
 
 	// Include PDF basic info if available
 	if summary.PDF.ID != 0 {
+		response.Stale = SummaryStale(summary, summary.PDF)
 		response.PDF = &dto.PDFBasicInfo{
 			ID:        summary.PDF.ID,
 			Title:     summary.PDF.Title,
@@ -62,3 +156,198 @@ func ConvertSummariesToResponse(summaries []models.Summaries) []dto.SummaryRespo
 	}
 	return responses
 }
+
+// ConvertSummaryRevisionToResponse converts SummaryRevision model to SummaryRevisionResponse DTO
+func ConvertSummaryRevisionToResponse(revision models.SummaryRevision) dto.SummaryRevisionResponse {
+	response := dto.SummaryRevisionResponse{
+		Revision:  revision.Revision,
+		Content:   revision.Content,
+		WordCount: revision.WordCount,
+		Note:      revision.Note,
+		CreatedAt: revision.CreatedAt,
+	}
+
+	if revision.EditedBy != nil {
+		editedBy := ConvertUserToResponse(*revision.EditedBy)
+		response.EditedBy = &editedBy
+	}
+
+	return response
+}
+
+// ConvertSummaryRevisionsToResponse converts slice of SummaryRevision models to slice of SummaryRevisionResponse DTOs
+func ConvertSummaryRevisionsToResponse(revisions []models.SummaryRevision) []dto.SummaryRevisionResponse {
+	responses := make([]dto.SummaryRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = ConvertSummaryRevisionToResponse(revision)
+	}
+	return responses
+}
+
+// ConvertPDFVersionToResponse converts PDFVersion model to PDFVersionResponse DTO.
+// current is the PDF's latest version number.
+func ConvertPDFVersionToResponse(version models.PDFVersion, current int) dto.PDFVersionResponse {
+	response := dto.PDFVersionResponse{
+		Version:          version.Version,
+		Filename:         version.Filename,
+		OriginalFilename: version.OriginalFilename,
+		FileSize:         version.FileSize,
+		PageCount:        version.PageCount,
+		ContentHash:      version.ContentHash,
+		Current:          version.Version == current,
+		CreatedAt:        version.CreatedAt,
+	}
+
+	if version.UploadedBy != nil {
+		uploadedBy := ConvertUserToResponse(*version.UploadedBy)
+		response.UploadedBy = &uploadedBy
+	}
+
+	return response
+}
+
+// ConvertSummaryJobToResponse converts SummaryJob model to SummaryJobResponse DTO
+func ConvertSummaryJobToResponse(job models.SummaryJob) dto.SummaryJobResponse {
+	response := dto.SummaryJobResponse{
+		ID:          job.ID,
+		PDFID:       job.PDFID,
+		Style:       job.Style,
+		Language:    job.Language,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		Error:       job.Error,
+		Cached:      job.Cached,
+		SummaryID:   job.SummaryID,
+		StartedAt:   job.StartedAt,
+		FinishedAt:  job.FinishedAt,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+
+	// Include the generated summary once the job has produced one
+	if job.Summary != nil && job.Summary.ID != 0 {
+		summary := ConvertSummaryToResponse(*job.Summary)
+		response.Summary = &summary
+	}
+
+	return response
+}
+
+func ConvertSummaryJobsToResponse(jobs []models.SummaryJob) []dto.SummaryJobResponse {
+	responses := make([]dto.SummaryJobResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = ConvertSummaryJobToResponse(job)
+	}
+	return responses
+}
+
+// ConvertSearchHitsToResponse converts search hits to SearchHitResponse DTOs
+func ConvertSearchHitsToResponse(hits []search.Hit) []dto.SearchHitResponse {
+	responses := make([]dto.SearchHitResponse, len(hits))
+	for i, hit := range hits {
+		responses[i] = dto.SearchHitResponse{
+			Type:       hit.Type,
+			PDFID:      hit.PDFID,
+			PDFTitle:   hit.PDFTitle,
+			PageNumber: hit.PageNumber,
+			SummaryID:  hit.SummaryID,
+			Language:   hit.Language,
+			Rank:       hit.Rank,
+			Snippet:    hit.Snippet,
+		}
+	}
+	return responses
+}
+
+// ConvertUserToResponse converts User model to UserResponse DTO
+func ConvertUserToResponse(user models.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:        user.ID,
+		Email:     user.Email,
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+// ConvertAPIKeyToResponse converts APIKey model to APIKeyResponse DTO
+func ConvertAPIKeyToResponse(key models.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     strings.Fields(key.Scopes),
+		RateLimit:  key.RateLimit,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// ConvertShareLinkToResponse converts ShareLink model to ShareLinkResponse DTO with its token and URL
+func ConvertShareLinkToResponse(link models.ShareLink, token, url string) dto.ShareLinkResponse {
+	linkType := "pdf"
+	if link.SummaryID != nil {
+		linkType = "summary"
+	}
+	active := link.RevokedAt == nil && time.Now().Before(link.ExpiresAt) &&
+		(link.MaxDownloads == nil || link.DownloadCount < *link.MaxDownloads)
+
+	return dto.ShareLinkResponse{
+		ID:                link.ID,
+		Type:              linkType,
+		PDFID:             link.PDFID,
+		SummaryID:         link.SummaryID,
+		Token:             token,
+		URL:               url,
+		ExpiresAt:         link.ExpiresAt,
+		PasswordProtected: link.PasswordHash != "",
+		MaxDownloads:      link.MaxDownloads,
+		DownloadCount:     link.DownloadCount,
+		LastDownloadedAt:  link.LastDownloadedAt,
+		RevokedAt:         link.RevokedAt,
+		Active:            active,
+		CreatedByID:       link.CreatedByID,
+		CreatedAt:         link.CreatedAt,
+	}
+}
+
+// ConvertAuditEventToResponse converts AuditEvent model to AuditEventResponse DTO
+func ConvertAuditEventToResponse(event models.AuditEvent) dto.AuditEventResponse {
+	return dto.AuditEventResponse{
+		ID:          event.ID,
+		CreatedAt:   event.CreatedAt,
+		WorkspaceID: event.WorkspaceID,
+		ActorID:     event.ActorID,
+		APIKeyID:    event.APIKeyID,
+		IP:          event.IP,
+		RequestID:   event.RequestID,
+		Action:      event.Action,
+		TargetType:  event.TargetType,
+		TargetID:    event.TargetID,
+		Before:      event.Before,
+		After:       event.After,
+	}
+}
+
+// ConvertMembershipToWorkspaceResponse converts a Membership with its Workspace to WorkspaceResponse DTO
+func ConvertMembershipToWorkspaceResponse(membership models.Membership) dto.WorkspaceResponse {
+	return dto.WorkspaceResponse{
+		ID:        membership.Workspace.ID,
+		Name:      membership.Workspace.Name,
+		Role:      membership.Role,
+		CreatedAt: membership.Workspace.CreatedAt,
+	}
+}
+
+// ConvertMembershipToMemberResponse converts a Membership with its User to MemberResponse DTO
+func ConvertMembershipToMemberResponse(membership models.Membership) dto.MemberResponse {
+	return dto.MemberResponse{
+		UserID:   membership.UserID,
+		Email:    membership.User.Email,
+		Name:     membership.User.Name,
+		Role:     membership.Role,
+		JoinedAt: membership.CreatedAt,
+	}
+}
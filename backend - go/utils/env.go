@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GetEnv returns the environment variable value or the fallback when unset
+func GetEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// GetEnvInt returns the environment variable parsed as an int or the fallback when unset or invalid
+func GetEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetEnvDuration returns the environment variable parsed as a duration (e.g. "30s") or the fallback when unset or invalid
+func GetEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+var (
+	instanceID   string
+	instanceOnce sync.Once
+)
+
+// InstanceID names this server process in leases on shared work, INSTANCE_ID
+// or else the hostname with the process ID and a random suffix
+func InstanceID() string {
+	instanceOnce.Do(func() {
+		instanceID = os.Getenv("INSTANCE_ID")
+		if instanceID != "" {
+			return
+		}
+		host, err := os.Hostname()
+		if err != nil {
+			host = "unknown"
+		}
+		suffix := make([]byte, 4)
+		rand.Read(suffix)
+		instanceID = fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+		if len(instanceID) > 64 {
+			instanceID = instanceID[len(instanceID)-64:]
+		}
+	})
+	return instanceID
+}
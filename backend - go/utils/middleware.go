@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"backend-go/models"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -29,55 +33,297 @@ func LoggingMiddleware() fiber.Handler {
 	}
 }
 
-// ErrorHandler provides consistent error responses
-func ErrorHandler(c *fiber.Ctx, err error) error {
-	code := fiber.StatusInternalServerError
-	message := "Internal Server Error"
+// BodyLimit answers 413 to requests whose body is larger than limit bytes.
+// The app streams request bodies, so this must run before anything reads them:
+// declared lengths are checked up front and chunked bodies are read only up to
+// the limit. Requests for which skip returns true are left to a route-level BodyLimit.
+func BodyLimit(limit int, skip func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if skip != nil && skip(c) {
+			return c.Next()
+		}
 
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
-		message = e.Message
+		tooLarge := func() error {
+			// The rest of the body is never read, so the connection can't be reused
+			c.Context().SetConnectionClose()
+			return c.Status(413).JSON(fiber.Map{
+				"error":   "body_too_large",
+				"message": fmt.Sprintf("Request body cannot exceed %d bytes", limit),
+			})
+		}
+
+		// Negative lengths mean the body is chunked
+		length := c.Request().Header.ContentLength()
+		if length > limit {
+			return tooLarge()
+		}
+		stream := c.Context().RequestBodyStream()
+		if length >= 0 || stream == nil {
+			return c.Next()
+		}
+
+		body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "invalid_request",
+				"message": "Failed to read request body",
+			})
+		}
+		if len(body) > limit {
+			return tooLarge()
+		}
+		c.Request().SetBody(body)
+
+		return c.Next()
 	}
+}
 
-	return c.Status(code).JSON(fiber.Map{
-		"error":     "server_error",
-		"message":   message,
-		"code":      code,
-		"timestamp": time.Now().Unix(),
-	})
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID uint
+	// APIKeyID is set when the request was authenticated with an API key
+	APIKeyID uint
+	// Scopes limits what an API key may do. User sessions are unrestricted.
+	Scopes []string
+	// RateLimit is the API key's requests per minute, 0 for the default
+	RateLimit int
+}
+
+// HasScope reports whether the principal may perform actions requiring scope
+func (p *Principal) HasScope(scope string) bool {
+	if p.APIKeyID == 0 {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope || s == "admin" {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenVerifier checks an access token and returns the user it was issued to
+type TokenVerifier interface {
+	VerifyAccessToken(token string) (uint, error)
+}
+
+// APIKeyVerifier checks an API key and returns who it acts as
+type APIKeyVerifier interface {
+	VerifyAPIKey(key string) (*Principal, error)
+}
+
+// AuthMiddleware rejects requests without a valid "X-API-Key" header or
+// "Authorization: Bearer" access token and stores the caller for CurrentPrincipal
+func AuthMiddleware(tokens TokenVerifier, apiKeys APIKeyVerifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if key := strings.TrimSpace(c.Get("X-API-Key")); key != "" {
+			principal, err := apiKeys.VerifyAPIKey(key)
+			if err != nil {
+				return c.Status(401).JSON(fiber.Map{
+					"error":   "unauthorized",
+					"message": "Invalid, expired or revoked API key",
+				})
+			}
+
+			c.Locals("principal", principal)
+			return c.Next()
+		}
+
+		header := c.Get(fiber.HeaderAuthorization)
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="api"`)
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "unauthorized",
+				"message": "Missing bearer token or API key",
+			})
+		}
+
+		userID, err := tokens.VerifyAccessToken(strings.TrimSpace(token))
+		if err != nil {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="api", error="invalid_token"`)
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "unauthorized",
+				"message": "Invalid or expired token",
+			})
+		}
+
+		c.Locals("principal", &Principal{UserID: userID})
+		return c.Next()
+	}
+}
+
+// RequireScope rejects API keys holding none of the given scopes
+func RequireScope(scopes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := CurrentPrincipal(c)
+		if principal == nil {
+			return c.Status(401).JSON(fiber.Map{
+				"error":   "unauthorized",
+				"message": "Authentication required",
+			})
+		}
+
+		for _, scope := range scopes {
+			if principal.HasScope(scope) {
+				return c.Next()
+			}
+		}
+
+		return c.Status(403).JSON(fiber.Map{
+			"error":   "insufficient_scope",
+			"message": "API key lacks the required scope: " + strings.Join(scopes, " or "),
+		})
+	}
+}
+
+// WorkspaceAccess is the caller's membership in the workspace a request operates on
+type WorkspaceAccess struct {
+	WorkspaceID uint
+	Role        string
 }
 
-// RateLimitMiddleware provides basic rate limiting
-func RateLimitMiddleware() fiber.Handler {
-	// Simple in-memory rate limiter
-	requests := make(map[string][]time.Time)
+// WorkspaceResolver looks up a user's role in a workspace, or in their default
+// workspace when workspaceID is 0. It returns nil when the user isn't a member.
+type WorkspaceResolver interface {
+	ResolveWorkspace(userID, workspaceID uint) (*WorkspaceAccess, error)
+}
 
+// WorkspacePathMiddleware serves "/workspaces/:workspace_id/<prefix>/..." with the
+// routes registered for "/<prefix>/...", remembering the workspace for WorkspaceMiddleware
+func WorkspacePathMiddleware(prefixes ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ip := c.IP()
-		now := time.Now()
-
-		// Clean old requests (older than 1 minute)
-		if times, exists := requests[ip]; exists {
-			var validTimes []time.Time
-			for _, t := range times {
-				if now.Sub(t) < time.Minute {
-					validTimes = append(validTimes, t)
+		rest, ok := strings.CutPrefix(c.Path(), "/workspaces/")
+		if !ok {
+			return c.Next()
+		}
+
+		id, sub, ok := strings.Cut(rest, "/")
+		if !ok {
+			return c.Next()
+		}
+
+		for _, prefix := range prefixes {
+			if sub == prefix || strings.HasPrefix(sub, prefix+"/") {
+				workspaceID, err := strconv.ParseUint(id, 10, 64)
+				if err != nil || workspaceID == 0 {
+					return c.Status(400).JSON(fiber.Map{
+						"error":   "invalid_workspace",
+						"message": "Workspace ID must be a positive number",
+					})
 				}
+
+				c.Locals("workspacePathID", uint(workspaceID))
+				c.Path("/" + sub)
+				break
 			}
-			requests[ip] = validTimes
 		}
 
-		// Check rate limit (100 requests per minute)
-		if len(requests[ip]) >= 100 {
-			return c.Status(429).JSON(fiber.Map{
-				"error":   "rate_limit_exceeded",
-				"message": "Too many requests, please try again later",
+		return c.Next()
+	}
+}
+
+// WorkspacePath returns path under the same "/workspaces/:id" prefix the
+// request used, if any, for Location headers that must stay in the workspace
+func WorkspacePath(c *fiber.Ctx, path string) string {
+	if workspaceID, ok := c.Locals("workspacePathID").(uint); ok {
+		return fmt.Sprintf("/workspaces/%d%s", workspaceID, path)
+	}
+	return path
+}
+
+// WorkspaceMiddleware resolves the workspace a request operates on from the path,
+// the "X-Workspace-ID" header or the caller's default workspace, and checks membership
+func WorkspaceMiddleware(resolver WorkspaceResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		workspaceID, _ := c.Locals("workspacePathID").(uint)
+		if header := strings.TrimSpace(c.Get("X-Workspace-ID")); workspaceID == 0 && header != "" {
+			id, err := strconv.ParseUint(header, 10, 64)
+			if err != nil || id == 0 {
+				return c.Status(400).JSON(fiber.Map{
+					"error":   "invalid_workspace",
+					"message": "X-Workspace-ID must be a positive number",
+				})
+			}
+			workspaceID = uint(id)
+		}
+
+		access, err := resolver.ResolveWorkspace(CurrentUserID(c), workspaceID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "database_error",
+				"message": "Failed to resolve workspace",
+				"details": err.Error(),
+			})
+		}
+		if access == nil {
+			return c.Status(404).JSON(fiber.Map{
+				"error":   "workspace_not_found",
+				"message": "Workspace not found or you are not a member",
 			})
 		}
 
-		// Add current request
-		requests[ip] = append(requests[ip], now)
+		c.Locals("workspace", access)
+		return c.Next()
+	}
+}
 
+// RequireRole rejects callers whose workspace role is below role
+func RequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		access := CurrentWorkspace(c)
+		if access == nil || !models.RoleAllows(access.Role, role) {
+			return c.Status(403).JSON(fiber.Map{
+				"error":   "forbidden",
+				"message": "This action requires the " + role + " role in the workspace",
+			})
+		}
 		return c.Next()
 	}
 }
+
+// CurrentWorkspace returns the workspace set by WorkspaceMiddleware, or nil
+func CurrentWorkspace(c *fiber.Ctx) *WorkspaceAccess {
+	access, _ := c.Locals("workspace").(*WorkspaceAccess)
+	return access
+}
+
+// CurrentWorkspaceID returns the workspace set by WorkspaceMiddleware, or 0
+func CurrentWorkspaceID(c *fiber.Ctx) uint {
+	if access := CurrentWorkspace(c); access != nil {
+		return access.WorkspaceID
+	}
+	return 0
+}
+
+// CurrentPrincipal returns the authenticated caller set by AuthMiddleware, or nil
+func CurrentPrincipal(c *fiber.Ctx) *Principal {
+	principal, _ := c.Locals("principal").(*Principal)
+	return principal
+}
+
+// CurrentUserID returns the authenticated user, or 0
+func CurrentUserID(c *fiber.Ctx) uint {
+	if principal := CurrentPrincipal(c); principal != nil {
+		return principal.UserID
+	}
+	return 0
+}
+
+// ErrorHandler provides consistent error responses
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	code := fiber.StatusInternalServerError
+	message := "Internal Server Error"
+
+	if e, ok := err.(*fiber.Error); ok {
+		code = e.Code
+		message = e.Message
+	}
+
+	return c.Status(code).JSON(fiber.Map{
+		"error":     "server_error",
+		"message":   message,
+		"code":      code,
+		"timestamp": time.Now().Unix(),
+	})
+}
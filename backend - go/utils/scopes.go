@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InWorkspace restricts a query to rows of the statement's table in workspaceID.
+// The column is qualified with the current table so it stays unambiguous in joins.
+func InWorkspace(workspaceID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: "workspace_id"},
+			Value:  workspaceID,
+		})
+	}
+}
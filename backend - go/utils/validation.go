@@ -44,14 +44,16 @@ func SanitizeSearchQuery(query string) string {
 	return query
 }
 
+// MaxFileSize is the largest PDF accepted, in bytes
+const MaxFileSize = 100 * 1024 * 1024 // 100MB
+
 // ValidateFileSize validates file size is within acceptable limits
 func ValidateFileSize(size int64) error {
-	const maxSize = 100 * 1024 * 1024 // 100MB
 	if size <= 0 {
 		return fmt.Errorf("file size must be greater than 0")
 	}
-	if size > maxSize {
-		return fmt.Errorf("file size exceeds maximum limit of %d bytes", maxSize)
+	if size > MaxFileSize {
+		return fmt.Errorf("file size exceeds maximum limit of %d bytes", MaxFileSize)
 	}
 	return nil
 }
@@ -62,7 +64,12 @@ func ValidateFileExtension(filename string) error {
 		".pdf": true,
 	}
 
-	ext := strings.ToLower(filename[strings.LastIndex(filename, "."):])
+	dot := strings.LastIndex(filename, ".")
+	if dot < 0 {
+		return fmt.Errorf("file has no extension, expected .pdf")
+	}
+
+	ext := strings.ToLower(filename[dot:])
 	if !allowedExtensions[ext] {
 		return fmt.Errorf("file extension %s is not allowed", ext)
 	}
@@ -82,6 +89,105 @@ func ValidateTitle(title string) error {
 	return nil
 }
 
+// ValidateDescription validates PDF description
+func ValidateDescription(description string) error {
+	if len(strings.TrimSpace(description)) > 5000 {
+		return fmt.Errorf("description cannot exceed 5000 characters")
+	}
+	return nil
+}
+
+// ValidateCustomFields validates PDF custom fields. Values must be strings, numbers or booleans.
+func ValidateCustomFields(fields map[string]interface{}) error {
+	if len(fields) > 50 {
+		return fmt.Errorf("a PDF cannot have more than 50 custom fields")
+	}
+	for key, value := range fields {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("custom field names cannot be empty")
+		}
+		if len(key) > 64 {
+			return fmt.Errorf("custom field name %q exceeds 64 characters", key)
+		}
+		switch v := value.(type) {
+		case string:
+			if len(v) > 1000 {
+				return fmt.Errorf("custom field %q exceeds 1000 characters", key)
+			}
+		case float64, bool:
+		default:
+			return fmt.Errorf("custom field %q must be a string, number or boolean", key)
+		}
+	}
+	return nil
+}
+
+// ValidateTagName validates a tag name
+func ValidateTagName(name string) error {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return fmt.Errorf("tag name cannot be empty")
+	}
+	if len(name) > 64 {
+		return fmt.Errorf("tag name cannot exceed 64 characters")
+	}
+	if strings.Contains(name, ",") {
+		return fmt.Errorf("tag name cannot contain commas")
+	}
+	return nil
+}
+
+// ValidateCollectionName validates a collection name
+func ValidateCollectionName(name string) error {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return fmt.Errorf("collection name cannot be empty")
+	}
+	if len(name) > 255 {
+		return fmt.Errorf("collection name cannot exceed 255 characters")
+	}
+	return nil
+}
+
+// ValidateSummaryContent validates manually edited summary content
+func ValidateSummaryContent(content string) error {
+	content = strings.TrimSpace(content)
+	if len(content) == 0 {
+		return fmt.Errorf("content cannot be empty")
+	}
+	if len(content) > 100000 {
+		return fmt.Errorf("content cannot exceed 100000 characters")
+	}
+	return nil
+}
+
+// ValidateEmail validates an email address
+func ValidateEmail(email string) error {
+	email = strings.TrimSpace(email)
+	if len(email) == 0 {
+		return fmt.Errorf("email cannot be empty")
+	}
+	if len(email) > 254 {
+		return fmt.Errorf("email cannot exceed 254 characters")
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 1 || at == len(email)-1 || strings.ContainsAny(email, " \t\r\n") {
+		return fmt.Errorf("email is not valid")
+	}
+	return nil
+}
+
+// ValidatePassword validates password length. bcrypt ignores anything past 72 bytes.
+func ValidatePassword(password string) error {
+	if len(password) < 8 {
+		return fmt.Errorf("password must be at least 8 characters")
+	}
+	if len(password) > 72 {
+		return fmt.Errorf("password cannot exceed 72 bytes")
+	}
+	return nil
+}
+
 // ValidateSummaryStyle validates summary style
 func ValidateSummaryStyle(style string) error {
 	validStyles := map[string]bool{
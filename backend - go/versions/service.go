@@ -0,0 +1,189 @@
+package versions
+
+import (
+	"backend-go/models"
+	"backend-go/storage"
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotFound        = errors.New("PDF not found")
+	ErrVersionNotFound = errors.New("version not found")
+	ErrSameFile        = errors.New("the file is identical to the current version")
+)
+
+// Service keeps the file history of PDFs. Every version holds a reference on
+// its file, so replacing a PDF's file never removes what older versions point at.
+type Service struct {
+	db      *gorm.DB
+	content *storage.ContentStore
+}
+
+// NewService creates a version service
+func NewService(db *gorm.DB, content *storage.ContentStore) *Service {
+	return &Service{db: db, content: content}
+}
+
+// Create saves a new PDF with its first version. staged is nil for records
+// created without uploading a file. The caller still owns the staged file.
+func (s *Service) Create(ctx context.Context, pdf *models.PDF, staged *storage.Staged, originalFilename string) error {
+	if staged != nil {
+		if err := s.content.Upload(ctx, staged); err != nil {
+			return err
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.CreateIn(tx, pdf, staged, originalFilename)
+	})
+	if err != nil && staged != nil {
+		s.content.Abandon(ctx, staged)
+	}
+	return err
+}
+
+// CreateIn saves a new PDF with its first version inside tx. staged must
+// already be uploaded to the content store, and abandoned if tx rolls back.
+func (s *Service) CreateIn(tx *gorm.DB, pdf *models.PDF, staged *storage.Staged, originalFilename string) error {
+	pdf.Version = 1
+
+	if staged != nil {
+		if err := s.content.Acquire(tx, staged); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Create(pdf).Error; err != nil {
+		return err
+	}
+
+	version := versionOf(*pdf, originalFilename, pdf.OwnerID)
+	return tx.Create(&version).Error
+}
+
+// Replace makes staged the PDF's next version and points the PDF at it. The
+// PDF's extracted pages are dropped since they belong to the previous file.
+func (s *Service) Replace(ctx context.Context, workspaceID, pdfID uint, staged *storage.Staged, originalFilename string, pageCount int, userID uint) (*models.PDF, *models.PDFVersion, error) {
+	var pdf models.PDF
+	var version models.PDFVersion
+
+	if err := s.content.Upload(ctx, staged); err != nil {
+		return nil, nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("workspace_id = ?", workspaceID).
+			First(&pdf, pdfID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if pdf.ContentHash == staged.Hash {
+			return ErrSameFile
+		}
+
+		if err := s.content.Acquire(tx, staged); err != nil {
+			return err
+		}
+
+		pdf.Filename = storage.Filename(staged.Hash)
+		pdf.FileSize = staged.Size
+		pdf.PageCount = pageCount
+		pdf.ContentHash = staged.Hash
+		pdf.TextStatus = models.TextStatusPending
+		pdf.Version++
+
+		version = versionOf(pdf, originalFilename, &userID)
+		if err := tx.Create(&version).Error; err != nil {
+			return fmt.Errorf("failed to save version: %w", err)
+		}
+
+		if err := tx.Where("pdf_id = ?", pdf.ID).Delete(&models.PDFPage{}).Error; err != nil {
+			return fmt.Errorf("failed to clear extracted pages: %w", err)
+		}
+
+		return tx.Model(&pdf).Updates(map[string]interface{}{
+			"filename":     pdf.Filename,
+			"file_size":    pdf.FileSize,
+			"page_count":   pdf.PageCount,
+			"content_hash": pdf.ContentHash,
+			"text_status":  pdf.TextStatus,
+			"version":      pdf.Version,
+		}).Error
+	})
+	if err != nil {
+		s.content.Abandon(ctx, staged)
+		return nil, nil, err
+	}
+	return &pdf, &version, nil
+}
+
+// List returns a PDF's versions, newest first
+func (s *Service) List(pdfID uint, limit, offset int) ([]models.PDFVersion, int64, error) {
+	var total int64
+	if err := s.db.Model(&models.PDFVersion{}).Where("pdf_id = ?", pdfID).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count versions: %w", err)
+	}
+
+	versions := []models.PDFVersion{}
+	if err := s.db.Preload("UploadedBy").
+		Where("pdf_id = ?", pdfID).
+		Order("version desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&versions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	return versions, total, nil
+}
+
+// Get returns one version of a PDF along with the PDF
+func (s *Service) Get(workspaceID, pdfID uint, number int) (*models.PDF, *models.PDFVersion, error) {
+	var pdf models.PDF
+	if err := s.findPDF(workspaceID, pdfID, &pdf); err != nil {
+		return nil, nil, err
+	}
+
+	var version models.PDFVersion
+	err := s.db.Where("pdf_id = ? AND version = ?", pdf.ID, number).First(&version).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrVersionNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &pdf, &version, nil
+}
+
+func (s *Service) findPDF(workspaceID, pdfID uint, pdf *models.PDF) error {
+	err := s.db.Where("workspace_id = ?", workspaceID).First(pdf, pdfID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// versionOf records the PDF's current file as its current version
+func versionOf(pdf models.PDF, originalFilename string, uploadedByID *uint) models.PDFVersion {
+	return models.PDFVersion{
+		PDFID:            pdf.ID,
+		Version:          pdf.Version,
+		Filename:         pdf.Filename,
+		OriginalFilename: originalFilename,
+		FileSize:         pdf.FileSize,
+		PageCount:        pdf.PageCount,
+		ContentHash:      pdf.ContentHash,
+		UploadedByID:     uploadedByID,
+	}
+}
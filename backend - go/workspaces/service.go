@@ -0,0 +1,235 @@
+package workspaces
+
+import (
+	"backend-go/models"
+	"backend-go/utils"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrNotFound       = errors.New("workspace not found")
+	ErrUserNotFound   = errors.New("no user is registered with that email")
+	ErrAlreadyMember  = errors.New("user is already a member of this workspace")
+	ErrMemberNotFound = errors.New("user is not a member of this workspace")
+	ErrLastOwner      = errors.New("a workspace must keep at least one owner")
+)
+
+// Service manages workspaces and who belongs to them
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService creates a workspace service
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// CreateWithOwner creates a workspace owned by userID using tx, so callers
+// can create one as part of a larger transaction
+func CreateWithOwner(tx *gorm.DB, userID uint, name string) (*models.Workspace, error) {
+	workspace := models.Workspace{
+		Name:        strings.TrimSpace(name),
+		CreatedByID: &userID,
+	}
+	if err := tx.Create(&workspace).Error; err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	membership := models.Membership{
+		WorkspaceID: workspace.ID,
+		UserID:      userID,
+		Role:        models.RoleOwner,
+	}
+	if err := tx.Create(&membership).Error; err != nil {
+		return nil, fmt.Errorf("failed to add workspace owner: %w", err)
+	}
+
+	return &workspace, nil
+}
+
+// Create creates a workspace owned by userID
+func (s *Service) Create(userID uint, name string) (*models.Workspace, error) {
+	var workspace *models.Workspace
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		workspace, err = CreateWithOwner(tx, userID, name)
+		return err
+	})
+	return workspace, err
+}
+
+// List returns the user's memberships with their workspaces, oldest first
+func (s *Service) List(userID uint) ([]models.Membership, error) {
+	var memberships []models.Membership
+	err := s.members().
+		Preload("Workspace").
+		Where("memberships.user_id = ?", userID).
+		Order("memberships.id asc").
+		Find(&memberships).Error
+	return memberships, err
+}
+
+// Membership returns the user's membership in a workspace with the workspace loaded
+func (s *Service) Membership(workspaceID, userID uint) (*models.Membership, error) {
+	var membership models.Membership
+	err := s.members().
+		Preload("Workspace").
+		Where("memberships.workspace_id = ? AND memberships.user_id = ?", workspaceID, userID).
+		First(&membership).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+// ResolveWorkspace returns the user's access to a workspace, or to the oldest
+// workspace they belong to when workspaceID is 0. It returns nil when the user
+// isn't a member.
+func (s *Service) ResolveWorkspace(userID, workspaceID uint) (*utils.WorkspaceAccess, error) {
+	query := s.members().Where("memberships.user_id = ?", userID)
+	if workspaceID != 0 {
+		query = query.Where("memberships.workspace_id = ?", workspaceID)
+	}
+
+	var membership models.Membership
+	err := query.Order("memberships.id asc").First(&membership).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &utils.WorkspaceAccess{
+		WorkspaceID: membership.WorkspaceID,
+		Role:        membership.Role,
+	}, nil
+}
+
+// Members lists a workspace's memberships with their users
+func (s *Service) Members(workspaceID uint) ([]models.Membership, error) {
+	var memberships []models.Membership
+	err := s.db.
+		Preload("User").
+		Where("workspace_id = ?", workspaceID).
+		Order("id asc").
+		Find(&memberships).Error
+	return memberships, err
+}
+
+// AddMember gives the user registered with email a role in a workspace
+func (s *Service) AddMember(workspaceID uint, email, role string) (*models.Membership, error) {
+	var user models.User
+	err := s.db.Where("email = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	membership := models.Membership{
+		WorkspaceID: workspaceID,
+		UserID:      user.ID,
+		Role:        role,
+	}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&membership)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to add member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAlreadyMember
+	}
+
+	membership.User = user
+	return &membership, nil
+}
+
+// UpdateRole changes a member's role. The last owner can't be demoted.
+func (s *Service) UpdateRole(workspaceID, userID uint, role string) (*models.Membership, error) {
+	var membership models.Membership
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.lockedMember(tx, workspaceID, userID, &membership); err != nil {
+			return err
+		}
+
+		if membership.Role == models.RoleOwner && role != models.RoleOwner {
+			if err := s.ensureAnotherOwner(tx, workspaceID, userID); err != nil {
+				return err
+			}
+		}
+
+		membership.Role = role
+		return tx.Model(&membership).Update("role", role).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+// RemoveMember takes a user out of a workspace. The last owner can't be removed.
+func (s *Service) RemoveMember(workspaceID, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var membership models.Membership
+		if err := s.lockedMember(tx, workspaceID, userID, &membership); err != nil {
+			return err
+		}
+
+		if membership.Role == models.RoleOwner {
+			if err := s.ensureAnotherOwner(tx, workspaceID, userID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&membership).Error
+	})
+}
+
+// members queries memberships of workspaces that haven't been deleted
+func (s *Service) members() *gorm.DB {
+	return s.db.Model(&models.Membership{}).
+		Joins("JOIN workspaces ON workspaces.id = memberships.workspace_id AND workspaces.deleted_at IS NULL")
+}
+
+// lockedMember loads a membership after locking the workspace row, so
+// concurrent role changes can't leave a workspace without an owner
+func (s *Service) lockedMember(tx *gorm.DB, workspaceID, userID uint, membership *models.Membership) error {
+	var workspace models.Workspace
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&workspace, workspaceID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = tx.Preload("User").Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(membership).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMemberNotFound
+	}
+	return err
+}
+
+func (s *Service) ensureAnotherOwner(tx *gorm.DB, workspaceID, userID uint) error {
+	var owners int64
+	err := tx.Model(&models.Membership{}).
+		Where("workspace_id = ? AND role = ? AND user_id <> ?", workspaceID, models.RoleOwner, userID).
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastOwner
+	}
+	return nil
+}
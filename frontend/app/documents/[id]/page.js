@@ -92,7 +92,7 @@ export default function DocumentDetailPage() {
   };
 
   const handleDelete = async () => {
-    if (!confirm('Are you sure you want to delete this document? This action cannot be undone.')) return;
+    if (!confirm('Move this document and its summaries to the trash? You can restore it from the trash later.')) return;
 
     try {
       await pdfApi.deletePDF(params.id);
@@ -0,0 +1,99 @@
+'use client';
+
+import { useState } from 'react';
+import { useRouter } from 'next/navigation';
+import { FileText, LogIn, UserPlus } from 'lucide-react';
+import { authApi } from '../../lib/api';
+
+export default function LoginPage() {
+  const router = useRouter();
+  const [mode, setMode] = useState('login');
+  const [email, setEmail] = useState('');
+  const [name, setName] = useState('');
+  const [password, setPassword] = useState('');
+  const [submitting, setSubmitting] = useState(false);
+  const [error, setError] = useState(null);
+
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setSubmitting(true);
+    setError(null);
+
+    try {
+      if (mode === 'register') {
+        await authApi.register(email, password, name);
+      }
+      await authApi.login(email, password);
+      router.push('/');
+    } catch (err) {
+      setError(err.message);
+    } finally {
+      setSubmitting(false);
+    }
+  };
+
+  const inputClassName = "w-full bg-transparent border border-[#1F2937] rounded px-4 py-3 text-white placeholder-[#6B7280] focus:border-[#3B82F6] focus:outline-none focus:shadow-[0_0_0_2px_rgba(59,130,246,0.1),0_0_20px_rgba(59,130,246,0.3)] transition-all duration-200 font-normal";
+
+  return (
+    <div className="min-h-screen bg-[#0A0A0A] flex items-center justify-center px-6">
+      <div className="w-full max-w-md border border-[#1F2937] rounded p-8">
+        <div className="flex items-center gap-3 mb-8">
+          <FileText className="w-6 h-6 text-[#3B82F6] stroke-1.5" />
+          <h1 className="text-2xl font-medium text-white">
+            {mode === 'login' ? 'Sign in' : 'Create account'}
+          </h1>
+        </div>
+
+        <form onSubmit={handleSubmit} className="space-y-4">
+          {mode === 'register' && (
+            <input
+              type="text"
+              placeholder="Name"
+              value={name}
+              onChange={(e) => setName(e.target.value)}
+              className={inputClassName}
+            />
+          )}
+          <input
+            type="email"
+            placeholder="Email"
+            value={email}
+            onChange={(e) => setEmail(e.target.value)}
+            required
+            className={inputClassName}
+          />
+          <input
+            type="password"
+            placeholder="Password"
+            value={password}
+            onChange={(e) => setPassword(e.target.value)}
+            required
+            minLength={8}
+            className={inputClassName}
+          />
+
+          {error && <p className="text-sm text-[#EF4444]">{error}</p>}
+
+          <button
+            type="submit"
+            disabled={submitting}
+            className="w-full border border-[#3B82F6] text-[#3B82F6] px-6 py-3 rounded font-normal transition-all duration-200 hover:border-[#2563EB] hover:text-[#2563EB] hover:shadow-[0_0_20px_rgba(59,130,246,0.4)] flex items-center justify-center gap-2 disabled:opacity-50"
+          >
+            {mode === 'login' ? <LogIn className="w-4 h-4 stroke-1.5" /> : <UserPlus className="w-4 h-4 stroke-1.5" />}
+            {submitting ? 'Please wait...' : mode === 'login' ? 'Sign in' : 'Create account'}
+          </button>
+        </form>
+
+        <button
+          onClick={() => {
+            setMode(mode === 'login' ? 'register' : 'login');
+            setError(null);
+          }}
+          className="mt-6 text-sm text-[#9CA3AF] hover:text-white transition-colors"
+        >
+          {mode === 'login' ? "Don't have an account? Create one" : 'Already have an account? Sign in'}
+        </button>
+      </div>
+    </div>
+  );
+}
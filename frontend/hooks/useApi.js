@@ -1,4 +1,5 @@
 import { useState, useEffect, useCallback } from 'react';
+import { getAccessToken, workspaceStorage } from '../lib/api';
 
 // Custom hook for API calls with loading and error states
 export function useApi(apiFunction, dependencies = []) {
@@ -114,6 +115,14 @@ export function useFileUpload() {
 
         const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL_GO || 'http://localhost:8080';
         xhr.open('POST', `${API_BASE_URL}/pdf/upload`);
+        const token = getAccessToken();
+        if (token) {
+          xhr.setRequestHeader('Authorization', `Bearer ${token}`);
+        }
+        const workspaceId = workspaceStorage.get();
+        if (workspaceId) {
+          xhr.setRequestHeader('X-Workspace-ID', workspaceId);
+        }
         xhr.send(formData);
       });
     } catch (err) {
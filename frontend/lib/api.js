@@ -1,6 +1,118 @@
 // API configuration
 const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL_GO || 'http://localhost:8080';
 
+// Tokens issued by /auth/login and /auth/refresh, kept in localStorage
+const TOKEN_STORAGE_KEY = 'auth_tokens';
+
+export const authStorage = {
+  get() {
+    if (typeof window === 'undefined') return null;
+    try {
+      return JSON.parse(window.localStorage.getItem(TOKEN_STORAGE_KEY));
+    } catch {
+      return null;
+    }
+  },
+
+  set(tokens) {
+    window.localStorage.setItem(TOKEN_STORAGE_KEY, JSON.stringify({
+      accessToken: tokens.access_token,
+      refreshToken: tokens.refresh_token,
+      user: tokens.user,
+    }));
+  },
+
+  clear() {
+    window.localStorage.removeItem(TOKEN_STORAGE_KEY);
+  },
+};
+
+// Get the current access token for requests made outside apiFetch
+export function getAccessToken() {
+  return authStorage.get()?.accessToken || null;
+}
+
+// Selected workspace, sent as X-Workspace-ID. The API uses the user's
+// first workspace when none is selected.
+const WORKSPACE_STORAGE_KEY = 'workspace_id';
+
+export const workspaceStorage = {
+  get() {
+    if (typeof window === 'undefined') return null;
+    return window.localStorage.getItem(WORKSPACE_STORAGE_KEY);
+  },
+
+  set(id) {
+    window.localStorage.setItem(WORKSPACE_STORAGE_KEY, String(id));
+  },
+
+  clear() {
+    window.localStorage.removeItem(WORKSPACE_STORAGE_KEY);
+  },
+};
+
+// Shared so concurrent 401s only trigger one refresh
+let refreshPromise = null;
+
+async function refreshSession() {
+  const stored = authStorage.get();
+  if (!stored?.refreshToken) return false;
+
+  if (!refreshPromise) {
+    refreshPromise = fetch(`${API_BASE_URL}/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: stored.refreshToken }),
+    })
+      .then(async (response) => {
+        if (!response.ok) return false;
+        authStorage.set(await response.json());
+        return true;
+      })
+      .catch(() => false)
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+}
+
+// Send to the login page once the session can't be refreshed
+function redirectToLogin() {
+  authStorage.clear();
+  workspaceStorage.clear();
+  if (typeof window !== 'undefined' && window.location.pathname !== '/login') {
+    window.location.href = '/login';
+  }
+}
+
+// fetch with the access token attached, refreshing it once when it has expired
+async function apiFetch(url, options = {}) {
+  const withAuth = () => {
+    const token = getAccessToken();
+    const workspaceId = workspaceStorage.get();
+    return fetch(url, {
+      ...options,
+      headers: {
+        ...options.headers,
+        ...(token && { Authorization: `Bearer ${token}` }),
+        ...(workspaceId && { 'X-Workspace-ID': workspaceId }),
+      },
+    });
+  };
+
+  let response = await withAuth();
+  if (response.status === 401) {
+    if (await refreshSession()) {
+      response = await withAuth();
+    }
+    if (response.status === 401) {
+      redirectToLogin();
+    }
+  }
+  return response;
+}
+
 // Helper function to handle API responses
 async function handleResponse(response) {
   if (!response.ok) {
@@ -19,16 +131,18 @@ export const pdfApi = {
       itemsperpage: params.itemsPerPage || 10,
       sort: params.sortBy || 'created_at',
       order: params.order || 'desc',
-      ...(params.search && { search: params.search })
+      ...(params.search && { search: params.search }),
+      ...(params.tags?.length && { tags: params.tags.join(','), tag_mode: params.tagMode || 'all' }),
+      ...(params.unfiled && { unfiled: 'true' })
     });
 
-    const response = await fetch(`${API_BASE_URL}/pdf?${searchParams}`);
+    const response = await apiFetch(`${API_BASE_URL}/pdf?${searchParams}`);
     return handleResponse(response);
   },
 
   // Get single PDF with summaries
   async getPDF(id) {
-    const response = await fetch(`${API_BASE_URL}/pdf/${id}`);
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}`);
     return handleResponse(response);
   },
 
@@ -40,16 +154,70 @@ export const pdfApi = {
       formData.append('title', title);
     }
 
-    const response = await fetch(`${API_BASE_URL}/pdf/upload`, {
+    const response = await apiFetch(`${API_BASE_URL}/pdf/upload`, {
       method: 'POST',
       body: formData,
     });
     return handleResponse(response);
   },
 
+  // Upload many PDFs and ZIP archives at once; each file gets its own result
+  async uploadPDFBatch(files, { dedupe = false, summarize = false, style, language } = {}) {
+    const formData = new FormData();
+    for (const file of files) {
+      formData.append('files', file);
+    }
+    if (dedupe) formData.append('dedupe', 'true');
+    if (summarize) {
+      formData.append('summarize', 'true');
+      if (style) formData.append('style', style);
+      if (language) formData.append('language', language);
+    }
+
+    const response = await apiFetch(`${API_BASE_URL}/pdf/upload/batch`, {
+      method: 'POST',
+      body: formData,
+    });
+    return handleResponse(response);
+  },
+
+  // Upload a new file for a PDF, kept as its next version
+  async replacePDFFile(id, file) {
+    const formData = new FormData();
+    formData.append('file', file);
+
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}/file`, {
+      method: 'PUT',
+      body: formData,
+    });
+    return handleResponse(response);
+  },
+
+  // Get file versions for PDF, newest first
+  async getVersions(id, params = {}) {
+    const searchParams = new URLSearchParams({
+      page: params.page || 1,
+      itemsperpage: params.itemsPerPage || 10,
+    });
+
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}/versions?${searchParams}`);
+    return handleResponse(response);
+  },
+
+  // Download the file of a specific version, inline for viewing in the browser
+  async downloadPDFVersion(id, version, { inline = false } = {}) {
+    const query = inline ? '?inline=true' : '';
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}/versions/${version}/download${query}`);
+    if (!response.ok) {
+      const error = await response.json().catch(() => ({ message: 'Download failed' }));
+      throw new Error(error.message || `HTTP error! status: ${response.status}`);
+    }
+    return response;
+  },
+
   // Create PDF record manually
   async createPDF(pdfData) {
-    const response = await fetch(`${API_BASE_URL}/pdf`, {
+    const response = await apiFetch(`${API_BASE_URL}/pdf`, {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
@@ -59,35 +227,90 @@ export const pdfApi = {
     return handleResponse(response);
   },
 
+  // Update PDF title, description or custom fields
+  // Custom fields are merged, a null value removes a field
+  async updatePDF(id, changes) {
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}`, {
+      method: 'PATCH',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(changes),
+    });
+    return handleResponse(response);
+  },
+
   // Delete PDF
-  async deletePDF(id) {
-    const response = await fetch(`${API_BASE_URL}/pdf/${id}`, {
+  // Moves the PDF to the trash unless permanent is set
+  async deletePDF(id, permanent = false) {
+    const query = permanent ? '?permanent=true' : '';
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}${query}`, {
       method: 'DELETE',
     });
     return handleResponse(response);
   },
 
-  // Generate summary for PDF
+  // Generate summary for PDF (enqueues a job and waits for it to finish)
   async generateSummary(id, summaryData) {
-    const response = await fetch(`${API_BASE_URL}/pdf/${id}/summarize`, {
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}/summarize`, {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
       },
       body: JSON.stringify(summaryData),
     });
+    let job = await handleResponse(response);
+
+    while (job.status === 'queued' || job.status === 'running') {
+      await new Promise((resolve) => setTimeout(resolve, 2000));
+      job = await jobApi.getJob(job.id);
+    }
+
+    if (job.status === 'failed') {
+      throw new Error(job.error || 'Summary generation failed');
+    }
+    return job.summary;
+  },
+
+  // Get summary jobs for PDF
+  async getJobs(id, params = {}) {
+    const searchParams = new URLSearchParams({
+      page: params.page || 1,
+      itemsperpage: params.itemsPerPage || 10,
+      ...(params.status && { status: params.status })
+    });
+
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}/jobs?${searchParams}`);
     return handleResponse(response);
   },
 
+  // Get extracted pages for PDF
+  async getPages(id, includeText = false) {
+    const query = includeText ? '?include_text=true' : '';
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}/pages${query}`);
+    return handleResponse(response);
+  },
+
+  // Get plain text of a single page
+  async getPageText(id, pageNumber) {
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}/pages/${pageNumber}/text`);
+    if (!response.ok) {
+      const error = await response.json().catch(() => ({ message: 'Failed to fetch page text' }));
+      throw new Error(error.message || `HTTP error! status: ${response.status}`);
+    }
+    return response.text();
+  },
+
   // Get PDF count
   async getPDFCount() {
-    const response = await fetch(`${API_BASE_URL}/pdf/count`);
+    const response = await apiFetch(`${API_BASE_URL}/pdf/count`);
     return handleResponse(response);
   },
 
-  // Download PDF file
-  async downloadPDF(id) {
-    const response = await fetch(`${API_BASE_URL}/pdf/${id}/download`);
+  // Download PDF file, inline for viewing in the browser
+  async downloadPDF(id, { inline = false } = {}) {
+    const query = inline ? '?inline=true' : '';
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}/download${query}`);
     if (!response.ok) {
       const error = await response.json().catch(() => ({ message: 'Download failed' }));
       throw new Error(error.message || `HTTP error! status: ${response.status}`);
@@ -108,22 +331,59 @@ export const summaryApi = {
       ...(params.search && { search: params.search }),
       ...(params.style && { style: params.style }),
       ...(params.language && { language: params.language }),
-      ...(params.pdfId && { pdf: params.pdfId })
+      ...(params.pdfId && { pdf: params.pdfId }),
+      ...(params.minWords && { min_words: params.minWords }),
+      ...(params.maxWords && { max_words: params.maxWords }),
+      ...(params.chunkingUsed !== undefined && { chunking_used: params.chunkingUsed }),
+      ...(params.tags?.length && { tags: params.tags.join(','), tag_mode: params.tagMode || 'all' })
     });
 
-    const response = await fetch(`${API_BASE_URL}/summaries?${searchParams}`);
+    const response = await apiFetch(`${API_BASE_URL}/summaries?${searchParams}`);
     return handleResponse(response);
   },
 
   // Get single summary
   async getSummary(id) {
-    const response = await fetch(`${API_BASE_URL}/summaries/${id}`);
+    const response = await apiFetch(`${API_BASE_URL}/summaries/${id}`);
+    return handleResponse(response);
+  },
+
+  // Edit summary content, saved as a new revision
+  async updateSummary(id, content, note) {
+    const response = await apiFetch(`${API_BASE_URL}/summaries/${id}`, {
+      method: 'PATCH',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ content, ...(note && { note }) }),
+    });
+    return handleResponse(response);
+  },
+
+  // Get summary revisions, newest first
+  async getRevisions(id, params = {}) {
+    const searchParams = new URLSearchParams({
+      page: params.page || 1,
+      itemsperpage: params.itemsPerPage || 10,
+    });
+
+    const response = await apiFetch(`${API_BASE_URL}/summaries/${id}/revisions?${searchParams}`);
+    return handleResponse(response);
+  },
+
+  // Restore an earlier revision's content
+  async revertSummary(id, revision) {
+    const response = await apiFetch(`${API_BASE_URL}/summaries/${id}/revisions/${revision}/revert`, {
+      method: 'POST',
+    });
     return handleResponse(response);
   },
 
   // Delete summary
-  async deleteSummary(id) {
-    const response = await fetch(`${API_BASE_URL}/summaries/${id}`, {
+  // Moves the summary to the trash unless permanent is set
+  async deleteSummary(id, permanent = false) {
+    const query = permanent ? '?permanent=true' : '';
+    const response = await apiFetch(`${API_BASE_URL}/summaries/${id}${query}`, {
       method: 'DELETE',
     });
     return handleResponse(response);
@@ -131,7 +391,7 @@ export const summaryApi = {
 
   // Bulk delete summaries
   async bulkDeleteSummaries(ids) {
-    const response = await fetch(`${API_BASE_URL}/summaries/bulk`, {
+    const response = await apiFetch(`${API_BASE_URL}/summaries/bulk`, {
       method: 'DELETE',
       headers: {
         'Content-Type': 'application/json',
@@ -145,7 +405,489 @@ export const summaryApi = {
 
   // Get summary count
   async getSummaryCount() {
-    const response = await fetch(`${API_BASE_URL}/summaries/count`);
+    const response = await apiFetch(`${API_BASE_URL}/summaries/count`);
+    return handleResponse(response);
+  },
+};
+
+// Authentication API functions
+export const authApi = {
+  async register(email, password, name = '') {
+    const response = await fetch(`${API_BASE_URL}/auth/register`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ email, password, name }),
+    });
+    return handleResponse(response);
+  },
+
+  async login(email, password) {
+    const response = await fetch(`${API_BASE_URL}/auth/login`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ email, password }),
+    });
+    const tokens = await handleResponse(response);
+    authStorage.set(tokens);
+    return tokens.user;
+  },
+
+  async logout() {
+    const stored = authStorage.get();
+    authStorage.clear();
+    workspaceStorage.clear();
+    if (stored?.refreshToken) {
+      await fetch(`${API_BASE_URL}/auth/logout`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: stored.refreshToken }),
+      }).catch(() => {});
+    }
+  },
+
+  async me() {
+    const response = await apiFetch(`${API_BASE_URL}/auth/me`);
+    return handleResponse(response);
+  },
+};
+
+// Trash API functions
+export const tagApi = {
+  // List tags with the number of PDFs carrying each
+  async list() {
+    const response = await apiFetch(`${API_BASE_URL}/tags`);
+    return handleResponse(response);
+  },
+
+  async create(name) {
+    const response = await apiFetch(`${API_BASE_URL}/tags`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ name }),
+    });
+    return handleResponse(response);
+  },
+
+  async rename(id, name) {
+    const response = await apiFetch(`${API_BASE_URL}/tags/${id}`, {
+      method: 'PATCH',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ name }),
+    });
+    return handleResponse(response);
+  },
+
+  async delete(id) {
+    const response = await apiFetch(`${API_BASE_URL}/tags/${id}`, {
+      method: 'DELETE',
+    });
+    return handleResponse(response);
+  },
+
+  // Attach tags to a PDF by name, missing tags are created
+  async tagPDF(pdfId, tags) {
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${pdfId}/tags`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ tags }),
+    });
+    return handleResponse(response);
+  },
+
+  async untagPDF(pdfId, tags) {
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${pdfId}/tags`, {
+      method: 'DELETE',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ tags }),
+    });
+    return handleResponse(response);
+  },
+
+  // Add and remove tags on several PDFs at once
+  async bulkUpdate(pdfIds, { add = [], remove = [] } = {}) {
+    const response = await apiFetch(`${API_BASE_URL}/pdf/tags/bulk`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ pdf_ids: pdfIds, add, remove }),
+    });
+    return handleResponse(response);
+  },
+};
+
+export const collectionApi = {
+  // List top-level collections, or the subcollections of parentId
+  async list(parentId) {
+    const query = parentId ? `?parent_id=${parentId}` : '';
+    const response = await apiFetch(`${API_BASE_URL}/collections${query}`);
+    return handleResponse(response);
+  },
+
+  // Get a collection with its breadcrumbs and subcollections
+  async get(id) {
+    const response = await apiFetch(`${API_BASE_URL}/collections/${id}`);
+    return handleResponse(response);
+  },
+
+  async create(name, parentId = null) {
+    const response = await apiFetch(`${API_BASE_URL}/collections`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ name, parent_id: parentId }),
+    });
+    return handleResponse(response);
+  },
+
+  async rename(id, name) {
+    const response = await apiFetch(`${API_BASE_URL}/collections/${id}`, {
+      method: 'PATCH',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ name }),
+    });
+    return handleResponse(response);
+  },
+
+  // Move a collection under parentId, or to the top level when parentId is null
+  async move(id, parentId = null) {
+    const response = await apiFetch(`${API_BASE_URL}/collections/${id}/move`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ parent_id: parentId }),
+    });
+    return handleResponse(response);
+  },
+
+  // 'reparent' moves the contents up a level, 'recursive' trashes everything inside
+  async delete(id, mode = 'reparent') {
+    const response = await apiFetch(`${API_BASE_URL}/collections/${id}?mode=${mode}`, {
+      method: 'DELETE',
+    });
+    return handleResponse(response);
+  },
+
+  // List a collection's PDFs, taking the same params as getPDFs
+  async getPDFs(id, params = {}) {
+    const searchParams = new URLSearchParams({
+      page: params.page || 1,
+      itemsperpage: params.itemsPerPage || 10,
+      sort: params.sortBy || 'created_at',
+      order: params.order || 'desc',
+      ...(params.search && { search: params.search }),
+      ...(params.tags?.length && { tags: params.tags.join(','), tag_mode: params.tagMode || 'all' }),
+      ...(params.recursive && { recursive: 'true' })
+    });
+
+    const response = await apiFetch(`${API_BASE_URL}/collections/${id}/pdfs?${searchParams}`);
+    return handleResponse(response);
+  },
+
+  // File PDFs in a collection, or take them out of their collection when collectionId is null
+  async movePDFs(pdfIds, collectionId = null) {
+    const response = await apiFetch(`${API_BASE_URL}/pdf/move`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ pdf_ids: pdfIds, collection_id: collectionId }),
+    });
+    return handleResponse(response);
+  },
+};
+
+export const trashApi = {
+  // List trashed PDFs and summaries, optionally only one type ('pdf' or 'summary')
+  async list(params = {}) {
+    const searchParams = new URLSearchParams();
+    if (params.page) searchParams.append('page', params.page);
+    if (params.itemsPerPage) searchParams.append('itemsperpage', params.itemsPerPage);
+    if (params.type) searchParams.append('type', params.type);
+
+    const response = await apiFetch(`${API_BASE_URL}/trash?${searchParams}`);
+    return handleResponse(response);
+  },
+
+  async restorePDF(id) {
+    const response = await apiFetch(`${API_BASE_URL}/pdf/${id}/restore`, {
+      method: 'POST',
+    });
+    return handleResponse(response);
+  },
+
+  async restoreSummary(id) {
+    const response = await apiFetch(`${API_BASE_URL}/summaries/${id}/restore`, {
+      method: 'POST',
+    });
+    return handleResponse(response);
+  },
+};
+
+// Share links, for sending a PDF or summary to someone without an account
+export const shareApi = {
+  // options: { expiresAt, password, maxDownloads }, all optional
+  async sharePDF(id, options = {}) {
+    return this.create(`${API_BASE_URL}/pdf/${id}/share`, options);
+  },
+
+  async shareSummary(id, options = {}) {
+    return this.create(`${API_BASE_URL}/summaries/${id}/share`, options);
+  },
+
+  async create(url, { expiresAt, password, maxDownloads } = {}) {
+    const response = await apiFetch(url, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({
+        ...(expiresAt && { expires_at: expiresAt }),
+        ...(password && { password }),
+        ...(maxDownloads && { max_downloads: maxDownloads }),
+      }),
+    });
+    return handleResponse(response);
+  },
+
+  // List active links, optionally for one PDF or summary; all includes inactive ones
+  async list(params = {}) {
+    const searchParams = new URLSearchParams();
+    if (params.pdfId) searchParams.append('pdf_id', params.pdfId);
+    if (params.summaryId) searchParams.append('summary_id', params.summaryId);
+    if (params.all) searchParams.append('all', 'true');
+
+    const response = await apiFetch(`${API_BASE_URL}/shares?${searchParams}`);
+    return handleResponse(response);
+  },
+
+  async revoke(id) {
+    const response = await apiFetch(`${API_BASE_URL}/shares/${id}`, {
+      method: 'DELETE',
+    });
+    return handleResponse(response);
+  },
+};
+
+// Audit log of the workspace's changes (owners only)
+const auditParams = (params = {}) => {
+  const searchParams = new URLSearchParams();
+  if (params.actorId) searchParams.append('actor_id', params.actorId);
+  if (params.actions?.length) searchParams.append('action', params.actions.join(','));
+  if (params.targetType) searchParams.append('target_type', params.targetType);
+  if (params.targetId) searchParams.append('target_id', params.targetId);
+  if (params.from) searchParams.append('from', new Date(params.from).toISOString());
+  if (params.to) searchParams.append('to', new Date(params.to).toISOString());
+  return searchParams;
+};
+
+export const auditApi = {
+  async list(params = {}) {
+    const searchParams = auditParams(params);
+    searchParams.append('page', params.page || 1);
+    searchParams.append('itemsperpage', params.itemsPerPage || 10);
+
+    const response = await apiFetch(`${API_BASE_URL}/audit?${searchParams}`);
+    return handleResponse(response);
+  },
+
+  // Your own account events, such as API key changes
+  async listAccount(params = {}) {
+    const searchParams = auditParams(params);
+    searchParams.append('page', params.page || 1);
+    searchParams.append('itemsperpage', params.itemsPerPage || 10);
+
+    const response = await apiFetch(`${API_BASE_URL}/auth/me/audit?${searchParams}`);
+    return handleResponse(response);
+  },
+
+  // Resolves to the raw response, a JSON Lines file of every matching event
+  async export(params = {}) {
+    const response = await apiFetch(`${API_BASE_URL}/audit/export?${auditParams(params)}`);
+    if (!response.ok) {
+      const error = await response.json().catch(() => ({ message: 'Export failed' }));
+      throw new Error(error.message || `HTTP error! status: ${response.status}`);
+    }
+    return response;
+  },
+};
+
+// Resumable uploads, for large files over unreliable connections
+const TUS_VERSION = '1.0.0';
+
+// Base64 encode a Upload-Metadata value, keeping non-ASCII filenames intact
+const encodeMetadata = (value) => btoa(String.fromCharCode(...new TextEncoder().encode(value)));
+
+export const uploadApi = {
+  // Start an upload of file, returning its id and the offset to send from
+  async create(file, title) {
+    const metadata = [`filename ${encodeMetadata(file.name)}`];
+    if (title) metadata.push(`title ${encodeMetadata(title)}`);
+
+    const response = await apiFetch(`${API_BASE_URL}/uploads`, {
+      method: 'POST',
+      headers: {
+        'Tus-Resumable': TUS_VERSION,
+        'Upload-Length': String(file.size),
+        'Upload-Metadata': metadata.join(','),
+      },
+    });
+    return handleResponse(response);
+  },
+
+  // Number of bytes the server already has
+  async offset(id) {
+    const response = await apiFetch(`${API_BASE_URL}/uploads/${id}`, {
+      method: 'HEAD',
+      headers: { 'Tus-Resumable': TUS_VERSION },
+    });
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+    return Number(response.headers.get('Upload-Offset'));
+  },
+
+  // Send one chunk at offset, returning the new offset
+  async sendChunk(id, offset, chunk) {
+    const response = await apiFetch(`${API_BASE_URL}/uploads/${id}`, {
+      method: 'PATCH',
+      headers: {
+        'Tus-Resumable': TUS_VERSION,
+        'Upload-Offset': String(offset),
+        'Content-Type': 'application/offset+octet-stream',
+      },
+      body: chunk,
+    });
+    if (!response.ok) {
+      const error = await response.json().catch(() => ({ message: 'Network error' }));
+      throw new Error(error.message || `HTTP error! status: ${response.status}`);
+    }
+    return Number(response.headers.get('Upload-Offset'));
+  },
+
+  // Turn a complete upload into a PDF
+  async finish(id) {
+    const response = await apiFetch(`${API_BASE_URL}/uploads/${id}/finish`, {
+      method: 'POST',
+      headers: { 'Tus-Resumable': TUS_VERSION },
+    });
+    return handleResponse(response);
+  },
+
+  async cancel(id) {
+    const response = await apiFetch(`${API_BASE_URL}/uploads/${id}`, {
+      method: 'DELETE',
+      headers: { 'Tus-Resumable': TUS_VERSION },
+    });
+    if (!response.ok) {
+      throw new Error(`HTTP error! status: ${response.status}`);
+    }
+  },
+
+  // Upload file in chunks and return the created PDF. Pass uploadId from an
+  // earlier attempt to resume it; onProgress gets (sent, total) and the id.
+  async upload(file, { title, uploadId, chunkSize = 8 * 1024 * 1024, onProgress } = {}) {
+    let id = uploadId;
+    let offset = 0;
+    if (id) {
+      offset = await this.offset(id);
+    } else {
+      const created = await this.create(file, title);
+      id = created.id;
+    }
+
+    onProgress?.(offset, file.size, id);
+    while (offset < file.size) {
+      const chunk = file.slice(offset, offset + chunkSize);
+      offset = await this.sendChunk(id, offset, chunk);
+      onProgress?.(offset, file.size, id);
+    }
+
+    return this.finish(id);
+  },
+};
+
+// Workspace API functions
+export const workspaceApi = {
+  async list() {
+    const response = await apiFetch(`${API_BASE_URL}/workspaces`);
+    return handleResponse(response);
+  },
+
+  async create(name) {
+    const response = await apiFetch(`${API_BASE_URL}/workspaces`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ name }),
+    });
+    return handleResponse(response);
+  },
+
+  async getMembers(id) {
+    const response = await apiFetch(`${API_BASE_URL}/workspaces/${id}/members`);
+    return handleResponse(response);
+  },
+
+  async addMember(id, email, role) {
+    const response = await apiFetch(`${API_BASE_URL}/workspaces/${id}/members`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ email, role }),
+    });
+    return handleResponse(response);
+  },
+
+  async updateMember(id, userId, role) {
+    const response = await apiFetch(`${API_BASE_URL}/workspaces/${id}/members/${userId}`, {
+      method: 'PATCH',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ role }),
+    });
+    return handleResponse(response);
+  },
+
+  async removeMember(id, userId) {
+    const response = await apiFetch(`${API_BASE_URL}/workspaces/${id}/members/${userId}`, {
+      method: 'DELETE',
+    });
+    return handleResponse(response);
+  },
+};
+
+// Summary job API functions
+export const jobApi = {
+  // Get summary job status
+  async getJob(id) {
+    const response = await apiFetch(`${API_BASE_URL}/jobs/${id}`);
+    return handleResponse(response);
+  },
+};
+
+// Full-text search API functions
+export const searchApi = {
+  // Search titles, page text and summaries, optionally within one PDF
+  async search(q, params = {}) {
+    const searchParams = new URLSearchParams({
+      q,
+      page: params.page || 1,
+      itemsperpage: params.itemsPerPage || 10,
+      ...(params.type && { type: params.type }),
+      ...(params.pdfId && { pdf_id: params.pdfId })
+    });
+
+    const response = await apiFetch(`${API_BASE_URL}/search?${searchParams}`);
     return handleResponse(response);
   },
 };